@@ -3,40 +3,79 @@
 // @description     Unified lookup and per-provider lookup for threat intelligence, CVE, and URL/domain security.
 // @host            localhost:8080
 // @BasePath        /api/v1
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 API key; "Authorization: Bearer <key>" is accepted as well.
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
 	"net/http"
+	"os"
+	"os/signal"
 	"strconv"
+	"sync"
+	"syscall"
+	"time"
 
 	"hermes/database"
 	_ "hermes/docs"
 	"hermes/internal/config"
 	"hermes/internal/handler"
+	"hermes/internal/logging"
 	"hermes/internal/middleware"
+	"hermes/internal/tracing"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+var logger = logging.For("server")
+
+// shutdownTimeout bounds how long requests in flight may take to finish on shutdown.
+const shutdownTimeout = 30 * time.Second
+
+// fatal logs err and exits; deferred calls do not run.
+func fatal(msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("load config: %v", err)
+		fatal("load config", err)
+	}
+	if err := logging.Setup(cfg); err != nil {
+		fatal("LOG_LEVEL", err)
 	}
 
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		fatal("tracing", err)
+	}
+	defer func() { _ = shutdownTracing(context.Background()) }()
+
 	var db *gorm.DB
 	if cfg.PostgresDSN != "" {
 		if err := database.RunMigrations(cfg.PostgresDSN); err != nil {
-			log.Fatalf("migrate: %v", err)
+			fatal("migrate", err)
 		}
 		db, err = gorm.Open(postgres.Open(cfg.PostgresDSN), &gorm.Config{})
 		if err != nil {
-			log.Fatalf("open db: %v", err)
+			fatal("open db", err)
+		}
+		if err := db.Use(tracing.Plugin{}); err != nil {
+			fatal("db tracing", err)
 		}
 	}
 
@@ -47,24 +86,52 @@ func main() {
 	}
 
 	r := gin.New()
-	r.Use(middleware.Logger())
+	r.Use(middleware.Logger(cfg.LogBodies))
+	r.Use(middleware.Tracing())
 	r.Use(middleware.Recovery())
+	r.Use(middleware.Metrics())
 
-	// Health check
+	// Health check (liveness) and readiness (database reachable and migrated)
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	r.GET("/health/ready", handler.Ready(db))
+
+	// Prometheus metrics
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
 	// Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// API v1 group (lookup and provider routes)
+	// API v1 group (lookup and provider routes) and the TAXII 2.1 server
 	v1 := r.Group("/api/v1")
-	handler.RegisterRoutes(v1, cfg, db)
+	workers := handler.RegisterRoutes(v1, r.Group("/taxii2"), cfg, db)
+
+	// Background workers stop with the server; bulk jobs and provider jobs they leave unfinished
+	// are resumed by the next process.
+	var wg sync.WaitGroup
+	if workers != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			workers.Run(ctx)
+		}()
+	}
+
+	srv := &http.Server{Addr: ":" + strconv.Itoa(cfg.HTTPPort), Handler: r}
+	go func() {
+		logger.Info("listening", "addr", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fatal("run", err)
+		}
+	}()
 
-	addr := ":" + strconv.Itoa(cfg.HTTPPort)
-	log.Printf("listening on %s", addr)
-	if err := r.Run(addr); err != nil {
-		log.Fatalf("run: %v", err)
+	<-ctx.Done()
+	logger.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("shutdown", "error", err)
 	}
+	wg.Wait()
 }
@@ -1,10 +1,14 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"errors"
 	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
 
 	_ "github.com/lib/pq"
 	"github.com/golang-migrate/migrate/v4"
@@ -48,3 +52,40 @@ func RunMigrations(dsn string) error {
 	}
 	return nil
 }
+
+// MigrationStatus is the schema version recorded by migrate next to the newest embedded migration.
+type MigrationStatus struct {
+	Version uint
+	Dirty   bool
+	Latest  uint
+}
+
+// Current reports whether every embedded migration has been applied cleanly.
+func (s MigrationStatus) Current() bool {
+	return !s.Dirty && s.Version == s.Latest
+}
+
+// CheckMigrations reads the applied schema version from db's schema_migrations table.
+func CheckMigrations(ctx context.Context, db *sql.DB) (MigrationStatus, error) {
+	var st MigrationStatus
+	entries, err := fs.ReadDir(migrationsFS, "migrations")
+	if err != nil {
+		return st, err
+	}
+	for _, e := range entries {
+		prefix, _, _ := strings.Cut(e.Name(), "_")
+		if v, err := strconv.ParseUint(prefix, 10, 64); err == nil && uint(v) > st.Latest {
+			st.Latest = uint(v)
+		}
+	}
+	var version int64
+	err = db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &st.Dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return st, nil
+	}
+	if err != nil {
+		return st, fmt.Errorf("read schema version: %w", err)
+	}
+	st.Version = uint(version)
+	return st, nil
+}
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_lookup_results_provider_cached_at;
+DROP INDEX IF EXISTS idx_lookup_requests_indicator_type_hash;
+ALTER TABLE lookup_requests DROP COLUMN IF EXISTS indicator_hash;
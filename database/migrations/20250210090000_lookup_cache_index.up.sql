@@ -0,0 +1,12 @@
+-- indicator_hash: sha256 of the normalized indicator_value; keeps the cache lookup index small
+-- even for 2048-char URLs (a btree on indicator_value itself would exceed the page row limit).
+ALTER TABLE lookup_requests ADD COLUMN IF NOT EXISTS indicator_hash VARCHAR(64);
+
+UPDATE lookup_requests
+SET indicator_hash = encode(sha256(convert_to(indicator_value, 'UTF8')), 'hex')
+WHERE indicator_hash IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_lookup_requests_indicator_type_hash ON lookup_requests(indicator_type, indicator_hash);
+
+-- read-through cache: newest result per provider for a given request set
+CREATE INDEX IF NOT EXISTS idx_lookup_results_provider_cached_at ON lookup_results(provider_code, cached_at);
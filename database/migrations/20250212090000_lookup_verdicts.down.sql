@@ -0,0 +1,9 @@
+ALTER TABLE lookup_requests DROP COLUMN IF EXISTS score;
+ALTER TABLE lookup_requests DROP COLUMN IF EXISTS verdict;
+
+DROP INDEX IF EXISTS idx_lookup_results_verdict_cached_at;
+ALTER TABLE lookup_results DROP COLUMN IF EXISTS last_seen;
+ALTER TABLE lookup_results DROP COLUMN IF EXISTS first_seen;
+ALTER TABLE lookup_results DROP COLUMN IF EXISTS tags;
+ALTER TABLE lookup_results DROP COLUMN IF EXISTS score;
+ALTER TABLE lookup_results DROP COLUMN IF EXISTS verdict;
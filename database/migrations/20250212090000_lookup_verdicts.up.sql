@@ -0,0 +1,12 @@
+-- normalized assessment per provider result (raw_response keeps the vendor payload for drill-down)
+ALTER TABLE lookup_results ADD COLUMN IF NOT EXISTS verdict VARCHAR(16);
+ALTER TABLE lookup_results ADD COLUMN IF NOT EXISTS score SMALLINT;
+ALTER TABLE lookup_results ADD COLUMN IF NOT EXISTS tags JSONB;
+ALTER TABLE lookup_results ADD COLUMN IF NOT EXISTS first_seen TIMESTAMPTZ;
+ALTER TABLE lookup_results ADD COLUMN IF NOT EXISTS last_seen TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_lookup_results_verdict_cached_at ON lookup_results(verdict, cached_at);
+
+-- aggregated verdict across providers for the request
+ALTER TABLE lookup_requests ADD COLUMN IF NOT EXISTS verdict VARCHAR(16);
+ALTER TABLE lookup_requests ADD COLUMN IF NOT EXISTS score SMALLINT;
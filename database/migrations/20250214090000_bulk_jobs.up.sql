@@ -0,0 +1,36 @@
+-- bulk_jobs: one row per bulk lookup; counters track progress over the de-duplicated items
+CREATE TABLE IF NOT EXISTS bulk_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    job_id UUID NOT NULL UNIQUE,
+    status VARCHAR(16) NOT NULL DEFAULT 'queued',
+    providers JSONB,
+    cache_mode VARCHAR(16),
+    total INT NOT NULL DEFAULT 0,
+    completed INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    duplicates INT NOT NULL DEFAULT 0,
+    user_id VARCHAR(128),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_bulk_jobs_status ON bulk_jobs(status);
+
+-- bulk_job_items: one row per unique (type, canonical value) in a job; lookup_request_id links to the lookup
+CREATE TABLE IF NOT EXISTS bulk_job_items (
+    id BIGSERIAL PRIMARY KEY,
+    bulk_job_id BIGINT NOT NULL REFERENCES bulk_jobs(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    indicator_type VARCHAR(32) NOT NULL,
+    indicator_value VARCHAR(2048) NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    lookup_request_id UUID,
+    verdict VARCHAR(16),
+    score SMALLINT,
+    error TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE(bulk_job_id, indicator_type, indicator_value)
+);
+
+CREATE INDEX idx_bulk_job_items_job_position ON bulk_job_items(bulk_job_id, position);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_lookup_requests_verdict_created_at;
+DROP INDEX IF EXISTS idx_lookup_requests_created_at_id;
@@ -0,0 +1,3 @@
+-- history listing: keyset pagination over (created_at, id), optionally filtered by verdict
+CREATE INDEX IF NOT EXISTS idx_lookup_requests_created_at_id ON lookup_requests(created_at, id);
+CREATE INDEX IF NOT EXISTS idx_lookup_requests_verdict_created_at ON lookup_requests(verdict, created_at);
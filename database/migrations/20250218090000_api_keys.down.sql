@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_lookup_requests_user_id_created_at;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS clients;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS client_usage;
+ALTER TABLE clients DROP COLUMN IF EXISTS monthly_quota;
+ALTER TABLE clients DROP COLUMN IF EXISTS daily_quota;
+ALTER TABLE clients DROP COLUMN IF EXISTS rate_limit_per_min;
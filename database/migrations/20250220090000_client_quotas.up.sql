@@ -0,0 +1,14 @@
+-- Per-client limit overrides; NULL uses the configured default, 0 means unlimited
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS rate_limit_per_min INT;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS daily_quota INT;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS monthly_quota INT;
+
+-- client_usage: requests and provider calls per client and UTC day
+CREATE TABLE IF NOT EXISTS client_usage (
+    client_name VARCHAR(128) NOT NULL,
+    day DATE NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    provider_calls BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (client_name, day)
+);
@@ -0,0 +1,23 @@
+-- provider_jobs: asynchronous provider jobs (scans, assessments) started by a lookup; pending rows
+-- are polled by the service's workers until done, failed or expired
+CREATE TABLE IF NOT EXISTS provider_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    lookup_request_id BIGINT NOT NULL REFERENCES lookup_requests(id) ON DELETE CASCADE,
+    request_id UUID NOT NULL,
+    provider_code VARCHAR(64) NOT NULL,
+    external_id VARCHAR(2048) NOT NULL,
+    data JSONB,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    polls INT NOT NULL DEFAULT 0,
+    failures INT NOT NULL DEFAULT 0,
+    next_poll_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ,
+    UNIQUE(lookup_request_id, provider_code)
+);
+
+CREATE INDEX idx_provider_jobs_request_id ON provider_jobs(request_id);
+CREATE INDEX idx_provider_jobs_due ON provider_jobs(next_poll_at) WHERE status = 'pending';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS taxii_collection_lookups;
+DROP TABLE IF EXISTS taxii_collections;
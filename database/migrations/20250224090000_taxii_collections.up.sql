@@ -0,0 +1,20 @@
+-- taxii_collections: curated TAXII collections; taxii_collection_lookups: the lookups whose STIX
+-- objects they serve, with the date the lookup was added (TAXII date_added)
+CREATE TABLE IF NOT EXISTS taxii_collections (
+    id BIGSERIAL PRIMARY KEY,
+    collection_id UUID NOT NULL UNIQUE,
+    alias VARCHAR(64) UNIQUE,
+    title VARCHAR(256) NOT NULL,
+    description TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS taxii_collection_lookups (
+    id BIGSERIAL PRIMARY KEY,
+    collection_id BIGINT NOT NULL REFERENCES taxii_collections(id) ON DELETE CASCADE,
+    lookup_request_id BIGINT NOT NULL REFERENCES lookup_requests(id) ON DELETE CASCADE,
+    date_added TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE(collection_id, lookup_request_id)
+);
+
+CREATE INDEX idx_taxii_collection_lookups_added ON taxii_collection_lookups(collection_id, date_added, id);
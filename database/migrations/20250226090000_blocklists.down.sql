@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS allowlist_entries;
+DROP TABLE IF EXISTS blocklists;
@@ -0,0 +1,27 @@
+-- blocklists: named enforcement lists rendered from stored verdicts. content_hash and modified_at
+-- record when the list's entries last changed (ETag / Last-Modified for polling devices).
+-- allowlist_entries: indicators kept off every blocklist
+CREATE TABLE IF NOT EXISTS blocklists (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(64) NOT NULL UNIQUE,
+    description TEXT,
+    indicator_type VARCHAR(32) NOT NULL,
+    format VARCHAR(16) NOT NULL,
+    verdicts JSONB,
+    min_score SMALLINT NOT NULL DEFAULT 0,
+    max_age_hours INT NOT NULL DEFAULT 0,
+    min_providers INT NOT NULL DEFAULT 0,
+    content_hash VARCHAR(64),
+    modified_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS allowlist_entries (
+    id BIGSERIAL PRIMARY KEY,
+    indicator_type VARCHAR(32) NOT NULL,
+    value VARCHAR(2048) NOT NULL,
+    comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE(indicator_type, value)
+);
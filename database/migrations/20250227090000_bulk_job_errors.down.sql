@@ -0,0 +1 @@
+ALTER TABLE bulk_jobs DROP COLUMN IF EXISTS error;
@@ -0,0 +1,2 @@
+-- bulk_jobs.error: why a job failed before looking up its items (status 'failed')
+ALTER TABLE bulk_jobs ADD COLUMN IF NOT EXISTS error TEXT;
@@ -0,0 +1 @@
+ALTER TABLE lookup_requests DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,8 @@
+-- updated_at: when the aggregate verdict was last stored (a provider job finishing re-aggregates
+-- it); exports use it as the modification time of a lookup.
+ALTER TABLE lookup_requests ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ;
+
+UPDATE lookup_requests SET updated_at = created_at WHERE updated_at IS NULL;
+
+ALTER TABLE lookup_requests ALTER COLUMN updated_at SET DEFAULT now();
+ALTER TABLE lookup_requests ALTER COLUMN updated_at SET NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_lookup_requests_verdict_updated;
+DROP TABLE IF EXISTS lookup_objects;
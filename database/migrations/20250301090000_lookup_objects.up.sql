@@ -0,0 +1,13 @@
+-- lookup_objects: the IDs of the STIX objects each lookup exports, so TAXII match[id] queries
+-- find the lookups serving an object without converting them. Filled as verdicts are stored;
+-- lookups stored earlier are indexed in the background at startup.
+CREATE TABLE IF NOT EXISTS lookup_objects (
+    object_id VARCHAR(128) NOT NULL,
+    lookup_request_id BIGINT NOT NULL REFERENCES lookup_requests(id) ON DELETE CASCADE,
+    PRIMARY KEY (object_id, lookup_request_id)
+);
+
+CREATE INDEX idx_lookup_objects_lookup_request ON lookup_objects(lookup_request_id);
+
+-- The built-in TAXII collections list lookups by verdict, added when the verdict was stored.
+CREATE INDEX idx_lookup_requests_verdict_updated ON lookup_requests(verdict, updated_at, id);
@@ -15,9 +15,52 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/lookup": {
+        "/admin/allowlist": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blocklist"
+                ],
+                "summary": "List allowlist",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hermes_internal_vo.AllowlistEntryVO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
             "post": {
-                "description": "Run lookup across all providers that support the indicator type",
+                "description": "Keeps an indicator off every blocklist. IP entries may be CIDR blocks; domain entries also cover subdomains and URLs on them.",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,26 +68,77 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "lookup"
+                    "blocklist"
                 ],
-                "summary": "Unified lookup",
+                "summary": "Allowlist indicator",
                 "parameters": [
                     {
-                        "description": "Lookup request",
+                        "description": "Entry",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/hermes_internal_dto.LookupRequestDTO"
+                            "$ref": "#/definitions/hermes_internal_dto.AllowlistEntryDTO"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/hermes_internal_vo.LookupResponseVO"
+                            "$ref": "#/definitions/hermes_internal_vo.AllowlistEntryVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body or indicator, or already allowlisted",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
                         }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/allowlist/{id}": {
+            "delete": {
+                "tags": [
+                    "blocklist"
+                ],
+                "summary": "Remove allowlist entry",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
                         "description": "Bad Request",
@@ -52,53 +146,180 @@ const docTemplate = `{
                             "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
             }
         },
-        "/providers/{code}/{type}/{value}": {
+        "/admin/blocklists": {
             "get": {
-                "description": "Lookup using one provider by code (e.g. abuseipdb, virustotal)",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "providers"
+                    "blocklist"
                 ],
-                "summary": "Single-provider lookup",
+                "summary": "List blocklists",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hermes_internal_vo.BlocklistVO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Defines a blocklist served at /blocklists/{name}: indicators of one type whose providers' latest results, at most max_age_hours old, aggregate to one of the verdicts with at least min_score and have min_providers providers agreeing. Lookups limited to some providers or to the cache leave the other results in place.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blocklist"
+                ],
+                "summary": "Create blocklist",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Provider code (e.g. abuseipdb)",
-                        "name": "code",
-                        "in": "path",
-                        "required": true
+                        "description": "Blocklist",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.BlocklistDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.BlocklistVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body, name taken, or a format the indicator type cannot be rendered in",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/blocklists/{name}": {
+            "put": {
+                "description": "Replaces a blocklist's definition; the name in the body is ignored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blocklist"
+                ],
+                "summary": "Replace blocklist",
+                "parameters": [
                     {
                         "type": "string",
-                        "description": "Indicator type (ip, domain, url, hash, email)",
-                        "name": "type",
+                        "description": "Blocklist name",
+                        "name": "name",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Indicator value",
-                        "name": "value",
-                        "in": "path",
-                        "required": true
+                        "description": "Blocklist",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.BlocklistDTO"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/hermes_internal_vo.ProviderLookupResponseVO"
+                            "$ref": "#/definitions/hermes_internal_vo.BlocklistVO"
                         }
                     },
                     "400": {
@@ -107,6 +328,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {
@@ -116,45 +349,2505 @@ const docTemplate = `{
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/hermes_internal_vo.ProviderLookupResponseVO"
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "tags": [
+                    "blocklist"
+                ],
+                "summary": "Delete blocklist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Blocklist name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/clients": {
+            "get": {
+                "description": "Clients with their keys; key secrets are never returned.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API clients",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hermes_internal_vo.ClientVO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
                         }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Create API client",
+                "parameters": [
+                    {
+                        "description": "Client",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.CreateClientDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ClientVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "409": {
+                        "description": "Name taken or reserved",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/clients/{id}/keys": {
+            "post": {
+                "description": "Issue a key for a client. The key is only returned in this response.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Issue API key",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Scopes and optional expiry",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.CreateAPIKeyDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.CreatedAPIKeyVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/clients/{id}/limits": {
+            "put": {
+                "description": "Replace a client's rate limit and provider-call quotas; omitted fields use the configured defaults, 0 means unlimited.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set client limits",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Client ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Limits",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.ClientLimitsDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ClientVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/keys/{key_id}": {
+            "delete": {
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key ID",
+                        "name": "key_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/keys/{key_id}/rotate": {
+            "post": {
+                "description": "Issue a replacement key with the same scopes. The old key keeps working for grace_seconds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Rotate API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Key ID",
+                        "name": "key_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Grace period",
+                        "name": "body",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.RotateAPIKeyDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.CreatedAPIKeyVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/taxii/collections": {
+            "post": {
+                "description": "Creates an empty collection served under /taxii2/api/collections/{id}/; add lookups to fill it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxii"
+                ],
+                "summary": "Create curated TAXII collection",
+                "parameters": [
+                    {
+                        "description": "Collection",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.CreateTAXIICollectionDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.TAXIICollectionVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid body, or the alias is taken or a UUID",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/taxii/collections/{id}": {
+            "delete": {
+                "tags": [
+                    "taxii"
+                ],
+                "summary": "Delete curated TAXII collection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID or alias",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Built-in collection",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/taxii/collections/{id}/lookups": {
+            "post": {
+                "description": "The lookup's STIX objects (see GET /lookups/{request_id}?format=stix) join the collection, dated now. Adding a lookup twice keeps its first date.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "taxii"
+                ],
+                "summary": "Add lookup to curated TAXII collection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID or alias",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Lookup",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.TAXIILookupDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid body or built-in collection",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Collection or lookup not found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/taxii/collections/{id}/lookups/{request_id}": {
+            "delete": {
+                "tags": [
+                    "taxii"
+                ],
+                "summary": "Remove lookup from curated TAXII collection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Collection ID or alias",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Request ID of the lookup",
+                        "name": "request_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Built-in collection",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Collection or lookup not found, or the lookup is not in the collection",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/blocklists/{name}": {
+            "get": {
+                "description": "Renders a blocklist from the latest stored result of each provider for each indicator, minus allowlisted ones, in the list's format: plain (one entry per line, IPs collapsed into CIDR blocks), rpz (a DNS Response Policy Zone answering NXDOMAIN for each domain and its subdomains, passing allowlisted subdomains through), squid (a dstdomain or url_regex ACL file; it cannot exempt allowlisted subdomains of listed domains and names them in a comment) or suricata (drop rules, with pass rules for allowlisted subdomains of listed domains; hash lists get one rule per algorithm loading \u003cname\u003e.\u003calgo\u003e, served with hash_type). Lists are refreshed every minute and when their definition or the allowlist changes; new verdicts show after the next refresh. Responses carry ETag and Last-Modified; If-None-Match and If-Modified-Since get 304 while the list is unchanged. Requires scope blocklist:read.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "blocklist"
+                ],
+                "summary": "Get blocklist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Blocklist name",
+                        "name": "name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "md5",
+                            "sha1",
+                            "sha256"
+                        ],
+                        "type": "string",
+                        "description": "Hash lists only: the plain list of hashes of this algorithm",
+                        "name": "hash_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Rendered list",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookup": {
+            "post": {
+                "description": "Run lookup across all providers that support the indicator type",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "Unified lookup",
+                "parameters": [
+                    {
+                        "description": "Lookup request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.LookupRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.LookupResponseVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed body or invalid indicator (code INVALID_INDICATOR with details)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit or quota exceeded (see Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookup/stream": {
+            "post": {
+                "description": "Unified lookup as server-sent events: \"start\" (vo.LookupStartVO) once the request is recorded, one \"result\" (vo.ProviderResultVO) per provider as soon as it answers, then \"complete\" (vo.LookupResponseVO) with the aggregated verdict. Closing the connection cancels the providers still running. Invalid requests get a JSON error instead of a stream.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "Streamed unified lookup",
+                "parameters": [
+                    {
+                        "description": "Lookup request",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_dto.LookupRequestDTO"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.LookupResponseVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Malformed body or invalid indicator (code INVALID_INDICATOR with details)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit or quota exceeded (see Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookups": {
+            "get": {
+                "description": "Filter past lookups; pages are linked by next_cursor. With format=csv or xlsx every matching lookup is downloaded as a spreadsheet instead (cursor and limit are ignored): one row per lookup with the aggregate verdict and, per provider (only the provider filter's, if given), its verdict, score and key fields such as AbuseIPDB confidence, VirusTotal detection ratio or PhishTank verification, plus the provider errors; XLSX files list the errors on a second sheet. Rows are streamed as they are read.",
+                "produces": [
+                    "application/json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "List past lookups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ip, domain, url, hash, email or cve",
+                        "name": "indicator_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Indicator value (canonicalized when indicator_type is given)",
+                        "name": "indicator_value",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only lookups with a result from this provider",
+                        "name": "provider",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Aggregate verdict: malicious, suspicious, clean or unknown",
+                        "name": "verdict",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after (RFC 3339)",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before (RFC 3339)",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "created_at, -created_at (default), score or -score",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor of the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "Page size (1-200)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.LookupHistoryVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit or quota exceeded (see Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookups/bulk": {
+            "post": {
+                "description": "Queue a bulk lookup. The body is a JSON array of indicators (objects with indicator_type/indicator_value, or bare strings whose type is detected) or, with Content-Type text/plain, one indicator per line (\"value\" or \"type value\"). Identical indicators are looked up once.",
+                "consumes": [
+                    "application/json",
+                    "text/plain"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "Submit bulk lookup",
+                "parameters": [
+                    {
+                        "description": "Indicators",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hermes_internal_dto.BulkIndicatorDTO"
+                            }
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma-separated provider codes (default: all enabled)",
+                        "name": "providers",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cache mode: prefer (default), bypass, only",
+                        "name": "cache",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.BulkJobVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit or quota exceeded (see Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookups/bulk/{id}": {
+            "get": {
+                "description": "Progress counters and a page of results (in input order) of a bulk lookup job. With format=stix the lookups of all finished items are returned as one STIX 2.1 bundle (see GET /lookups/{request_id}); page and page_size are ignored. With format=misp they are returned as one MISP event. With format=csv or xlsx all items are downloaded as a spreadsheet, one row per indicator in input order (see GET /lookups); items without a lookup show their status and error.",
+                "produces": [
+                    "application/json",
+                    "application/stix+json",
+                    "text/csv",
+                    "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "Bulk lookup progress",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Page (from 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Items per page (max 1000)",
+                        "name": "page_size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "stix",
+                            "misp",
+                            "csv",
+                            "xlsx"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.BulkJobVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit or quota exceeded (see Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookups/bulk/{id}/misp": {
+            "post": {
+                "description": "Push the lookups of a bulk job's finished items to the configured MISP instance as one event (see GET /lookups/bulk/{id}?format=misp); jobs with more than 10000 lookups are rejected. Pushing again updates the same event. Requires scope misp:push.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "Push bulk lookup to MISP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.MISPPushVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "502": {
+                        "description": "MISP rejected the event or could not be reached",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "503": {
+                        "description": "MISP is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookups/{request_id}": {
+            "get": {
+                "description": "Reconstruct a unified lookup response from storage. Only successful provider results are stored. With format=stix the lookup is returned as a STIX 2.1 bundle: the indicator as an observable and an indicator pattern (a vulnerability for CVEs), an identity per provider, and a note per provider verdict plus a sighting per malicious or suspicious one. Object IDs are deterministic, so re-exports deduplicate. With format=misp it is returned as a MISP event with one attribute tagged with the aggregate and provider verdicts and any galaxy clusters (e.g. Malpedia families) providers name.",
+                "produces": [
+                    "application/json",
+                    "application/stix+json"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "Get past lookup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Request ID returned by /lookup",
+                        "name": "request_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "json",
+                            "stix",
+                            "misp"
+                        ],
+                        "type": "string",
+                        "default": "json",
+                        "description": "Response format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.LookupResponseVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit or quota exceeded (see Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookups/{request_id}/events": {
+            "get": {
+                "description": "Server-sent events for a lookup with pending provider jobs: a \"result\" event (vo.ProviderResultVO) as each job finishes, then one \"complete\" event (vo.LookupResponseVO) once none is pending, after which the stream ends. A lookup without pending jobs gets \"complete\" at once. Streams end after 10 minutes; jobs still pending then get a final \"partial\" event (vo.LookupResponseVO) instead, and the lookup can be followed again.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "Follow a lookup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Request ID returned by /lookup",
+                        "name": "request_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Event stream",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.LookupResponseVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit or quota exceeded (see Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/lookups/{request_id}/misp": {
+            "post": {
+                "description": "Push a past lookup to the configured MISP instance as the event GET /lookups/{request_id}?format=misp returns. The event UUID is derived from the request ID, so pushing again updates the same event. Requires scope misp:push.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lookup"
+                ],
+                "summary": "Push lookup to MISP",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Request ID returned by /lookup",
+                        "name": "request_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.MISPPushVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "502": {
+                        "description": "MISP rejected the event or could not be reached",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "503": {
+                        "description": "MISP is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/providers": {
+            "get": {
+                "description": "Every registered provider with its supported indicator types, whether its API key is configured, enabled state, rate limit, and success rate and latency of its latest calls (at most 100, since the server started).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "List providers",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/hermes_internal_vo.ProviderStatusVO"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/providers/{code}/health": {
+            "get": {
+                "description": "Looks up a harmless canary indicator (e.g. 8.8.8.8, example.com, the EICAR test file hash) with the provider and reports whether it answered. The call counts against the provider's rate limit and the client's quota. Asynchronous providers are never asked to start a scan: they are checked with a search, or reported as skipped. Requires scope provider:\u003ccode\u003e.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "Provider health check",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider code (e.g. abuseipdb)",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Healthy, or skipped",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ProviderHealthVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Client rate limit or quota exceeded (see Retry-After)",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "503": {
+                        "description": "Canary lookup failed",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ProviderHealthVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/providers/{code}/{type}/{value}": {
+            "get": {
+                "description": "Lookup using one provider by code (e.g. abuseipdb, virustotal). Requires scope provider:\u003ccode\u003e.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "providers"
+                ],
+                "summary": "Single-provider lookup",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider code (e.g. abuseipdb)",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Indicator type (ip, domain, url, hash, email, cve)",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Indicator value",
+                        "name": "value",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ProviderLookupResponseVO"
+                        }
+                    },
+                    "202": {
+                        "description": "Provider still working (pending); retry later",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ProviderLookupResponseVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ProviderLookupResponseVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ProviderLookupResponseVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        },
+        "/usage": {
+            "get": {
+                "description": "Requests and provider calls of the calling client today and this month (UTC), with its limits. Quotas count provider calls: one /lookup may call many providers.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "usage"
+                ],
+                "summary": "Current usage",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Client name (admin only; default: the caller)",
+                        "name": "client",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.UsageVO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/hermes_internal_vo.ErrorVO"
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "hermes_internal_dto.AllowlistEntryDTO": {
+            "type": "object",
+            "required": [
+                "indicator_type",
+                "value"
+            ],
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Office egress"
+                },
+                "indicator_type": {
+                    "type": "string",
+                    "enum": [
+                        "ip",
+                        "domain",
+                        "url",
+                        "hash",
+                        "email"
+                    ],
+                    "example": "ip"
+                },
+                "value": {
+                    "description": "Value is canonicalized; IPs may be CIDR blocks and domains cover their subdomains.",
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "192.0.2.0/24"
+                }
+            }
+        },
+        "hermes_internal_dto.BlocklistDTO": {
+            "type": "object",
+            "required": [
+                "format",
+                "indicator_type"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "example": "IPs at least two providers call malicious, last 30 days"
+                },
+                "format": {
+                    "description": "Format: plain (ip, domain, url, hash, email), rpz (domain), squid (domain, url) or\nsuricata (ip, domain, hash).",
+                    "type": "string",
+                    "enum": [
+                        "plain",
+                        "rpz",
+                        "squid",
+                        "suricata"
+                    ],
+                    "example": "plain"
+                },
+                "indicator_type": {
+                    "type": "string",
+                    "enum": [
+                        "ip",
+                        "domain",
+                        "url",
+                        "hash",
+                        "email"
+                    ],
+                    "example": "ip"
+                },
+                "max_age_hours": {
+                    "description": "MaxAgeHours leaves out provider results cached longer ago (0 = any age).",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 720
+                },
+                "min_providers": {
+                    "description": "MinProviders is how many providers must have a verdict among Verdicts.",
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 2
+                },
+                "min_score": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 0,
+                    "example": 70
+                },
+                "name": {
+                    "description": "Name is part of the list's URL: lowercase letters, digits, '-', '_' and '.'. It is taken\nfrom the path when replacing a list.",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "malicious-ips"
+                },
+                "verdicts": {
+                    "description": "Verdicts the aggregate of the providers' latest results must be one of (default\nmalicious).",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "malicious"
+                    ]
+                }
+            }
+        },
+        "hermes_internal_dto.BulkIndicatorDTO": {
+            "type": "object",
+            "properties": {
+                "indicator_type": {
+                    "type": "string",
+                    "example": "ip"
+                },
+                "indicator_value": {
+                    "type": "string",
+                    "example": "8.8.8.8"
+                }
+            }
+        },
+        "hermes_internal_dto.ClientLimitsDTO": {
+            "type": "object",
+            "properties": {
+                "daily_quota": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 20000
+                },
+                "monthly_quota": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 400000
+                },
+                "rate_limit_per_min": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 120
+                }
+            }
+        },
+        "hermes_internal_dto.CreateAPIKeyDTO": {
+            "type": "object",
+            "required": [
+                "scopes"
+            ],
+            "properties": {
+                "expires_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "description": "Scopes: lookup:read, taxii:read, misp:push, blocklist:read, admin, provider:* or provider:\u003ccode\u003e",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "lookup:read",
+                        "provider:abuseipdb"
+                    ]
+                }
+            }
+        },
+        "hermes_internal_dto.CreateClientDTO": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "daily_quota": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 20000
+                },
+                "monthly_quota": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 400000
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "soc-automation"
+                },
+                "rate_limit_per_min": {
+                    "type": "integer",
+                    "minimum": 0,
+                    "example": 120
+                }
+            }
+        },
+        "hermes_internal_dto.CreateTAXIICollectionDTO": {
+            "type": "object",
+            "required": [
+                "title"
+            ],
+            "properties": {
+                "alias": {
+                    "description": "Alias can stand in for the collection ID in TAXII URLs.",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "phishing"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Confirmed phishing domains and URLs"
+                },
+                "title": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "Phishing infrastructure"
+                }
+            }
+        },
+        "hermes_internal_dto.LookupRequestDTO": {
+            "description": "Request body for unified lookup",
+            "type": "object",
+            "required": [
+                "indicator_type",
+                "indicator_value"
+            ],
+            "properties": {
+                "cache": {
+                    "description": "Cache is one of: prefer (default), bypass, only",
+                    "type": "string",
+                    "enum": [
+                        "bypass",
+                        "prefer",
+                        "only"
+                    ],
+                    "example": "prefer"
+                },
+                "indicator_type": {
+                    "description": "IndicatorType is one of: ip, domain, url, hash, email, cve",
+                    "type": "string",
+                    "enum": [
+                        "ip",
+                        "domain",
+                        "url",
+                        "hash",
+                        "email",
+                        "cve"
+                    ],
+                    "example": "ip"
+                },
+                "indicator_value": {
+                    "description": "IndicatorValue is the value to look up (e.g. IP, domain, URL, hash, email, CVE ID)",
+                    "type": "string",
+                    "example": "8.8.8.8"
+                },
+                "providers": {
+                    "description": "Providers optionally limits which providers to query (empty = all enabled)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "hermes_internal_dto.RotateAPIKeyDTO": {
+            "type": "object",
+            "properties": {
+                "grace_seconds": {
+                    "description": "GraceSeconds keeps the old key working this long (default 0, at most 7 days).",
+                    "type": "integer",
+                    "maximum": 604800,
+                    "minimum": 0,
+                    "example": 3600
+                }
+            }
+        },
+        "hermes_internal_dto.TAXIILookupDTO": {
+            "type": "object",
+            "required": [
+                "request_id"
+            ],
+            "properties": {
+                "request_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                }
+            }
+        },
+        "hermes_internal_vo.APIKeyVO": {
+            "description": "API key (without secret)",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "key_id": {
+                    "type": "string",
+                    "example": "3f9a1c2e"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "lookup:read",
+                        "provider:abuseipdb"
+                    ]
+                }
+            }
+        },
+        "hermes_internal_vo.AggregateVerdictVO": {
+            "description": "Aggregated verdict across providers",
+            "type": "object",
+            "properties": {
+                "contributors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hermes_internal_vo.VerdictContributorVO"
+                    }
+                },
+                "explanation": {
+                    "type": "string",
+                    "example": "malicious (score 82) from 3 provider(s) with a verdict; driven by virustotal (malicious 90, weight 1.5)"
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 82
+                },
+                "verdict": {
+                    "type": "string",
+                    "enum": [
+                        "malicious",
+                        "suspicious",
+                        "clean",
+                        "unknown"
+                    ],
+                    "example": "malicious"
+                }
+            }
+        },
+        "hermes_internal_vo.AllowlistEntryVO": {
+            "type": "object",
+            "properties": {
+                "comment": {
+                    "type": "string",
+                    "example": "Office egress"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "indicator_type": {
+                    "type": "string",
+                    "example": "ip"
+                },
+                "value": {
+                    "type": "string",
+                    "example": "192.0.2.0/24"
+                }
+            }
+        },
+        "hermes_internal_vo.AssessmentVO": {
+            "description": "Normalized provider verdict",
+            "type": "object",
+            "properties": {
+                "first_seen": {
+                    "type": "string"
+                },
+                "last_seen": {
+                    "type": "string"
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "verdict": {
+                    "type": "string",
+                    "enum": [
+                        "malicious",
+                        "suspicious",
+                        "clean",
+                        "unknown"
+                    ],
+                    "example": "malicious"
+                }
+            }
+        },
+        "hermes_internal_vo.BlocklistVO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string",
+                    "example": "plain"
+                },
+                "indicator_type": {
+                    "type": "string",
+                    "example": "ip"
+                },
+                "max_age_hours": {
+                    "type": "integer",
+                    "example": 720
+                },
+                "min_providers": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "min_score": {
+                    "type": "integer",
+                    "example": 70
+                },
+                "modified_at": {
+                    "description": "ModifiedAt is when the list's entries last changed, as of the last refresh.",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "malicious-ips"
+                },
+                "path": {
+                    "description": "Path serves the rendered list.",
+                    "type": "string",
+                    "example": "/api/v1/blocklists/malicious-ips"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "verdicts": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "malicious"
+                    ]
+                }
+            }
+        },
+        "hermes_internal_vo.BulkJobItemVO": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "indicator_type": {
+                    "type": "string",
+                    "example": "ip"
+                },
+                "indicator_value": {
+                    "type": "string",
+                    "example": "8.8.8.8"
+                },
+                "position": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "pending",
+                        "done",
+                        "invalid",
+                        "error"
+                    ],
+                    "example": "done"
+                },
+                "verdict": {
+                    "type": "string",
+                    "example": "clean"
+                }
+            }
+        },
+        "hermes_internal_vo.BulkJobVO": {
+            "description": "Bulk lookup job progress and results",
+            "type": "object",
+            "properties": {
+                "completed": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "duplicates": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "error": {
+                    "description": "Error says why a failed job stopped; its remaining items stay pending.",
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "finished_at": {
+                    "type": "string"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hermes_internal_vo.BulkJobItemVO"
+                    }
+                },
+                "job_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "page": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "page_size": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "queued",
+                        "running",
+                        "completed",
+                        "failed"
+                    ],
+                    "example": "running"
+                },
+                "total": {
+                    "description": "Total is the number of unique indicators; Duplicates counts repeated input lines dropped.",
+                    "type": "integer",
+                    "example": 500
+                }
+            }
+        },
+        "hermes_internal_vo.CVEVO": {
+            "description": "CVE record merged across providers",
+            "type": "object",
+            "properties": {
+                "cpes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "cpe:2.3:a:tukaani:xz:5.6.0:*:*:*:*:*:*:*"
+                    ]
+                },
+                "cvss": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hermes_internal_vo.CVSSVO"
+                    }
+                },
+                "cwes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "CWE-506"
+                    ]
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "CVE-2024-3094"
+                },
+                "modified": {
+                    "type": "string"
+                },
+                "published": {
+                    "type": "string"
+                },
+                "references": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "sources": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "nvd",
+                        "circl_cve"
+                    ]
+                }
+            }
+        },
+        "hermes_internal_vo.CVSSVO": {
+            "type": "object",
+            "properties": {
+                "base_score": {
+                    "type": "number",
+                    "example": 10
+                },
+                "severity": {
+                    "type": "string",
+                    "example": "CRITICAL"
+                },
+                "source": {
+                    "type": "string",
+                    "example": "nvd"
+                },
+                "vector": {
+                    "type": "string",
+                    "example": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"
+                },
+                "version": {
+                    "type": "string",
+                    "example": "3.1"
+                }
+            }
+        },
+        "hermes_internal_vo.ClientLimitsVO": {
+            "description": "Effective client limits",
+            "type": "object",
+            "properties": {
+                "daily_quota": {
+                    "type": "integer",
+                    "example": 20000
+                },
+                "monthly_quota": {
+                    "type": "integer",
+                    "example": 400000
+                },
+                "rate_limit_per_min": {
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "hermes_internal_vo.ClientVO": {
+            "description": "API client",
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "keys": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hermes_internal_vo.APIKeyVO"
                     }
+                },
+                "limits": {
+                    "$ref": "#/definitions/hermes_internal_vo.ClientLimitsVO"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "soc-automation"
                 }
             }
-        }
-    },
-    "definitions": {
-        "hermes_internal_dto.LookupRequestDTO": {
-            "description": "Request body for unified lookup",
+        },
+        "hermes_internal_vo.CreatedAPIKeyVO": {
+            "description": "Newly issued API key",
             "type": "object",
-            "required": [
-                "indicator_type",
-                "indicator_value"
-            ],
             "properties": {
-                "indicator_type": {
-                    "description": "IndicatorType is one of: ip, domain, url, hash, email",
+                "active": {
+                    "type": "boolean"
+                },
+                "client_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "key": {
                     "type": "string",
-                    "enum": [
-                        "ip",
-                        "domain",
-                        "url",
-                        "hash",
-                        "email"
-                    ],
-                    "example": "ip"
+                    "example": "hk_3f9a1c2e_5b0e..."
                 },
-                "indicator_value": {
-                    "description": "IndicatorValue is the value to look up (e.g. IP, domain, URL, hash, email)",
+                "key_id": {
                     "type": "string",
-                    "example": "8.8.8.8"
+                    "example": "3f9a1c2e"
                 },
-                "providers": {
-                    "description": "Providers optionally limits which providers to query (empty = all enabled)",
+                "last_used_at": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "scopes": {
                     "type": "array",
                     "items": {
                         "type": "string"
-                    }
+                    },
+                    "example": [
+                        "lookup:read",
+                        "provider:abuseipdb"
+                    ]
                 }
             }
         },
@@ -166,16 +2859,71 @@ const docTemplate = `{
                     "type": "string",
                     "example": "BAD_REQUEST"
                 },
+                "details": {
+                    "description": "Details lists per-field validation problems, when there are any.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hermes_internal_vo.FieldErrorVO"
+                    }
+                },
                 "message": {
                     "type": "string",
                     "example": "invalid request"
                 }
             }
         },
+        "hermes_internal_vo.FieldErrorVO": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "type": "string",
+                    "example": "indicator_value"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "private or reserved address"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "private_ip"
+                }
+            }
+        },
+        "hermes_internal_vo.LookupHistoryVO": {
+            "description": "Page of past lookups",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/hermes_internal_vo.LookupSummaryVO"
+                    }
+                },
+                "next_cursor": {
+                    "type": "string"
+                }
+            }
+        },
         "hermes_internal_vo.LookupResponseVO": {
             "description": "Response for unified lookup across providers",
             "type": "object",
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "cve": {
+                    "description": "CVE merges the vulnerability providers' records (cve lookups only).",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/hermes_internal_vo.CVEVO"
+                        }
+                    ]
+                },
+                "hash_type": {
+                    "description": "HashType is the detected algorithm of a hash indicator (md5, sha1, sha256, sha512, ssdeep, tlsh).",
+                    "type": "string",
+                    "example": "sha256"
+                },
                 "indicator_type": {
                     "type": "string",
                     "example": "ip"
@@ -184,6 +2932,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": ""
                 },
+                "partial": {
+                    "description": "Partial is true when the lookup deadline passed before every provider answered, or some\nresults are still pending.",
+                    "type": "boolean"
+                },
                 "request_id": {
                     "type": "string",
                     "example": "550e8400-e29b-41d4-a716-446655440000"
@@ -193,16 +2945,139 @@ const docTemplate = `{
                     "additionalProperties": {
                         "$ref": "#/definitions/hermes_internal_vo.ProviderResultVO"
                     }
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is when the verdict was last stored, e.g. after a pending provider finished.",
+                    "type": "string"
+                },
+                "verdict": {
+                    "description": "Verdict aggregates the providers' normalized verdicts.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/hermes_internal_vo.AggregateVerdictVO"
+                        }
+                    ]
+                }
+            }
+        },
+        "hermes_internal_vo.LookupSummaryVO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "indicator_type": {
+                    "type": "string",
+                    "example": "ip"
+                },
+                "indicator_value": {
+                    "type": "string",
+                    "example": "8.8.8.8"
+                },
+                "providers": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "abuseipdb",
+                        "virustotal"
+                    ]
+                },
+                "request_id": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "verdict": {
+                    "type": "string",
+                    "example": "clean"
+                }
+            }
+        },
+        "hermes_internal_vo.MISPPushVO": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "created": {
+                    "description": "Created is false when an earlier push of the same lookup or job was updated.",
+                    "type": "boolean"
+                },
+                "event_id": {
+                    "description": "EventID is MISP's ID of the event, EventUUID the UUID Hermes derives for it.",
+                    "type": "string",
+                    "example": "1234"
+                },
+                "event_uuid": {
+                    "type": "string",
+                    "example": "5d1c3b6a-2f4e-5a8b-9c0d-1e2f3a4b5c6d"
+                }
+            }
+        },
+        "hermes_internal_vo.ProviderHealthVO": {
+            "description": "Provider canary lookup result",
+            "type": "object",
+            "properties": {
+                "checked_at": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "healthy": {
+                    "type": "boolean"
+                },
+                "indicator_type": {
+                    "type": "string",
+                    "example": "ip"
+                },
+                "indicator_value": {
+                    "type": "string",
+                    "example": "8.8.8.8"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 380
+                },
+                "provider_code": {
+                    "type": "string",
+                    "example": "abuseipdb"
+                },
+                "status": {
+                    "description": "Status is the canary result's status (ok, error, timed_out, rate_limited, pending), or\nskipped when every lookup the provider supports would start a provider-side job.",
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "error",
+                        "timed_out",
+                        "rate_limited",
+                        "pending",
+                        "skipped"
+                    ],
+                    "example": "ok"
                 }
             }
         },
         "hermes_internal_vo.ProviderLookupResponseVO": {
             "type": "object",
             "properties": {
+                "assessment": {
+                    "$ref": "#/definitions/hermes_internal_vo.AssessmentVO"
+                },
                 "data": {},
                 "error": {
                     "type": "string"
                 },
+                "pending": {
+                    "description": "Pending is true when the provider is still working; Data identifies the pending work.",
+                    "type": "boolean"
+                },
                 "provider_code": {
                     "type": "string"
                 },
@@ -211,10 +3086,45 @@ const docTemplate = `{
                 }
             }
         },
+        "hermes_internal_vo.ProviderRecentVO": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "type": "integer",
+                    "example": 420
+                },
+                "calls": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "last_call_at": {
+                    "type": "string"
+                },
+                "p95_latency_ms": {
+                    "type": "integer",
+                    "example": 1300
+                },
+                "success_rate": {
+                    "description": "SuccessRate is the share of calls that answered (0..1); absent without calls.",
+                    "type": "number",
+                    "example": 0.97
+                }
+            }
+        },
         "hermes_internal_vo.ProviderResultVO": {
             "description": "Single provider lookup result",
             "type": "object",
             "properties": {
+                "assessment": {
+                    "$ref": "#/definitions/hermes_internal_vo.AssessmentVO"
+                },
+                "cache_age_seconds": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "cached": {
+                    "type": "boolean"
+                },
                 "data": {},
                 "error": {
                     "type": "string"
@@ -223,10 +3133,186 @@ const docTemplate = `{
                     "type": "string",
                     "example": "abuseipdb"
                 },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "error",
+                        "timed_out",
+                        "rate_limited",
+                        "pending"
+                    ],
+                    "example": "ok"
+                },
                 "success": {
                     "type": "boolean"
                 }
             }
+        },
+        "hermes_internal_vo.ProviderStatusVO": {
+            "description": "Provider capabilities and status",
+            "type": "object",
+            "properties": {
+                "async": {
+                    "description": "Async providers answer \"pending\" and finish in the background.",
+                    "type": "boolean"
+                },
+                "code": {
+                    "type": "string",
+                    "example": "abuseipdb"
+                },
+                "configured": {
+                    "description": "Configured is false when the provider's API key is missing; its lookups then fail.",
+                    "type": "boolean"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "hash_types": {
+                    "description": "HashTypes lists the accepted hash algorithms when the provider takes only some.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "md5",
+                        "sha256"
+                    ]
+                },
+                "name": {
+                    "type": "string",
+                    "example": "AbuseIPDB"
+                },
+                "rate_limit_per_min": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "recent": {
+                    "$ref": "#/definitions/hermes_internal_vo.ProviderRecentVO"
+                },
+                "supported_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ip"
+                    ]
+                }
+            }
+        },
+        "hermes_internal_vo.TAXIICollectionVO": {
+            "description": "TAXII collection",
+            "type": "object",
+            "properties": {
+                "alias": {
+                    "type": "string",
+                    "example": "malicious-last-7d"
+                },
+                "can_read": {
+                    "type": "boolean"
+                },
+                "can_write": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9c119955-a20b-59a3-9e0e-fdb93756a999"
+                },
+                "media_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Malicious indicators, last 7 days"
+                }
+            }
+        },
+        "hermes_internal_vo.UsageVO": {
+            "description": "Client usage and limits",
+            "type": "object",
+            "properties": {
+                "client": {
+                    "type": "string",
+                    "example": "soc-automation"
+                },
+                "day": {
+                    "$ref": "#/definitions/hermes_internal_vo.UsageWindowVO"
+                },
+                "month": {
+                    "$ref": "#/definitions/hermes_internal_vo.UsageWindowVO"
+                },
+                "rate_limit_per_min": {
+                    "description": "RateLimitPerMin bounds HTTP requests per minute (0 = unlimited).",
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "hermes_internal_vo.UsageWindowVO": {
+            "description": "Usage within a quota window",
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "description": "Limit is the provider-call quota (0 = unlimited).",
+                    "type": "integer",
+                    "example": 20000
+                },
+                "provider_calls": {
+                    "type": "integer",
+                    "example": 517
+                },
+                "remaining": {
+                    "description": "Remaining is omitted when unlimited.",
+                    "type": "integer",
+                    "example": 19483
+                },
+                "requests": {
+                    "type": "integer",
+                    "example": 42
+                },
+                "resets_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "hermes_internal_vo.VerdictContributorVO": {
+            "type": "object",
+            "properties": {
+                "drove": {
+                    "type": "boolean"
+                },
+                "provider_code": {
+                    "type": "string",
+                    "example": "virustotal"
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 90
+                },
+                "verdict": {
+                    "type": "string",
+                    "example": "malicious"
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 1.5
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "API key; \"Authorization: Bearer \u003ckey\u003e\" is accepted as well.",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,111 @@
+// Package auth holds API key primitives and the authenticated principal carried in request
+// contexts.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Scopes granted to API keys. Provider scopes are "provider:<code>" (see ProviderScope) or
+// "provider:*" for every provider.
+const (
+	// ScopeLookupRead allows unified and bulk lookups and reading lookup history.
+	ScopeLookupRead = "lookup:read"
+	// ScopeTAXIIRead allows reading the TAXII collections under /taxii2/.
+	ScopeTAXIIRead = "taxii:read"
+	// ScopeMISPPush allows pushing lookups and bulk jobs to the configured MISP instance.
+	ScopeMISPPush = "misp:push"
+	// ScopeBlocklistRead allows fetching rendered blocklists under /blocklists.
+	ScopeBlocklistRead = "blocklist:read"
+	// ScopeAdmin allows everything, including key management and other clients' history.
+	ScopeAdmin = "admin"
+	// ScopeAllProviders allows single-provider lookups on every provider.
+	ScopeAllProviders = "provider:*"
+)
+
+// ProviderScope is the scope allowing single-provider lookups on code.
+func ProviderScope(code string) string { return "provider:" + code }
+
+// keyPrefix marks Hermes API keys so they are recognizable in secret scanners and logs.
+const keyPrefix = "hk_"
+
+// Principal is the authenticated caller.
+type Principal struct {
+	ClientID int64
+	// ClientName identifies the client in stored records (lookup_requests.user_id).
+	ClientName string
+	// KeyID is the public identifier of the key used.
+	KeyID  string
+	Scopes []string
+	// Limits are the client's effective request rate and provider-call quotas.
+	Limits Limits
+}
+
+// Limits are per-client limits; 0 means unlimited.
+type Limits struct {
+	// RatePerMin bounds HTTP requests per minute.
+	RatePerMin int
+	// DailyCalls and MonthlyCalls bound provider calls per UTC day and month.
+	DailyCalls   int
+	MonthlyCalls int
+}
+
+// Allows reports whether p has scope; admin allows every scope and provider:* every provider scope.
+func (p *Principal) Allows(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.Scopes {
+		if s == scope || s == ScopeAdmin || (s == ScopeAllProviders && strings.HasPrefix(scope, "provider:")) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsAdmin reports whether p has the admin scope.
+func (p *Principal) IsAdmin() bool { return p.Allows(ScopeAdmin) }
+
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying p.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal attached by WithPrincipal, or nil when unauthenticated.
+func FromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// UserID returns the client name of ctx's principal for storing with records, or nil.
+func UserID(ctx context.Context) *string {
+	p := FromContext(ctx)
+	if p == nil || p.ClientName == "" {
+		return nil
+	}
+	name := p.ClientName
+	return &name
+}
+
+// GenerateKey returns a new API key ("hk_<key id>_<secret>"), its public key ID and the hash to store.
+func GenerateKey() (key, keyID, hash string, err error) {
+	b := make([]byte, 28)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	keyID = hex.EncodeToString(b[:4])
+	key = keyPrefix + keyID + "_" + hex.EncodeToString(b[4:])
+	return key, keyID, HashKey(key), nil
+}
+
+// HashKey is the stored form of an API key (hex SHA-256). Keys are random, so no salt is needed.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrincipal_Allows(t *testing.T) {
+	reader := &Principal{Scopes: []string{ScopeLookupRead, ProviderScope("abuseipdb")}}
+	assert.True(t, reader.Allows(ScopeLookupRead))
+	assert.True(t, reader.Allows(ProviderScope("abuseipdb")))
+	assert.False(t, reader.Allows(ProviderScope("virustotal")))
+	assert.False(t, reader.IsAdmin())
+
+	all := &Principal{Scopes: []string{ScopeAllProviders}}
+	assert.True(t, all.Allows(ProviderScope("virustotal")))
+	assert.False(t, all.Allows(ScopeLookupRead))
+
+	admin := &Principal{Scopes: []string{ScopeAdmin}}
+	assert.True(t, admin.Allows(ScopeLookupRead))
+	assert.True(t, admin.Allows(ProviderScope("virustotal")))
+
+	var none *Principal
+	assert.False(t, none.Allows(ScopeLookupRead))
+}
+
+func TestGenerateKey(t *testing.T) {
+	key, keyID, hash, err := GenerateKey()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "hk_"+keyID+"_"))
+	assert.Equal(t, HashKey(key), hash)
+	assert.Len(t, hash, 64)
+
+	other, _, _, _ := GenerateKey()
+	assert.NotEqual(t, key, other)
+}
+
+func TestUserID(t *testing.T) {
+	assert.Nil(t, UserID(context.Background()))
+	ctx := WithPrincipal(context.Background(), &Principal{ClientName: "soc"})
+	assert.Equal(t, "soc", *UserID(ctx))
+}
@@ -0,0 +1,359 @@
+// Package blocklist renders indicators as enforcement artifacts: plain lists (IPs collapsed into
+// CIDR blocks), DNS Response Policy Zones, Squid ACL files and Suricata rules. Output is
+// deterministic for the same entries, so devices can poll with conditional requests.
+package blocklist
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"hermes/internal/indicator"
+)
+
+// Formats a list can be rendered in.
+const (
+	FormatPlain    = "plain"
+	FormatRPZ      = "rpz"
+	FormatSquid    = "squid"
+	FormatSuricata = "suricata"
+)
+
+// formats lists the formats of each indicator type a blocklist can hold.
+var formats = map[string][]string{
+	indicator.TypeIP:     {FormatPlain, FormatSuricata},
+	indicator.TypeDomain: {FormatPlain, FormatRPZ, FormatSquid, FormatSuricata},
+	indicator.TypeURL:    {FormatPlain, FormatSquid},
+	indicator.TypeHash:   {FormatPlain, FormatSuricata},
+	indicator.TypeEmail:  {FormatPlain},
+}
+
+// Supports reports whether lists of indicatorType can be rendered in format.
+func Supports(indicatorType, format string) bool {
+	for _, f := range formats[indicatorType] {
+		if f == format {
+			return true
+		}
+	}
+	return false
+}
+
+// ContentType returns the media type of format.
+func ContentType(format string) string {
+	if format == FormatRPZ {
+		return "text/dns; charset=utf-8"
+	}
+	return "text/plain; charset=utf-8"
+}
+
+// sidBase starts the range of Suricata signature IDs; IDs below 1000000 are reserved.
+const sidBase = 1000000000
+
+// List is a blocklist to render.
+type List struct {
+	Name          string
+	IndicatorType string
+	Format        string
+	// Values are canonical indicators; HashTypes holds the algorithm of each hash, by value.
+	Values    []string
+	HashTypes map[string]string
+	// Exceptions are allowlisted domains under listed domains of a domain list. Listing a domain
+	// blocks its subdomains, so RPZ zones and Suricata rules let these through, and Squid files,
+	// which cannot, name them in a comment.
+	Exceptions []string
+	// Modified is when the entries last changed; it is the RPZ serial.
+	Modified time.Time
+}
+
+// Render writes l in its format, entries sorted.
+func Render(w io.Writer, l *List) error {
+	if !Supports(l.IndicatorType, l.Format) {
+		return fmt.Errorf("%s lists cannot be rendered as %s", l.IndicatorType, l.Format)
+	}
+	values := append([]string(nil), l.Values...)
+	sort.Strings(values)
+	bw := bufio.NewWriter(w)
+	switch l.Format {
+	case FormatRPZ:
+		renderRPZ(bw, l, values)
+	case FormatSquid:
+		renderSquid(bw, l, values)
+	case FormatSuricata:
+		renderSuricata(bw, l, values)
+	default:
+		if l.IndicatorType == indicator.TypeIP {
+			values = CollapseIPs(values)
+		}
+		for _, v := range values {
+			fmt.Fprintln(bw, v)
+		}
+	}
+	return bw.Flush()
+}
+
+// renderRPZ answers NXDOMAIN for each domain and its subdomains, except the exceptions and their
+// subdomains: their own names are more specific than the wildcards of the blocked domains.
+func renderRPZ(w io.Writer, l *List, domains []string) {
+	fmt.Fprintf(w, "; Hermes blocklist %s: %d domains\n", l.Name, len(domains))
+	fmt.Fprintf(w, "$TTL 300\n@ IN SOA localhost. hostmaster.localhost. %d 3600 600 604800 300\n@ IN NS localhost.\n", l.Modified.Unix())
+	for _, d := range domains {
+		fmt.Fprintf(w, "%s CNAME .\n*.%s CNAME .\n", d, d)
+	}
+	for _, d := range l.Exceptions {
+		fmt.Fprintf(w, "%s CNAME rpz-passthru.\n*.%s CNAME rpz-passthru.\n", d, d)
+	}
+}
+
+// renderSquid writes a dstdomain ACL file for domains (matching subdomains too, exceptions
+// included) or a url_regex ACL file for URLs.
+func renderSquid(w io.Writer, l *List, values []string) {
+	if l.IndicatorType == indicator.TypeDomain {
+		fmt.Fprintf(w, "# Hermes blocklist %s: acl %s dstdomain \"/path/to/this/file\"\n", l.Name, l.Name)
+		for _, d := range l.Exceptions {
+			fmt.Fprintf(w, "# allowlisted but matched below: %s (allow it before this ACL)\n", d)
+		}
+		for _, d := range values {
+			fmt.Fprintln(w, "."+d)
+		}
+		return
+	}
+	fmt.Fprintf(w, "# Hermes blocklist %s: acl %s url_regex \"/path/to/this/file\"\n", l.Name, l.Name)
+	for _, u := range values {
+		fmt.Fprintln(w, "^"+regexp.QuoteMeta(u))
+	}
+}
+
+// renderSuricata writes one drop rule per IP or domain, and a pass rule per exception, which
+// Suricata applies before drop rules. Hashes cannot be written into rules, so
+// hash lists get one rule per algorithm loading the list file of that algorithm (see HashFile).
+// Signature IDs are derived from the list name and the values, so they are stable across renders;
+// see sids. IP rules are valid Snort rules as well.
+func renderSuricata(w io.Writer, l *List, values []string) {
+	fmt.Fprintf(w, "# Hermes blocklist %s\n", l.Name)
+	ids := make(sids)
+	switch l.IndicatorType {
+	case indicator.TypeIP:
+		for _, ip := range values {
+			fmt.Fprintf(w, "drop ip any any <> %s any (msg:\"Hermes %s: %s\"; sid:%d; rev:1;)\n", ip, l.Name, ip, ids.next(l.Name+":"+ip))
+		}
+	case indicator.TypeDomain:
+		for _, d := range values {
+			fmt.Fprintf(w, "drop dns any any -> any any (msg:\"Hermes %s: %s\"; dns.query; dotprefix; content:\".%s\"; nocase; endswith; sid:%d; rev:1;)\n",
+				l.Name, d, d, ids.next(l.Name+":"+d))
+		}
+		for _, d := range l.Exceptions {
+			fmt.Fprintf(w, "pass dns any any -> any any (msg:\"Hermes %s: allowlisted %s\"; dns.query; dotprefix; content:\".%s\"; nocase; endswith; sid:%d; rev:1;)\n",
+				l.Name, d, d, ids.next(l.Name+":pass:"+d))
+		}
+	case indicator.TypeHash:
+		seen := make(map[string]bool)
+		for _, v := range values {
+			seen[l.HashTypes[v]] = true
+		}
+		for _, algo := range []string{indicator.HashMD5, indicator.HashSHA1, indicator.HashSHA256} {
+			if !seen[algo] {
+				continue
+			}
+			fmt.Fprintf(w, "# %s: the %s hashes (?hash_type=%s)\n", HashFile(l.Name, algo), algo, algo)
+			fmt.Fprintf(w, "drop http any any -> any any (msg:\"Hermes %s: known %s\"; file%s:%s; sid:%d; rev:1;)\n",
+				l.Name, algo, algo, HashFile(l.Name, algo), ids.next(l.Name+":"+algo))
+		}
+	}
+}
+
+// HashFile is the name Suricata rules of a hash list give the file of its algo hashes.
+func HashFile(name, algo string) string {
+	return name + "." + algo
+}
+
+// sids hands out the signature IDs of one rendering. Suricata refuses duplicate IDs, so a key
+// whose hash is taken by an earlier key gets the next free ID. Keys are handed out in sorted
+// order, so IDs only move when a colliding key is added or removed.
+type sids map[uint32]bool
+
+func (s sids) next(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	id := h.Sum32() % sidBase
+	for s[id] {
+		id = (id + 1) % sidBase
+	}
+	s[id] = true
+	return sidBase + id
+}
+
+// CollapseIPs merges IPs into the fewest CIDR blocks covering exactly them; IPv4 blocks come
+// first. Values that are not IPs are dropped.
+func CollapseIPs(values []string) []string {
+	var addrs []netip.Addr
+	for _, v := range values {
+		if a, err := netip.ParseAddr(v); err == nil {
+			addrs = append(addrs, a.Unmap())
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Less(addrs[j]) })
+
+	var out []string
+	for i := 0; i < len(addrs); {
+		// Grow the run of consecutive addresses starting at i.
+		j := i
+		for j+1 < len(addrs) && addrs[j].Next() == addrs[j+1] {
+			j++
+		}
+		for _, p := range rangePrefixes(addrs[i], addrs[j]) {
+			out = append(out, prefixString(p))
+		}
+		i = j + 1
+		for i < len(addrs) && addrs[i] == addrs[j] {
+			i++
+		}
+	}
+	return out
+}
+
+// rangePrefixes covers the range [from, to] with the largest aligned prefixes.
+func rangePrefixes(from, to netip.Addr) []netip.Prefix {
+	var out []netip.Prefix
+	for {
+		// Widen the prefix at from while it stays aligned and ends within the range.
+		bits := from.BitLen()
+		for bits > 0 {
+			p := netip.PrefixFrom(from, bits-1).Masked()
+			if p.Addr() != from || lastAddr(p).Compare(to) > 0 {
+				break
+			}
+			bits--
+		}
+		p := netip.PrefixFrom(from, bits)
+		out = append(out, p)
+		last := lastAddr(p)
+		if last.Compare(to) >= 0 {
+			return out
+		}
+		from = last.Next()
+	}
+}
+
+// lastAddr returns the last address of p.
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+// prefixString writes single addresses without their /32 or /128.
+func prefixString(p netip.Prefix) string {
+	if p.IsSingleIP() {
+		return p.Addr().String()
+	}
+	return p.String()
+}
+
+// Allowlist excludes indicators from blocklists: IPs within allowlisted IPs or CIDR blocks,
+// allowlisted domains and their subdomains (also as URL hosts), and exact values otherwise.
+type Allowlist struct {
+	prefixes []netip.Prefix
+	domains  map[string]bool
+	exact    map[string]bool
+}
+
+// NewAllowlist returns an empty allowlist.
+func NewAllowlist() *Allowlist {
+	return &Allowlist{domains: make(map[string]bool), exact: make(map[string]bool)}
+}
+
+// Add allowlists value of indicatorType; IP values may be CIDR blocks.
+func (a *Allowlist) Add(indicatorType, value string) {
+	switch indicatorType {
+	case indicator.TypeIP:
+		if p, err := netip.ParsePrefix(value); err == nil {
+			a.prefixes = append(a.prefixes, p.Masked())
+		} else if ip, err := netip.ParseAddr(value); err == nil {
+			a.prefixes = append(a.prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+		}
+	case indicator.TypeDomain:
+		a.domains[strings.ToLower(strings.TrimSuffix(value, "."))] = true
+	default:
+		a.exact[indicatorType+":"+value] = true
+	}
+}
+
+// Allows reports whether value of indicatorType is allowlisted.
+func (a *Allowlist) Allows(indicatorType, value string) bool {
+	switch indicatorType {
+	case indicator.TypeIP:
+		ip, err := netip.ParseAddr(value)
+		if err != nil {
+			return false
+		}
+		for _, p := range a.prefixes {
+			if p.Contains(ip.Unmap()) {
+				return true
+			}
+		}
+		return false
+	case indicator.TypeDomain:
+		return a.domainAllowed(value)
+	case indicator.TypeURL:
+		if a.exact[indicatorType+":"+value] {
+			return true
+		}
+		u, err := url.Parse(value)
+		return err == nil && a.domainAllowed(u.Hostname())
+	default:
+		return a.exact[indicatorType+":"+value]
+	}
+}
+
+// Under returns the allowlisted domains that are subdomains of one of domains, sorted; see
+// List.Exceptions.
+func (a *Allowlist) Under(domains []string) []string {
+	listed := make(map[string]bool, len(domains))
+	for _, d := range domains {
+		listed[d] = true
+	}
+	var out []string
+	for d := range a.domains {
+		for p := parentDomain(d); p != ""; p = parentDomain(p) {
+			if listed[p] {
+				out = append(out, d)
+				break
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func parentDomain(d string) string {
+	if i := strings.IndexByte(d, '.'); i >= 0 {
+		return d[i+1:]
+	}
+	return ""
+}
+
+// domainAllowed reports whether d or one of its parent domains is allowlisted.
+func (a *Allowlist) domainAllowed(d string) bool {
+	d = strings.ToLower(strings.TrimSuffix(d, "."))
+	for d != "" {
+		if a.domains[d] {
+			return true
+		}
+		i := strings.IndexByte(d, '.')
+		if i < 0 {
+			return false
+		}
+		d = d[i+1:]
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package blocklist
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func render(t *testing.T, l *List) string {
+	var buf bytes.Buffer
+	assert.NoError(t, Render(&buf, l))
+	return buf.String()
+}
+
+func TestCollapseIPs(t *testing.T) {
+	ips := []string{"10.0.0.3", "10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.4", "192.0.2.1", "192.0.2.1", "2001:db8::1", "2001:db8::", "bogus"}
+	assert.Equal(t, []string{"10.0.0.0/30", "10.0.0.4", "192.0.2.1", "2001:db8::/127"}, CollapseIPs(ips))
+	// Consecutive but unaligned addresses stay apart.
+	assert.Equal(t, []string{"10.0.0.255", "10.0.1.0"}, CollapseIPs([]string{"10.0.1.0", "10.0.0.255"}))
+}
+
+func TestRender(t *testing.T) {
+	modified := time.Unix(1740000000, 0)
+	assert.Equal(t, "10.0.0.0/31\n", render(t, &List{Name: "ips", IndicatorType: "ip", Format: FormatPlain, Values: []string{"10.0.0.1", "10.0.0.0"}}))
+
+	rpz := render(t, &List{Name: "doms", IndicatorType: "domain", Format: FormatRPZ, Values: []string{"evil.example"}, Modified: modified})
+	assert.Contains(t, rpz, "hostmaster.localhost. 1740000000 ")
+	assert.Contains(t, rpz, "evil.example CNAME .\n*.evil.example CNAME .\n")
+
+	// An allowlisted subdomain of a listed domain passes through.
+	rpz = render(t, &List{Name: "doms", IndicatorType: "domain", Format: FormatRPZ, Values: []string{"evil.example"}, Exceptions: []string{"ok.evil.example"}})
+	assert.True(t, strings.HasSuffix(rpz, "*.evil.example CNAME .\nok.evil.example CNAME rpz-passthru.\n*.ok.evil.example CNAME rpz-passthru.\n"))
+	rules := render(t, &List{Name: "doms", IndicatorType: "domain", Format: FormatSuricata, Values: []string{"evil.example"}, Exceptions: []string{"ok.evil.example"}})
+	assert.Contains(t, rules, `pass dns any any -> any any (msg:"Hermes doms: allowlisted ok.evil.example"; dns.query; dotprefix; content:".ok.evil.example"; nocase; endswith; sid:`)
+	squid := render(t, &List{Name: "doms", IndicatorType: "domain", Format: FormatSquid, Values: []string{"evil.example"}, Exceptions: []string{"ok.evil.example"}})
+	assert.Contains(t, squid, "# allowlisted but matched below: ok.evil.example")
+
+	squid = render(t, &List{Name: "urls", IndicatorType: "url", Format: FormatSquid, Values: []string{"http://evil.example/a.php?x=1"}})
+	assert.True(t, strings.HasSuffix(squid, "^http://evil\\.example/a\\.php\\?x=1\n"))
+
+	rules = render(t, &List{Name: "doms", IndicatorType: "domain", Format: FormatSuricata, Values: []string{"evil.example"}})
+	assert.Contains(t, rules, `drop dns any any -> any any (msg:"Hermes doms: evil.example"; dns.query; dotprefix; content:".evil.example"; nocase; endswith; sid:`)
+	assert.Equal(t, rules, render(t, &List{Name: "doms", IndicatorType: "domain", Format: FormatSuricata, Values: []string{"evil.example"}}))
+
+	// The SIDs of these two hash alike: the second one gets the next ID.
+	rules = render(t, &List{Name: "doms", IndicatorType: "domain", Format: FormatSuricata, Values: []string{"h36388.example", "h148346.example"}})
+	assert.Contains(t, rules, "sid:1182688456;")
+	assert.Contains(t, rules, "sid:1182688457;")
+
+	hashes := render(t, &List{Name: "files", IndicatorType: "hash", Format: FormatSuricata, Values: []string{"a", "b"},
+		HashTypes: map[string]string{"a": "md5", "b": "sha256"}})
+	assert.Contains(t, hashes, "filemd5:files.md5;")
+	assert.Contains(t, hashes, "filesha256:files.sha256;")
+	assert.NotContains(t, hashes, "filesha1")
+
+	assert.Error(t, Render(&bytes.Buffer{}, &List{IndicatorType: "url", Format: FormatRPZ}))
+}
+
+func TestAllowlist(t *testing.T) {
+	a := NewAllowlist()
+	a.Add("ip", "192.0.2.0/24")
+	a.Add("ip", "2001:db8::1")
+	a.Add("domain", "example.com")
+	a.Add("hash", "abc")
+	assert.True(t, a.Allows("ip", "192.0.2.77"))
+	assert.False(t, a.Allows("ip", "192.0.3.1"))
+	assert.True(t, a.Allows("ip", "2001:db8::1"))
+	assert.True(t, a.Allows("domain", "www.example.com"))
+	assert.False(t, a.Allows("domain", "notexample.com"))
+	assert.True(t, a.Allows("url", "https://cdn.example.com:8443/x"))
+	assert.True(t, a.Allows("hash", "abc"))
+	assert.False(t, a.Allows("email", "abc"))
+
+	a.Add("domain", "cdn.evil.example")
+	a.Add("domain", "evil.example.org")
+	assert.Equal(t, []string{"cdn.evil.example"}, a.Under([]string{"evil.example", "example.com", "other.example"}))
+}
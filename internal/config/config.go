@@ -1,35 +1,112 @@
 package config
 
 import (
+	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config holds application configuration loaded from environment.
 type Config struct {
-	HTTPPort         int
-	PostgresDSN      string
-	LogLevel         string
-	CacheTTLSeconds  int
+	HTTPPort        int
+	PostgresDSN     string
+	LogLevel        string
+	CacheTTLSeconds int
+	// LookupDeadlineSeconds bounds a unified lookup; providers still running are reported as timed_out.
+	LookupDeadlineSeconds int
+	// DefaultProviderPolicy applies to every provider without an entry in ProviderPolicies.
+	DefaultProviderPolicy ProviderPolicy
+	// ProviderPolicies overrides timeout/retry settings per provider code (PROVIDER_POLICIES).
+	ProviderPolicies map[string]ProviderPolicy
+	// ProviderRefreshSeconds is how often the providers table (enabled, rate_limit_per_min) is re-read.
+	ProviderRefreshSeconds int
+	// ProviderRateLimitMode is "queue" (wait for a token within the lookup deadline) or "reject".
+	ProviderRateLimitMode string
+	// AllowPrivateIndicators accepts private/reserved IPs (and URLs on them) as indicators.
+	AllowPrivateIndicators bool
+	// BulkConcurrency bounds the lookups of all bulk jobs running at once.
+	BulkConcurrency int
+	// BulkMaxIndicators caps the indicators of one bulk job.
+	BulkMaxIndicators int
+	// ProviderWeights weighs providers in the aggregated verdict (PROVIDER_WEIGHTS); missing codes weigh 1.
+	ProviderWeights map[string]float64
+	// AuthRequired enforces API keys on /api/v1 routes (AUTH_REQUIRED=false only for local development).
+	AuthRequired bool
+	// AdminAPIKey authenticates as admin without a database row, to bootstrap clients and keys.
+	AdminAPIKey string
+	// ClientRateLimitPerMin, ClientDailyQuota and ClientMonthlyQuota are the default per-client
+	// limits (0 = unlimited); quotas count provider calls, not HTTP requests.
+	ClientRateLimitPerMin int
+	ClientDailyQuota      int
+	ClientMonthlyQuota    int
+	// URLScanWaitSeconds is how long a url lookup waits for a new urlscan.io scan before reporting
+	// it as pending (bounded by the lookup deadline).
+	URLScanWaitSeconds int
+	// SSLLabsMaxAgeHours is the oldest cached SSL Labs report reused (0 = always start a new assessment).
+	SSLLabsMaxAgeHours int
+	// PendingTimeoutSeconds bounds how long an asynchronous provider job is polled (0 = no limit).
+	PendingTimeoutSeconds int
+	// JobWorkers is the number of workers polling asynchronous provider jobs.
+	JobWorkers int
+	// LogLevels overrides LogLevel per component (LOG_LEVELS, e.g. "service=debug,http=warn").
+	LogLevels map[string]slog.Level
+	// LogBodies logs request bodies, with indicators anonymized (LOG_BODIES=true).
+	LogBodies bool
+	// TracingExporter is "none" (no-op tracing) or "otlp" (export to OTEL_EXPORTER_OTLP_ENDPOINT).
+	TracingExporter string
+	// TracingSampleRatio is the fraction of new traces sampled; traces started by callers keep their decision.
+	TracingSampleRatio float64
+	// MISPURL and MISPAPIKey enable pushing lookups to a MISP instance (empty = push disabled).
+	MISPURL    string
+	MISPAPIKey string
+	// MISPDistribution is the distribution of exported events (0 = your organisation only .. 3 = all).
+	MISPDistribution int
+	// MISPPublish publishes pushed events, which notifies MISP's sharing partners.
+	MISPPublish bool
 	// Provider API keys (empty = skip provider)
-	AbuseIPDBAPIKey           string
-	VirusTotalAPIKey          string
-	PhishTankAppKey           string
-	GoogleSafeBrowsingAPIKey  string
-	URLScanAPIKey             string
-	HIBPAPIKey                string
-	NVDAPIKey                 string
-	BinaryEdgeAPIKey          string
-	CriminalIPAPIKey          string
-	PulsediveAPIKey           string
-	EmailRepAPIKey            string
-	VulnersAPIKey             string
+	AbuseIPDBAPIKey          string
+	VirusTotalAPIKey         string
+	PhishTankAppKey          string
+	GoogleSafeBrowsingAPIKey string
+	URLScanAPIKey            string
+	HIBPAPIKey               string
+	NVDAPIKey                string
+	BinaryEdgeAPIKey         string
+	CriminalIPAPIKey         string
+	PulsediveAPIKey          string
+	EmailRepAPIKey           string
+	VulnersAPIKey            string
 	// Batch 3: Malware / SSL
-	HybridAnalysisAPIKey     string
-	MalshareAPIKey            string
-	MalwareBazaarAPIKey       string
+	HybridAnalysisAPIKey string
+	MalshareAPIKey       string
+	MalwareBazaarAPIKey  string
+	// abuse.ch: URLhaus, ThreatFox, Feodo Tracker (Feodo's blocklist is public; the key is optional)
+	URLhausAPIKey      string
+	ThreatFoxAPIKey    string
+	FeodoTrackerAPIKey string
+}
+
+// ProviderPolicy is the timeout and retry-with-backoff policy for one provider.
+type ProviderPolicy struct {
+	// Timeout bounds each HTTP attempt.
+	Timeout time.Duration
+	// MaxRetries is the number of retries after a 429, 5xx or transport error.
+	MaxRetries int
+	// Backoff is the first retry delay (doubled per retry) when the provider sends no Retry-After.
+	Backoff time.Duration
+}
+
+// PolicyFor returns the policy for a provider code, falling back to DefaultProviderPolicy.
+func (c *Config) PolicyFor(code string) ProviderPolicy {
+	if p, ok := c.ProviderPolicies[code]; ok {
+		return p
+	}
+	return c.DefaultProviderPolicy
 }
 
 // Load reads .env if present and populates Config from environment.
@@ -38,30 +115,173 @@ func Load() (*Config, error) {
 
 	port, _ := strconv.Atoi(getEnv("HTTP_PORT", "8080"))
 	cacheTTL, _ := strconv.Atoi(getEnv("CACHE_TTL_SECONDS", "3600"))
+	deadline, _ := strconv.Atoi(getEnv("LOOKUP_DEADLINE_SECONDS", "20"))
+	timeout, _ := strconv.Atoi(getEnv("PROVIDER_TIMEOUT_SECONDS", "10"))
+	retries, _ := strconv.Atoi(getEnv("PROVIDER_MAX_RETRIES", "2"))
+	backoff, _ := strconv.Atoi(getEnv("PROVIDER_RETRY_BACKOFF_MS", "500"))
+	refresh, _ := strconv.Atoi(getEnv("PROVIDER_REFRESH_SECONDS", "30"))
+	bulkConcurrency, _ := strconv.Atoi(getEnv("BULK_CONCURRENCY", "4"))
+	bulkMax, _ := strconv.Atoi(getEnv("BULK_MAX_INDICATORS", "1000"))
+	urlscanWait, _ := strconv.Atoi(getEnv("URLSCAN_WAIT_SECONDS", "15"))
+	ssllabsMaxAge, _ := strconv.Atoi(getEnv("SSLLABS_MAX_AGE_HOURS", "24"))
+	pendingTimeout, _ := strconv.Atoi(getEnv("PENDING_TIMEOUT_SECONDS", "600"))
+	jobWorkers, _ := strconv.Atoi(getEnv("JOB_WORKERS", "4"))
+	sampleRatio, _ := strconv.ParseFloat(getEnv("TRACING_SAMPLE_RATIO", "1"), 64)
+	mispDistribution, _ := strconv.Atoi(getEnv("MISP_DISTRIBUTION", "0"))
+	clientRate, _ := strconv.Atoi(getEnv("CLIENT_RATE_LIMIT_PER_MIN", "120"))
+	clientDaily, _ := strconv.Atoi(getEnv("CLIENT_DAILY_QUOTA", "20000"))
+	clientMonthly, _ := strconv.Atoi(getEnv("CLIENT_MONTHLY_QUOTA", "400000"))
+
+	defaultPolicy := ProviderPolicy{
+		Timeout:    time.Duration(timeout) * time.Second,
+		MaxRetries: retries,
+		Backoff:    time.Duration(backoff) * time.Millisecond,
+	}
+	policies, err := parseProviderPolicies(getEnv("PROVIDER_POLICIES", ""), defaultPolicy)
+	if err != nil {
+		return nil, err
+	}
+	logLevels, err := parseLogLevels(getEnv("LOG_LEVELS", ""))
+	if err != nil {
+		return nil, err
+	}
+	weights, err := parseProviderWeights(getEnv("PROVIDER_WEIGHTS", "virustotal:1.5,malwarebazaar:1.5,abuseipdb:1.2,phishtank:1.2,hibp:0.5"))
+	if err != nil {
+		return nil, err
+	}
 
 	return &Config{
-		HTTPPort:                  port,
-		PostgresDSN:               getEnv("POSTGRES_DSN", "host=localhost user=hermes password=changeme dbname=hermes sslmode=disable"),
-		LogLevel:                  getEnv("LOG_LEVEL", "info"),
-		CacheTTLSeconds:           cacheTTL,
-		AbuseIPDBAPIKey:           getEnv("ABUSEIPDB_API_KEY", ""),
-		VirusTotalAPIKey:          getEnv("VIRUSTOTAL_API_KEY", ""),
-		PhishTankAppKey:           getEnv("PHISHTANK_APP_KEY", ""),
-		GoogleSafeBrowsingAPIKey:  getEnv("GOOGLE_SAFE_BROWSING_API_KEY", ""),
-		URLScanAPIKey:             getEnv("URLSCAN_API_KEY", ""),
-		HIBPAPIKey:                getEnv("HIBP_API_KEY", ""),
-		NVDAPIKey:                 getEnv("NVD_API_KEY", ""),
-		BinaryEdgeAPIKey:          getEnv("BINARYEDGE_API_KEY", ""),
-		CriminalIPAPIKey:          getEnv("CRIMINALIP_API_KEY", ""),
-		PulsediveAPIKey:           getEnv("PULSEDIVE_API_KEY", ""),
-		EmailRepAPIKey:            getEnv("EMAILREP_API_KEY", ""),
-		VulnersAPIKey:             getEnv("VULNERS_API_KEY", ""),
-		HybridAnalysisAPIKey:      getEnv("HYBRIDANALYSIS_API_KEY", ""),
-		MalshareAPIKey:            getEnv("MALSHARE_API_KEY", ""),
-		MalwareBazaarAPIKey:       getEnv("MALWAREBAZAAR_API_KEY", ""),
+		HTTPPort:                 port,
+		PostgresDSN:              getEnv("POSTGRES_DSN", "host=localhost user=hermes password=changeme dbname=hermes sslmode=disable"),
+		LogLevel:                 getEnv("LOG_LEVEL", "info"),
+		LogLevels:                logLevels,
+		LogBodies:                getEnv("LOG_BODIES", "false") == "true",
+		CacheTTLSeconds:          cacheTTL,
+		LookupDeadlineSeconds:    deadline,
+		DefaultProviderPolicy:    defaultPolicy,
+		ProviderPolicies:         policies,
+		ProviderRefreshSeconds:   refresh,
+		ProviderRateLimitMode:    getEnv("PROVIDER_RATE_LIMIT_MODE", "queue"),
+		AllowPrivateIndicators:   getEnv("ALLOW_PRIVATE_INDICATORS", "false") == "true",
+		BulkConcurrency:          bulkConcurrency,
+		BulkMaxIndicators:        bulkMax,
+		ProviderWeights:          weights,
+		AuthRequired:             getEnv("AUTH_REQUIRED", "true") != "false",
+		AdminAPIKey:              getEnv("ADMIN_API_KEY", ""),
+		URLScanWaitSeconds:       urlscanWait,
+		SSLLabsMaxAgeHours:       ssllabsMaxAge,
+		PendingTimeoutSeconds:    pendingTimeout,
+		JobWorkers:               jobWorkers,
+		TracingExporter:          getEnv("TRACING_EXPORTER", "none"),
+		TracingSampleRatio:       sampleRatio,
+		MISPURL:                  getEnv("MISP_URL", ""),
+		MISPAPIKey:               getEnv("MISP_API_KEY", ""),
+		MISPDistribution:         mispDistribution,
+		MISPPublish:              getEnv("MISP_PUBLISH", "false") == "true",
+		ClientRateLimitPerMin:    clientRate,
+		ClientDailyQuota:         clientDaily,
+		ClientMonthlyQuota:       clientMonthly,
+		AbuseIPDBAPIKey:          getEnv("ABUSEIPDB_API_KEY", ""),
+		VirusTotalAPIKey:         getEnv("VIRUSTOTAL_API_KEY", ""),
+		PhishTankAppKey:          getEnv("PHISHTANK_APP_KEY", ""),
+		GoogleSafeBrowsingAPIKey: getEnv("GOOGLE_SAFE_BROWSING_API_KEY", ""),
+		URLScanAPIKey:            getEnv("URLSCAN_API_KEY", ""),
+		HIBPAPIKey:               getEnv("HIBP_API_KEY", ""),
+		NVDAPIKey:                getEnv("NVD_API_KEY", ""),
+		BinaryEdgeAPIKey:         getEnv("BINARYEDGE_API_KEY", ""),
+		CriminalIPAPIKey:         getEnv("CRIMINALIP_API_KEY", ""),
+		PulsediveAPIKey:          getEnv("PULSEDIVE_API_KEY", ""),
+		EmailRepAPIKey:           getEnv("EMAILREP_API_KEY", ""),
+		VulnersAPIKey:            getEnv("VULNERS_API_KEY", ""),
+		HybridAnalysisAPIKey:     getEnv("HYBRIDANALYSIS_API_KEY", ""),
+		MalshareAPIKey:           getEnv("MALSHARE_API_KEY", ""),
+		MalwareBazaarAPIKey:      getEnv("MALWAREBAZAAR_API_KEY", ""),
+		URLhausAPIKey:            getEnv("URLHAUS_API_KEY", ""),
+		ThreatFoxAPIKey:          getEnv("THREATFOX_API_KEY", ""),
+		FeodoTrackerAPIKey:       getEnv("FEODOTRACKER_API_KEY", ""),
 	}, nil
 }
 
+// parseProviderPolicies parses PROVIDER_POLICIES, e.g.
+// "ssllabs:timeout=90s:retries=0,nvd:timeout=20s:retries=3:backoff=2s".
+// Settings not given for a provider keep the default policy's value.
+func parseProviderPolicies(s string, def ProviderPolicy) (map[string]ProviderPolicy, error) {
+	out := make(map[string]ProviderPolicy)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		code := strings.TrimSpace(parts[0])
+		p := def
+		for _, kv := range parts[1:] {
+			k, v, ok := strings.Cut(kv, "=")
+			if !ok {
+				return nil, fmt.Errorf("PROVIDER_POLICIES: %s: expected key=value, got %q", code, kv)
+			}
+			var err error
+			switch strings.TrimSpace(k) {
+			case "timeout":
+				p.Timeout, err = time.ParseDuration(v)
+			case "retries":
+				p.MaxRetries, err = strconv.Atoi(v)
+			case "backoff":
+				p.Backoff, err = time.ParseDuration(v)
+			default:
+				err = fmt.Errorf("unknown setting %q", k)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("PROVIDER_POLICIES: %s: %w", code, err)
+			}
+		}
+		out[code] = p
+	}
+	return out, nil
+}
+
+// parseProviderWeights parses PROVIDER_WEIGHTS, e.g. "virustotal:1.5,hibp:0.5".
+func parseProviderWeights(s string) (map[string]float64, error) {
+	out := make(map[string]float64)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		code, w, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("PROVIDER_WEIGHTS: expected code:weight, got %q", entry)
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(w), 64)
+		if err != nil {
+			return nil, fmt.Errorf("PROVIDER_WEIGHTS: %s: %w", code, err)
+		}
+		out[strings.TrimSpace(code)] = f
+	}
+	return out, nil
+}
+
+// parseLogLevels parses LOG_LEVELS, e.g. "service=debug,http=warn".
+func parseLogLevels(s string) (map[string]slog.Level, error) {
+	out := make(map[string]slog.Level)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		component, level, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("LOG_LEVELS: expected component=level, got %q", entry)
+		}
+		var l slog.Level
+		if err := l.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+			return nil, fmt.Errorf("LOG_LEVELS: %s: %w", component, err)
+		}
+		out[strings.TrimSpace(component)] = l
+	}
+	return out, nil
+}
+
 func getEnv(key, defaultVal string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
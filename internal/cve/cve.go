@@ -0,0 +1,124 @@
+// Package cve validates CVE IDs and merges the CVE records parsed from the vulnerability
+// providers (NVD, CIRCL, Vulners) into one view.
+package cve
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+var idPattern = regexp.MustCompile(`^CVE-\d{4}-\d{4,}$`)
+
+// Normalize trims and upper-cases a CVE ID ("cve-2024-3094" -> "CVE-2024-3094").
+func Normalize(id string) string {
+	return strings.ToUpper(strings.TrimSpace(id))
+}
+
+// Valid reports whether id is a well-formed, normalized CVE ID (CVE-YYYY-NNNN with 4+ digits).
+func Valid(id string) bool {
+	return idPattern.MatchString(id)
+}
+
+// CVSS is one CVSS score of a CVE.
+type CVSS struct {
+	// Version is "2.0", "3.0", "3.1" or "4.0".
+	Version   string
+	Vector    string
+	BaseScore float64
+	Severity  string
+	// Source is the provider code the score came from.
+	Source string
+}
+
+// Record is a CVE as described by one or more providers.
+type Record struct {
+	ID          string
+	Description string
+	Published   *time.Time
+	Modified    *time.Time
+	CVSS        []CVSS
+	CWEs        []string
+	References  []string
+	// CPEs are the affected platform CPE 2.3 names.
+	CPEs []string
+	// Sources are the provider codes that contributed to the record.
+	Sources []string
+}
+
+// Parser is implemented by adapters whose responses describe a CVE.
+type Parser interface {
+	// ParseCVE extracts the CVE from a raw provider response; ok is false when it describes none.
+	ParseCVE(data map[string]interface{}) (rec Record, ok bool)
+}
+
+// Merge combines records from several providers: the first non-empty description wins, dates
+// widen to the earliest publication and latest modification, CVSS scores are kept once per
+// vector, and lists are de-duplicated and sorted.
+func Merge(records ...Record) Record {
+	var out Record
+	vectors := make(map[string]bool)
+	for _, r := range records {
+		if out.ID == "" {
+			out.ID = r.ID
+		}
+		if out.Description == "" {
+			out.Description = r.Description
+		}
+		if r.Published != nil && (out.Published == nil || r.Published.Before(*out.Published)) {
+			out.Published = r.Published
+		}
+		if r.Modified != nil && (out.Modified == nil || r.Modified.After(*out.Modified)) {
+			out.Modified = r.Modified
+		}
+		for _, s := range r.CVSS {
+			if s.Vector == "" || vectors[s.Vector] {
+				continue
+			}
+			vectors[s.Vector] = true
+			out.CVSS = append(out.CVSS, s)
+		}
+		out.CWEs = append(out.CWEs, r.CWEs...)
+		out.References = append(out.References, r.References...)
+		out.CPEs = append(out.CPEs, r.CPEs...)
+		out.Sources = append(out.Sources, r.Sources...)
+	}
+	// Newest CVSS version first.
+	sort.SliceStable(out.CVSS, func(i, j int) bool { return out.CVSS[i].Version > out.CVSS[j].Version })
+	out.CWEs = unique(out.CWEs)
+	out.References = unique(out.References)
+	out.CPEs = unique(out.CPEs)
+	out.Sources = unique(out.Sources)
+	return out
+}
+
+// VersionFromVector returns the CVSS version encoded in a vector string ("CVSS:3.1/..." -> "3.1");
+// v2 vectors carry no prefix.
+func VersionFromVector(vector string) string {
+	if rest, ok := strings.CutPrefix(vector, "CVSS:"); ok {
+		v, _, _ := strings.Cut(rest, "/")
+		return v
+	}
+	if vector != "" {
+		return "2.0"
+	}
+	return ""
+}
+
+func unique(in []string) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(in))
+	out := make([]string, 0, len(in))
+	for _, s := range in {
+		s = strings.TrimSpace(s)
+		if s != "" && !seen[s] {
+			seen[s] = true
+			out = append(out, s)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
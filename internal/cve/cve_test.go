@@ -0,0 +1,61 @@
+package cve
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValid(t *testing.T) {
+	assert.True(t, Valid("CVE-2024-3094"))
+	assert.True(t, Valid("CVE-2021-1234567"))
+	assert.True(t, Valid(Normalize(" cve-2014-0160 ")))
+	assert.False(t, Valid("CVE-2024-123"))
+	assert.False(t, Valid("CVE-24-3094"))
+	assert.False(t, Valid("cve-2024-3094"))
+	assert.False(t, Valid("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"))
+}
+
+func TestMerge(t *testing.T) {
+	early := time.Date(2024, 3, 29, 0, 0, 0, 0, time.UTC)
+	late := time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC)
+	nvd := Record{
+		ID:          "CVE-2024-3094",
+		Description: "Malicious code was discovered in the upstream tarballs of xz",
+		Published:   &late,
+		Modified:    &late,
+		CVSS:        []CVSS{{Version: "3.1", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", BaseScore: 10, Severity: "CRITICAL", Source: "nvd"}},
+		CWEs:        []string{"CWE-506"},
+		References:  []string{"https://www.openwall.com/lists/oss-security/2024/03/29/4"},
+		CPEs:        []string{"cpe:2.3:a:tukaani:xz:5.6.0:*:*:*:*:*:*:*"},
+		Sources:     []string{"nvd"},
+	}
+	circl := Record{
+		ID:         "CVE-2024-3094",
+		Published:  &early,
+		CVSS:       []CVSS{{Version: "3.1", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", BaseScore: 10, Source: "circl_cve"}},
+		CWEs:       []string{"CWE-506"},
+		References: []string{"https://access.redhat.com/security/cve/CVE-2024-3094", "https://www.openwall.com/lists/oss-security/2024/03/29/4"},
+		CPEs:       []string{"cpe:2.3:a:tukaani:xz:5.6.1:*:*:*:*:*:*:*"},
+		Sources:    []string{"circl_cve"},
+	}
+
+	m := Merge(nvd, circl)
+	assert.Equal(t, "CVE-2024-3094", m.ID)
+	assert.Equal(t, nvd.Description, m.Description)
+	assert.Equal(t, early, *m.Published)
+	assert.Equal(t, late, *m.Modified)
+	assert.Len(t, m.CVSS, 1)
+	assert.Equal(t, "nvd", m.CVSS[0].Source)
+	assert.Equal(t, []string{"CWE-506"}, m.CWEs)
+	assert.Len(t, m.References, 2)
+	assert.Len(t, m.CPEs, 2)
+	assert.Equal(t, []string{"circl_cve", "nvd"}, m.Sources)
+}
+
+func TestVersionFromVector(t *testing.T) {
+	assert.Equal(t, "4.0", VersionFromVector("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"))
+	assert.Equal(t, "2.0", VersionFromVector("AV:N/AC:L/Au:N/C:P/I:P/A:P"))
+	assert.Equal(t, "", VersionFromVector(""))
+}
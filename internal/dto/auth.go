@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// CreateClientDTO is the request body for creating an API client.
+type CreateClientDTO struct {
+	Name string `json:"name" binding:"required,max=128" example:"soc-automation"`
+	ClientLimitsDTO
+}
+
+// ClientLimitsDTO overrides a client's limits; omitted fields use the configured defaults and
+// 0 means unlimited. Quotas count provider calls.
+type ClientLimitsDTO struct {
+	RateLimitPerMin *int `json:"rate_limit_per_min,omitempty" binding:"omitempty,min=0" example:"120"`
+	DailyQuota      *int `json:"daily_quota,omitempty" binding:"omitempty,min=0" example:"20000"`
+	MonthlyQuota    *int `json:"monthly_quota,omitempty" binding:"omitempty,min=0" example:"400000"`
+}
+
+// CreateAPIKeyDTO is the request body for issuing an API key.
+type CreateAPIKeyDTO struct {
+	// Scopes: lookup:read, taxii:read, misp:push, blocklist:read, admin, provider:* or provider:<code>
+	Scopes    []string   `json:"scopes" binding:"required,min=1" example:"lookup:read,provider:abuseipdb"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// RotateAPIKeyDTO is the optional request body for rotating an API key.
+type RotateAPIKeyDTO struct {
+	// GraceSeconds keeps the old key working this long (default 0, at most 7 days).
+	GraceSeconds int `json:"grace_seconds" binding:"min=0,max=604800" example:"3600"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+// BlocklistDTO is the request body for creating or replacing a blocklist.
+type BlocklistDTO struct {
+	// Name is part of the list's URL: lowercase letters, digits, '-', '_' and '.'. It is taken
+	// from the path when replacing a list.
+	Name          string `json:"name" binding:"omitempty,max=64" example:"malicious-ips"`
+	Description   string `json:"description" example:"IPs at least two providers call malicious, last 30 days"`
+	IndicatorType string `json:"indicator_type" binding:"required,oneof=ip domain url hash email" example:"ip"`
+	// Format: plain (ip, domain, url, hash, email), rpz (domain), squid (domain, url) or
+	// suricata (ip, domain, hash).
+	Format string `json:"format" binding:"required,oneof=plain rpz squid suricata" example:"plain"`
+	// Verdicts the aggregate of the providers' latest results must be one of (default
+	// malicious).
+	Verdicts []string `json:"verdicts" binding:"omitempty,dive,oneof=malicious suspicious" example:"malicious"`
+	MinScore int      `json:"min_score" binding:"min=0,max=100" example:"70"`
+	// MaxAgeHours leaves out provider results cached longer ago (0 = any age).
+	MaxAgeHours int `json:"max_age_hours" binding:"min=0" example:"720"`
+	// MinProviders is how many providers must have a verdict among Verdicts.
+	MinProviders int `json:"min_providers" binding:"min=0" example:"2"`
+}
+
+// AllowlistEntryDTO is the request body for allowlisting an indicator.
+type AllowlistEntryDTO struct {
+	IndicatorType string `json:"indicator_type" binding:"required,oneof=ip domain url hash email" example:"ip"`
+	// Value is canonicalized; IPs may be CIDR blocks and domains cover their subdomains.
+	Value   string `json:"value" binding:"required,max=2048" example:"192.0.2.0/24"`
+	Comment string `json:"comment" example:"Office egress"`
+}
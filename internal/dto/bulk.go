@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"bufio"
+	"encoding/json"
+	"strings"
+)
+
+// BulkIndicatorDTO is one indicator of a bulk lookup. In a JSON array it is either an object or
+// a bare string; IndicatorType may be empty, in which case it is detected from the value.
+type BulkIndicatorDTO struct {
+	IndicatorType  string `json:"indicator_type,omitempty" example:"ip"`
+	IndicatorValue string `json:"indicator_value" example:"8.8.8.8"`
+}
+
+// UnmarshalJSON accepts {"indicator_type": ..., "indicator_value": ...} or "value".
+func (d *BulkIndicatorDTO) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*d = BulkIndicatorDTO{IndicatorValue: s}
+		return nil
+	}
+	type plain BulkIndicatorDTO
+	return json.Unmarshal(b, (*plain)(d))
+}
+
+// ParseBulkText parses newline-separated indicators. Each line is "value" or "type value"
+// (e.g. "ip 8.8.8.8"); blank lines and lines starting with # are skipped.
+func ParseBulkText(text string) []BulkIndicatorDTO {
+	var out []BulkIndicatorDTO
+	sc := bufio.NewScanner(strings.NewReader(text))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		d := BulkIndicatorDTO{IndicatorValue: line}
+		if f := strings.Fields(line); len(f) > 1 && bulkTypes[strings.ToLower(f[0])] {
+			d = BulkIndicatorDTO{IndicatorType: strings.ToLower(f[0]), IndicatorValue: strings.TrimSpace(line[len(f[0]):])}
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+var bulkTypes = map[string]bool{"ip": true, "domain": true, "url": true, "hash": true, "email": true, "cve": true}
@@ -0,0 +1,18 @@
+package dto
+
+import "time"
+
+// LookupHistoryQueryDTO is the query string of GET /lookups.
+type LookupHistoryQueryDTO struct {
+	IndicatorType string `form:"indicator_type" binding:"omitempty,oneof=ip domain url hash email cve"`
+	// IndicatorValue matches the canonical value (canonicalized with IndicatorType when given).
+	IndicatorValue string     `form:"indicator_value"`
+	Provider       string     `form:"provider"`
+	Verdict        string     `form:"verdict" binding:"omitempty,oneof=malicious suspicious clean unknown"`
+	Since          *time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"`
+	Until          *time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"`
+	// Sort is created_at or score, prefixed with - for descending (default -created_at).
+	Sort   string `form:"sort" binding:"omitempty,oneof=created_at -created_at score -score"`
+	Cursor string `form:"cursor"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`
+}
@@ -1,12 +1,24 @@
 package dto
 
+// Cache modes for LookupRequestDTO.Cache.
+const (
+	// CachePrefer serves unexpired cached results and calls providers only on a miss (default).
+	CachePrefer = "prefer"
+	// CacheBypass always calls providers; results are still written to the cache.
+	CacheBypass = "bypass"
+	// CacheOnly never calls providers; providers without a cached result are reported as misses.
+	CacheOnly = "only"
+)
+
 // LookupRequestDTO is the request body for unified lookup.
 // @description Request body for unified lookup
 type LookupRequestDTO struct {
-	// IndicatorType is one of: ip, domain, url, hash, email
-	IndicatorType string `json:"indicator_type" binding:"required,oneof=ip domain url hash email" example:"ip"`
-	// IndicatorValue is the value to look up (e.g. IP, domain, URL, hash, email)
+	// IndicatorType is one of: ip, domain, url, hash, email, cve
+	IndicatorType string `json:"indicator_type" binding:"required,oneof=ip domain url hash email cve" example:"ip"`
+	// IndicatorValue is the value to look up (e.g. IP, domain, URL, hash, email, CVE ID)
 	IndicatorValue string `json:"indicator_value" binding:"required" example:"8.8.8.8"`
 	// Providers optionally limits which providers to query (empty = all enabled)
 	Providers []string `json:"providers,omitempty"`
+	// Cache is one of: prefer (default), bypass, only
+	Cache string `json:"cache,omitempty" binding:"omitempty,oneof=bypass prefer only" example:"prefer"`
 }
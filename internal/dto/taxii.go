@@ -0,0 +1,30 @@
+package dto
+
+import "time"
+
+// TAXIIQueryDTO holds the filters and paging of the TAXII object and manifest endpoints.
+type TAXIIQueryDTO struct {
+	AddedAfter *time.Time `form:"added_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	Limit      int        `form:"limit" binding:"omitempty,min=1"`
+	Next       string     `form:"next"`
+	// Types and IDs are comma-separated STIX object types and IDs.
+	Types string `form:"match[type]"`
+	IDs   string `form:"match[id]"`
+	// Version and SpecVersion are accepted for compatibility: every object has one version, in
+	// STIX 2.1.
+	Version     string `form:"match[version]"`
+	SpecVersion string `form:"match[spec_version]"`
+}
+
+// CreateTAXIICollectionDTO is the request body for creating a curated TAXII collection.
+type CreateTAXIICollectionDTO struct {
+	Title       string `json:"title" binding:"required,max=256" example:"Phishing infrastructure"`
+	Description string `json:"description" example:"Confirmed phishing domains and URLs"`
+	// Alias can stand in for the collection ID in TAXII URLs.
+	Alias string `json:"alias" binding:"omitempty,max=64" example:"phishing"`
+}
+
+// TAXIILookupDTO is the request body for adding a lookup to a curated TAXII collection.
+type TAXIILookupDTO struct {
+	RequestID string `json:"request_id" binding:"required,uuid" example:"550e8400-e29b-41d4-a716-446655440000"`
+}
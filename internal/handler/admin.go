@@ -0,0 +1,209 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hermes/internal/dto"
+	"hermes/internal/service"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AdminHandler manages API clients and keys. All routes require the admin scope.
+type AdminHandler struct {
+	authSvc *service.AuthService
+}
+
+// NewAdminHandler creates an admin handler.
+func NewAdminHandler(authSvc *service.AuthService) *AdminHandler {
+	return &AdminHandler{authSvc: authSvc}
+}
+
+// CreateClient handles POST /admin/clients.
+// @Summary      Create API client
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        body  body  dto.CreateClientDTO  true  "Client"
+// @Success      201  {object}  vo.ClientVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      409  {object}  vo.ErrorVO  "Name taken or reserved"
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/clients [post]
+func (h *AdminHandler) CreateClient(c *gin.Context) {
+	var req dto.CreateClientDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	res, err := h.authSvc.CreateClient(&req)
+	if errors.Is(err, service.ErrClientExists) {
+		c.JSON(http.StatusConflict, vo.ErrorVO{Code: "CONFLICT", Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// ListClients handles GET /admin/clients.
+// @Summary      List API clients
+// @Description  Clients with their keys; key secrets are never returned.
+// @Tags         admin
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   vo.ClientVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/clients [get]
+func (h *AdminHandler) ListClients(c *gin.Context) {
+	res, err := h.authSvc.ListClients()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// SetClientLimits handles PUT /admin/clients/:id/limits.
+// @Summary      Set client limits
+// @Description  Replace a client's rate limit and provider-call quotas; omitted fields use the configured defaults, 0 means unlimited.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id    path  int                  true  "Client ID"
+// @Param        body  body  dto.ClientLimitsDTO  true  "Limits"
+// @Success      200  {object}  vo.ClientVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/clients/{id}/limits [put]
+func (h *AdminHandler) SetClientLimits(c *gin.Context) {
+	clientID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "id must be an integer"})
+		return
+	}
+	var req dto.ClientLimitsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	res, err := h.authSvc.SetClientLimits(clientID, &req)
+	if err != nil {
+		writeKeyError(c, err, "client not found")
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// CreateKey handles POST /admin/clients/:id/keys.
+// @Summary      Issue API key
+// @Description  Issue a key for a client. The key is only returned in this response.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id    path  int                  true  "Client ID"
+// @Param        body  body  dto.CreateAPIKeyDTO  true  "Scopes and optional expiry"
+// @Success      201  {object}  vo.CreatedAPIKeyVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/clients/{id}/keys [post]
+func (h *AdminHandler) CreateKey(c *gin.Context) {
+	clientID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "id must be an integer"})
+		return
+	}
+	var req dto.CreateAPIKeyDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	res, err := h.authSvc.CreateKey(clientID, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		writeKeyError(c, err, "client not found")
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// RotateKey handles POST /admin/keys/:key_id/rotate.
+// @Summary      Rotate API key
+// @Description  Issue a replacement key with the same scopes. The old key keeps working for grace_seconds.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        key_id  path  string               true   "Key ID"
+// @Param        body    body  dto.RotateAPIKeyDTO  false  "Grace period"
+// @Success      201  {object}  vo.CreatedAPIKeyVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/keys/{key_id}/rotate [post]
+func (h *AdminHandler) RotateKey(c *gin.Context) {
+	var req dto.RotateAPIKeyDTO
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+			return
+		}
+	}
+	res, err := h.authSvc.RotateKey(c.Param("key_id"), time.Duration(req.GraceSeconds)*time.Second)
+	if err != nil {
+		writeKeyError(c, err, "active key not found")
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// RevokeKey handles DELETE /admin/keys/:key_id.
+// @Summary      Revoke API key
+// @Tags         admin
+// @Security     ApiKeyAuth
+// @Param        key_id  path  string  true  "Key ID"
+// @Success      204
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/keys/{key_id} [delete]
+func (h *AdminHandler) RevokeKey(c *gin.Context) {
+	if err := h.authSvc.RevokeKey(c.Param("key_id")); err != nil {
+		writeKeyError(c, err, "key not found")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeKeyError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidScope):
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: notFound})
+	default:
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+	}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hermes/internal/config"
+	"hermes/internal/model"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupAuthRouter mounts RegisterRoutes with authentication required and the bootstrap admin key
+// "bootstrap-secret"; the returned func sends a request with an optional API key.
+func setupAuthRouter(t *testing.T, cfg *config.Config) func(method, path, key, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&model.LookupRequest{}, &model.LookupResult{}, &model.AuditLog{}, &model.Provider{},
+		&model.BulkJob{}, &model.BulkJobItem{}, &model.Client{}, &model.APIKey{}, &model.ClientUsage{}, &model.ProviderJob{}, &model.LookupObject{}))
+	cfg.CacheTTLSeconds, cfg.AuthRequired, cfg.AdminAPIKey = 3600, true, "bootstrap-secret"
+	r := gin.New()
+	RegisterRoutes(r.Group("/api/v1"), r.Group("/taxii2"), cfg, db)
+
+	return func(method, path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+}
+
+func TestAdminHandler_KeyLifecycle(t *testing.T) {
+	do := setupAuthRouter(t, &config.Config{})
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/ping", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/lookups", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/lookups", "hk_nope", "").Code)
+
+	w := do(http.MethodPost, "/api/v1/admin/clients", "bootstrap-secret", `{"name":"soc"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var client vo.ClientVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &client))
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/api/v1/admin/clients", "bootstrap-secret", `{"name":"soc"}`).Code)
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/api/v1/admin/clients", "bootstrap-secret", `{"name":"bootstrap"}`).Code)
+
+	w = do(http.MethodPost, "/api/v1/admin/clients/1/keys", "bootstrap-secret", `{"scopes":["lookup:read","provider:nope"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do(http.MethodPost, "/api/v1/admin/clients/1/keys", "bootstrap-secret", `{"scopes":["lookup:read","provider:virustotal"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var key vo.CreatedAPIKeyVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &key))
+	assert.Equal(t, client.ID, key.ClientID)
+
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/lookups", key.Key, "").Code)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/v1/admin/clients", key.Key, "").Code)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/v1/providers/abuseipdb/ip/8.8.8.8", key.Key, "").Code)
+
+	w = do(http.MethodPost, "/api/v1/admin/keys/"+key.KeyID+"/rotate", "bootstrap-secret", "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var rotated vo.CreatedAPIKeyVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &rotated))
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/lookups", key.Key, "").Code)
+	assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/lookups", rotated.Key, "").Code)
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/v1/admin/keys/"+rotated.KeyID, "bootstrap-secret", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/api/v1/lookups", rotated.Key, "").Code)
+}
+
+func TestUsage_RateLimitAndQuotaHeaders(t *testing.T) {
+	do := setupAuthRouter(t, &config.Config{ClientRateLimitPerMin: 60, ClientDailyQuota: 100})
+	assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/api/v1/admin/clients", "bootstrap-secret", `{"name":"soc","rate_limit_per_min":1}`).Code)
+	w := do(http.MethodPost, "/api/v1/admin/clients/1/keys", "bootstrap-secret", `{"scopes":["lookup:read"]}`)
+	var key vo.CreatedAPIKeyVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &key))
+
+	w = do(http.MethodGet, "/api/v1/lookups", key.Key, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "100", w.Header().Get("X-RateLimit-Limit-Day"))
+
+	w = do(http.MethodGet, "/api/v1/lookups", key.Key, "")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// /usage is not metered, so it stays readable when the rate is used up.
+	w = do(http.MethodGet, "/api/v1/usage", key.Key, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var u vo.UsageVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &u))
+	assert.Equal(t, "soc", u.Client)
+	assert.Equal(t, 1, u.RateLimitPerMin)
+	assert.Equal(t, int64(1), u.Day.Requests)
+	assert.Equal(t, http.StatusForbidden, do(http.MethodGet, "/api/v1/usage?client=other", key.Key, "").Code)
+}
@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"hermes/internal/dto"
+	"hermes/internal/indicator"
+	"hermes/internal/service"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// BlocklistHandler serves blocklists under /blocklists and manages them and the allowlist under
+// /admin.
+type BlocklistHandler struct {
+	blocklistSvc *service.BlocklistService
+}
+
+// NewBlocklistHandler creates a blocklist handler.
+func NewBlocklistHandler(blocklistSvc *service.BlocklistService) *BlocklistHandler {
+	return &BlocklistHandler{blocklistSvc: blocklistSvc}
+}
+
+// Get handles GET /blocklists/:name.
+// @Summary      Get blocklist
+// @Description  Renders a blocklist from the latest stored result of each provider for each indicator, minus allowlisted ones, in the list's format: plain (one entry per line, IPs collapsed into CIDR blocks), rpz (a DNS Response Policy Zone answering NXDOMAIN for each domain and its subdomains, passing allowlisted subdomains through), squid (a dstdomain or url_regex ACL file; it cannot exempt allowlisted subdomains of listed domains and names them in a comment) or suricata (drop rules, with pass rules for allowlisted subdomains of listed domains; hash lists get one rule per algorithm loading <name>.<algo>, served with hash_type). Lists are refreshed every minute and when their definition or the allowlist changes; new verdicts show after the next refresh. Responses carry ETag and Last-Modified; If-None-Match and If-Modified-Since get 304 while the list is unchanged. Requires scope blocklist:read.
+// @Tags         blocklist
+// @Produce      plain
+// @Security     ApiKeyAuth
+// @Param        name       path   string  true   "Blocklist name"
+// @Param        hash_type  query  string  false  "Hash lists only: the plain list of hashes of this algorithm"  Enums(md5, sha1, sha256)
+// @Success      200  {string}  string  "Rendered list"
+// @Success      304  "Not modified"
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /blocklists/{name} [get]
+func (h *BlocklistHandler) Get(c *gin.Context) {
+	name, hashType := c.Param("name"), c.Query("hash_type")
+	v, err := h.blocklistSvc.Version(name, hashType)
+	if err != nil {
+		writeBlocklistError(c, err, "blocklist not found")
+		return
+	}
+	c.Header("Cache-Control", "no-cache")
+	if notModified(c.Request, v.ETag, v.ModifiedAt) {
+		setBlocklistVersion(c, v)
+		c.Status(http.StatusNotModified)
+		return
+	}
+	res, err := h.blocklistSvc.Render(name, hashType)
+	if err != nil {
+		writeBlocklistError(c, err, "blocklist not found")
+		return
+	}
+	setBlocklistVersion(c, &res.Version)
+	c.Data(http.StatusOK, res.ContentType, res.Body)
+}
+
+func setBlocklistVersion(c *gin.Context, v *service.Version) {
+	c.Header("ETag", v.ETag)
+	c.Header("Last-Modified", v.ModifiedAt.UTC().Format(http.TimeFormat))
+}
+
+// notModified evaluates If-None-Match, or without it If-Modified-Since (RFC 9110 13.2.2).
+func notModified(r *http.Request, etag string, modified time.Time) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		for _, t := range strings.Split(inm, ",") {
+			if t = strings.TrimPrefix(strings.TrimSpace(t), "W/"); t == etag || t == "*" {
+				return true
+			}
+		}
+		return false
+	}
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	return err == nil && !modified.Truncate(time.Second).After(since)
+}
+
+// List handles GET /admin/blocklists.
+// @Summary      List blocklists
+// @Tags         blocklist
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   vo.BlocklistVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/blocklists [get]
+func (h *BlocklistHandler) List(c *gin.Context) {
+	res, err := h.blocklistSvc.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Create handles POST /admin/blocklists.
+// @Summary      Create blocklist
+// @Description  Defines a blocklist served at /blocklists/{name}: indicators of one type whose providers' latest results, at most max_age_hours old, aggregate to one of the verdicts with at least min_score and have min_providers providers agreeing. Lookups limited to some providers or to the cache leave the other results in place.
+// @Tags         blocklist
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        body  body  dto.BlocklistDTO  true  "Blocklist"
+// @Success      201  {object}  vo.BlocklistVO
+// @Failure      400  {object}  vo.ErrorVO  "Invalid body, name taken, or a format the indicator type cannot be rendered in"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/blocklists [post]
+func (h *BlocklistHandler) Create(c *gin.Context) {
+	var req dto.BlocklistDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	res, err := h.blocklistSvc.Create(&req)
+	if err != nil {
+		writeBlocklistError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// Update handles PUT /admin/blocklists/:name.
+// @Summary      Replace blocklist
+// @Description  Replaces a blocklist's definition; the name in the body is ignored.
+// @Tags         blocklist
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        name  path  string            true  "Blocklist name"
+// @Param        body  body  dto.BlocklistDTO  true  "Blocklist"
+// @Success      200  {object}  vo.BlocklistVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/blocklists/{name} [put]
+func (h *BlocklistHandler) Update(c *gin.Context) {
+	var req dto.BlocklistDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	res, err := h.blocklistSvc.Update(c.Param("name"), &req)
+	if err != nil {
+		writeBlocklistError(c, err, "blocklist not found")
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Delete handles DELETE /admin/blocklists/:name.
+// @Summary      Delete blocklist
+// @Tags         blocklist
+// @Security     ApiKeyAuth
+// @Param        name  path  string  true  "Blocklist name"
+// @Success      204
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/blocklists/{name} [delete]
+func (h *BlocklistHandler) Delete(c *gin.Context) {
+	if err := h.blocklistSvc.Delete(c.Param("name")); err != nil {
+		writeBlocklistError(c, err, "blocklist not found")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// ListAllowlist handles GET /admin/allowlist.
+// @Summary      List allowlist
+// @Tags         blocklist
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   vo.AllowlistEntryVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/allowlist [get]
+func (h *BlocklistHandler) ListAllowlist(c *gin.Context) {
+	res, err := h.blocklistSvc.AllowlistEntries()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// AddAllowlistEntry handles POST /admin/allowlist.
+// @Summary      Allowlist indicator
+// @Description  Keeps an indicator off every blocklist. IP entries may be CIDR blocks; domain entries also cover subdomains and URLs on them.
+// @Tags         blocklist
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        body  body  dto.AllowlistEntryDTO  true  "Entry"
+// @Success      201  {object}  vo.AllowlistEntryVO
+// @Failure      400  {object}  vo.ErrorVO  "Invalid body or indicator, or already allowlisted"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/allowlist [post]
+func (h *BlocklistHandler) AddAllowlistEntry(c *gin.Context) {
+	var req dto.AllowlistEntryDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	res, err := h.blocklistSvc.AddAllowlistEntry(&req)
+	var invalid *indicator.Error
+	if errors.As(err, &invalid) {
+		c.JSON(http.StatusBadRequest, invalidIndicatorVO(invalid, "indicator_type", "value"))
+		return
+	}
+	if err != nil {
+		writeBlocklistError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// DeleteAllowlistEntry handles DELETE /admin/allowlist/:id.
+// @Summary      Remove allowlist entry
+// @Tags         blocklist
+// @Security     ApiKeyAuth
+// @Param        id  path  int  true  "Entry ID"
+// @Success      204
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/allowlist/{id} [delete]
+func (h *BlocklistHandler) DeleteAllowlistEntry(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "id must be an integer"})
+		return
+	}
+	if err := h.blocklistSvc.DeleteAllowlistEntry(id); err != nil {
+		writeBlocklistError(c, err, "allowlist entry not found")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeBlocklistError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBlocklist):
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: notFound})
+	default:
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+	}
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"hermes/internal/config"
+	"hermes/internal/model"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestBlocklist(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&model.LookupRequest{}, &model.LookupResult{}, &model.Blocklist{}, &model.AllowlistEntry{}))
+
+	// 203.0.113.7 and .8 are malicious to two providers; 198.51.100.1 to one; 203.0.113.9 was
+	// malicious but both providers answer clean now. The last lookup of 203.0.113.7 was served
+	// from an empty cache and stored no results.
+	for i, l := range []struct {
+		value, verdict string
+		providers      int
+	}{
+		{"203.0.113.7", "malicious", 2},
+		{"203.0.113.8", "malicious", 2},
+		{"198.51.100.1", "malicious", 1},
+		{"203.0.113.9", "malicious", 2},
+		{"203.0.113.9", "clean", 2},
+		{"203.0.113.7", "unknown", 0},
+	} {
+		score := 90
+		if l.verdict != "malicious" {
+			score = 0
+		}
+		req := &model.LookupRequest{RequestID: uuid.New(), IndicatorType: "ip", IndicatorValue: l.value,
+			IndicatorHash: l.value, Verdict: l.verdict, Score: score, CreatedAt: time.Now().Add(time.Duration(i-10) * time.Minute)}
+		assert.NoError(t, db.Create(req).Error)
+		for _, code := range []string{"virustotal", "abuseipdb"}[:l.providers] {
+			assert.NoError(t, db.Create(&model.LookupResult{LookupRequestID: req.ID, ProviderCode: code,
+				RawResponse: model.JSONB{}, Verdict: l.verdict, Score: score}).Error)
+		}
+	}
+
+	r := gin.New()
+	workers := RegisterRoutes(r.Group("/api/v1"), r.Group("/taxii2"), &config.Config{CacheTTLSeconds: 3600}, db)
+	do := func(method, path, body string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v1/admin/blocklists", `{"name":"bad-ips","indicator_type":"ip","format":"rpz"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = do(http.MethodPost, "/api/v1/admin/blocklists", `{"name":"bad-ips","indicator_type":"ip","format":"plain","min_score":70,"min_providers":2,"max_age_hours":24}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	w = do(http.MethodGet, "/api/v1/blocklists/bad-ips", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "203.0.113.7\n203.0.113.8\n", w.Body.String())
+	etag, modified := w.Header().Get("ETag"), w.Header().Get("Last-Modified")
+	assert.NotEmpty(t, etag)
+
+	assert.Equal(t, http.StatusNotModified, do(http.MethodGet, "/api/v1/blocklists/bad-ips", "", "If-None-Match", etag).Code)
+	assert.Equal(t, http.StatusNotModified, do(http.MethodGet, "/api/v1/blocklists/bad-ips", "", "If-Modified-Since", modified).Code)
+
+	w = do(http.MethodPost, "/api/v1/admin/allowlist", `{"indicator_type":"ip","value":"203.0.113.8/31"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"value":"203.0.113.8/31"`)
+
+	w = do(http.MethodGet, "/api/v1/blocklists/bad-ips", "", "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "203.0.113.7\n", w.Body.String())
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	// A new verdict is listed once the refresh records the change; until then polls are
+	// answered from the stored validators and rendering, and nothing is written.
+	etag = w.Header().Get("ETag")
+	var before, after model.Blocklist
+	assert.NoError(t, db.First(&before, "name = ?", "bad-ips").Error)
+	req := &model.LookupRequest{RequestID: uuid.New(), IndicatorType: "ip", IndicatorValue: "203.0.113.20",
+		IndicatorHash: "203.0.113.20", Verdict: "malicious", Score: 90, CreatedAt: time.Now()}
+	assert.NoError(t, db.Create(req).Error)
+	for _, code := range []string{"virustotal", "abuseipdb"} {
+		assert.NoError(t, db.Create(&model.LookupResult{LookupRequestID: req.ID, ProviderCode: code,
+			RawResponse: model.JSONB{}, Verdict: "malicious", Score: 90}).Error)
+	}
+	assert.Equal(t, http.StatusNotModified, do(http.MethodGet, "/api/v1/blocklists/bad-ips", "", "If-None-Match", etag).Code)
+	w = do(http.MethodGet, "/api/v1/blocklists/bad-ips", "")
+	assert.Equal(t, "203.0.113.7\n", w.Body.String())
+	assert.Equal(t, etag, w.Header().Get("ETag"))
+	assert.NoError(t, db.First(&after, "name = ?", "bad-ips").Error)
+	assert.Equal(t, before.ContentHash, after.ContentHash)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	workers.blocklist.Run(ctx)
+	assert.NoError(t, db.First(&after, "name = ?", "bad-ips").Error)
+	assert.NotEqual(t, before.ContentHash, after.ContentHash)
+	w = do(http.MethodGet, "/api/v1/blocklists/bad-ips", "", "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "203.0.113.7\n203.0.113.20\n", w.Body.String())
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, "/api/v1/blocklists/bad-ips?hash_type=md5", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/blocklists/nope", "").Code)
+}
@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"hermes/internal/config"
+	"hermes/internal/dto"
+	"hermes/internal/service"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultBulkPageSize = 100
+	maxBulkPageSize     = 1000
+	// maxBulkBodyBytes bounds a bulk request body.
+	maxBulkBodyBytes = 4 << 20
+)
+
+// BulkHandler handles bulk lookup jobs.
+type BulkHandler struct {
+	bulkSvc *service.BulkService
+}
+
+// NewBulkHandler creates a bulk handler that runs lookups through lookupSvc (sharing its
+// registry and provider rate limits) and charges provider calls to the submitting client through
+// usageSvc (nil = not metered). Jobs are processed while the service's Run is active.
+func NewBulkHandler(cfg *config.Config, db *gorm.DB, lookupSvc *service.LookupService, usageSvc *service.UsageService) *BulkHandler {
+	return &BulkHandler{bulkSvc: service.NewBulkService(cfg, lookupSvc, usageSvc, db)}
+}
+
+// Submit handles POST /lookups/bulk.
+// @Summary      Submit bulk lookup
+// @Description  Queue a bulk lookup. The body is a JSON array of indicators (objects with indicator_type/indicator_value, or bare strings whose type is detected) or, with Content-Type text/plain, one indicator per line ("value" or "type value"). Identical indicators are looked up once.
+// @Tags         lookup
+// @Accept       json
+// @Accept       plain
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        body       body   []dto.BulkIndicatorDTO  true   "Indicators"
+// @Param        providers  query  string                  false  "Comma-separated provider codes (default: all enabled)"
+// @Param        cache      query  string                  false  "Cache mode: prefer (default), bypass, only"
+// @Success      202  {object}  vo.BulkJobVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ErrorVO  "Client rate limit or quota exceeded (see Retry-After)"
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /lookups/bulk [post]
+func (h *BulkHandler) Submit(c *gin.Context) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxBulkBodyBytes+1))
+	if err != nil || len(body) > maxBulkBodyBytes {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "body missing or larger than 4 MiB"})
+		return
+	}
+	var indicators []dto.BulkIndicatorDTO
+	if strings.HasPrefix(c.ContentType(), "text/") {
+		indicators = dto.ParseBulkText(string(body))
+	} else if err := json.Unmarshal(body, &indicators); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "expected a JSON array of indicators: " + err.Error()})
+		return
+	}
+
+	cache := c.DefaultQuery("cache", dto.CachePrefer)
+	if cache != dto.CachePrefer && cache != dto.CacheBypass && cache != dto.CacheOnly {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "cache must be one of: prefer, bypass, only"})
+		return
+	}
+	var providers []string
+	for _, p := range strings.Split(c.Query("providers"), ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			providers = append(providers, p)
+		}
+	}
+
+	job, err := h.bulkSvc.Submit(c.Request.Context(), indicators, providers, cache)
+	if errors.Is(err, service.ErrBulkInput) {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// Get handles GET /lookups/bulk/:id.
+// @Summary      Bulk lookup progress
+// @Description  Progress counters and a page of results (in input order) of a bulk lookup job. With format=stix the lookups of all finished items are returned as one STIX 2.1 bundle (see GET /lookups/{request_id}); page and page_size are ignored. With format=misp they are returned as one MISP event. With format=csv or xlsx all items are downloaded as a spreadsheet, one row per indicator in input order (see GET /lookups); items without a lookup show their status and error.
+// @Tags         lookup
+// @Produce      json
+// @Produce      application/stix+json
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security     ApiKeyAuth
+// @Param        id         path   string  true   "Job ID"
+// @Param        page       query  int     false  "Page (from 1)"  default(1)
+// @Param        page_size  query  int     false  "Items per page (max 1000)"  default(100)
+// @Param        format     query  string  false  "Response format"  Enums(json, stix, misp, csv, xlsx)  default(json)
+// @Success      200  {object}  vo.BulkJobVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ErrorVO  "Client rate limit or quota exceeded (see Retry-After)"
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /lookups/bulk/{id} [get]
+func (h *BulkHandler) Get(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "id must be a UUID"})
+		return
+	}
+	page, err1 := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, err2 := strconv.Atoi(c.DefaultQuery("page_size", strconv.Itoa(defaultBulkPageSize)))
+	if err1 != nil || err2 != nil || page < 1 || pageSize < 1 || pageSize > maxBulkPageSize {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "page must be >= 1 and page_size between 1 and 1000"})
+		return
+	}
+	format, ok := exportFormat(c, formatJSON, formatSTIX, formatMISP, formatCSV, formatXLSX)
+	if !ok {
+		return
+	}
+	var res interface{}
+	switch format {
+	case formatCSV, formatXLSX:
+		err = streamReport(c, format, "bulk-"+jobID.String(), func(w io.Writer) error {
+			return h.bulkSvc.Report(c.Request.Context(), jobID, w, format)
+		})
+	case formatSTIX:
+		res, err = h.bulkSvc.STIX(c.Request.Context(), jobID)
+	case formatMISP:
+		res, err = h.bulkSvc.MISP(c.Request.Context(), jobID)
+	default:
+		res, err = h.bulkSvc.Get(c.Request.Context(), jobID, page, pageSize)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: "bulk job not found"})
+		return
+	}
+	if errors.Is(err, service.ErrExportTooLarge) {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "EXPORT_TOO_LARGE", Message: err.Error() + "; use format=csv or xlsx"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	if res != nil {
+		writeExport(c, format, res)
+	}
+}
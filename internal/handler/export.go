@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"hermes/internal/logging"
+	"hermes/internal/report"
+	"hermes/internal/stix"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export formats of GET /lookups, GET /lookups/:request_id and GET /lookups/bulk/:id (query
+// parameter format).
+const (
+	formatJSON = "json"
+	formatSTIX = "stix"
+	formatMISP = "misp"
+	formatCSV  = report.FormatCSV
+	formatXLSX = report.FormatXLSX
+)
+
+var exportLog = logging.For("http")
+
+// exportFormat returns the requested format, answering 400 when it is not one of formats.
+func exportFormat(c *gin.Context, formats ...string) (string, bool) {
+	f := c.DefaultQuery("format", formatJSON)
+	for _, v := range formats {
+		if f == v {
+			return f, true
+		}
+	}
+	last := len(formats) - 1
+	msg := "format must be " + strings.Join(formats[:last], ", ") + " or " + formats[last]
+	c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: msg})
+	return "", false
+}
+
+// writeExport answers 200 with res, a value of the format's type.
+func writeExport(c *gin.Context, format string, res interface{}) {
+	if format == formatSTIX {
+		c.Header("Content-Type", stix.MediaType)
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// streamReport runs write with a writer that starts a download of a report in format, named
+// name, on the first write. It returns write's error only when nothing was sent yet, for the
+// caller to answer; later errors cut the download short.
+func streamReport(c *gin.Context, format, name string, write func(io.Writer) error) error {
+	w := &reportWriter{c: c, format: format, name: name + "." + format}
+	err := write(w)
+	if err != nil && w.started {
+		exportLog.ErrorContext(c.Request.Context(), "report export aborted", "file", w.name, "error", err)
+		c.Abort()
+		return nil
+	}
+	return err
+}
+
+type reportWriter struct {
+	c       *gin.Context
+	format  string
+	name    string
+	started bool
+}
+
+func (w *reportWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Content-Type", report.ContentType(w.format))
+		w.c.Header("Content-Disposition", `attachment; filename="`+w.name+`"`)
+		w.c.Status(http.StatusOK)
+	}
+	return w.c.Writer.Write(p)
+}
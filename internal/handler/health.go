@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"hermes/database"
+	"hermes/internal/logging"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readyTimeout bounds the database checks of Ready.
+const readyTimeout = 2 * time.Second
+
+var healthLog = logging.For("health")
+
+// Ready handles GET /health/ready: 200 once the database answers and every embedded migration
+// has been applied cleanly, 503 otherwise. /health stays a liveness check that touches nothing.
+// The endpoint is unauthenticated, so database errors are logged rather than returned.
+func Ready(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		out := vo.ReadinessVO{Status: "not_ready"}
+		if db == nil {
+			out.Database = "not configured"
+			c.JSON(http.StatusServiceUnavailable, out)
+			return
+		}
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readyTimeout)
+		defer cancel()
+		sqlDB, err := db.DB()
+		if err == nil {
+			err = sqlDB.PingContext(ctx)
+		}
+		if err != nil {
+			healthLog.ErrorContext(ctx, "ping database", "error", err)
+			out.Database = "unreachable"
+			c.JSON(http.StatusServiceUnavailable, out)
+			return
+		}
+		st, err := database.CheckMigrations(ctx, sqlDB)
+		out.MigrationVersion, out.LatestMigration = st.Version, st.Latest
+		switch {
+		case err != nil:
+			healthLog.ErrorContext(ctx, "check migrations", "error", err)
+			out.Database = "migration status unavailable"
+		case st.Dirty:
+			out.Database = "migration dirty"
+		case !st.Current():
+			out.Database = "migrations pending"
+		default:
+			out.Status, out.Database = "ready", "ok"
+			c.JSON(http.StatusOK, out)
+			return
+		}
+		c.JSON(http.StatusServiceUnavailable, out)
+	}
+}
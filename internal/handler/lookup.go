@@ -1,42 +1,70 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
+	"strings"
+	"time"
 
+	"hermes/internal/auth"
 	"hermes/internal/config"
 	"hermes/internal/dto"
+	"hermes/internal/indicator"
+	"hermes/internal/middleware"
+	"hermes/internal/providerapi"
 	"hermes/internal/registry"
 	"hermes/internal/service"
+	"hermes/internal/usage"
 	"hermes/internal/vo"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
+const (
+	// lookupEventsRecheck is how often LookupEvents re-reads a lookup it has no event for.
+	lookupEventsRecheck = 15 * time.Second
+	// lookupEventsMax is how long LookupEvents follows a lookup whose jobs stay pending.
+	lookupEventsMax = 10 * time.Minute
+)
+
 // LookupHandler handles unified lookup and per-provider lookup.
 type LookupHandler struct {
+	cfg       *config.Config
 	lookupSvc *service.LookupService
 	registry  *registry.Registry
+	// eventsMax caps a LookupEvents stream.
+	eventsMax time.Duration
 }
 
-// NewLookupHandler creates a new lookup handler.
+// NewLookupHandler creates a new lookup handler. Asynchronous provider jobs are polled while the
+// service's RunJobs is active.
 func NewLookupHandler(cfg *config.Config, db *gorm.DB) *LookupHandler {
-	reg := registry.NewRegistry(cfg)
+	reg := registry.NewRegistry(cfg, db)
 	return &LookupHandler{
+		cfg:       cfg,
 		lookupSvc: service.NewLookupService(cfg, reg, db),
 		registry:  reg,
+		eventsMax: lookupEventsMax,
 	}
 }
 
-// Lookup handles POST /lookup (unified lookup).
+// Lookup handles POST /lookup (unified lookup). With Accept: text/event-stream it streams like
+// LookupStream.
 // @Summary      Unified lookup
 // @Description  Run lookup across all providers that support the indicator type
 // @Tags         lookup
 // @Accept       json
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        body  body  dto.LookupRequestDTO  true  "Lookup request"
 // @Success      200  {object}  vo.LookupResponseVO
-// @Failure      400  {object}  vo.ErrorVO
+// @Failure      400  {object}  vo.ErrorVO  "Malformed body or invalid indicator (code INVALID_INDICATOR with details)"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ErrorVO  "Client rate limit or quota exceeded (see Retry-After)"
 // @Failure      500  {object}  vo.ErrorVO
 // @Router       /lookup [post]
 func (h *LookupHandler) Lookup(c *gin.Context) {
@@ -45,41 +73,155 @@ func (h *LookupHandler) Lookup(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
 		return
 	}
+	if strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		h.streamLookup(c, &req)
+		return
+	}
 	res, err := h.lookupSvc.Lookup(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		writeLookupError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, res)
 }
 
+// LookupStream handles POST /lookup/stream.
+// @Summary      Streamed unified lookup
+// @Description  Unified lookup as server-sent events: "start" (vo.LookupStartVO) once the request is recorded, one "result" (vo.ProviderResultVO) per provider as soon as it answers, then "complete" (vo.LookupResponseVO) with the aggregated verdict. Closing the connection cancels the providers still running. Invalid requests get a JSON error instead of a stream.
+// @Tags         lookup
+// @Accept       json
+// @Produce      text/event-stream
+// @Security     ApiKeyAuth
+// @Param        body  body  dto.LookupRequestDTO  true  "Lookup request"
+// @Success      200  {object}  vo.LookupResponseVO  "Event stream"
+// @Failure      400  {object}  vo.ErrorVO  "Malformed body or invalid indicator (code INVALID_INDICATOR with details)"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ErrorVO  "Client rate limit or quota exceeded (see Retry-After)"
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /lookup/stream [post]
+func (h *LookupHandler) LookupStream(c *gin.Context) {
+	var req dto.LookupRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	h.streamLookup(c, &req)
+}
+
+// streamLookup runs a lookup, writing its progress as server-sent events. The stream starts
+// with the first event, so errors before it (an invalid indicator) are answered as JSON.
+func (h *LookupHandler) streamLookup(c *gin.Context, req *dto.LookupRequestDTO) {
+	ctx := c.Request.Context()
+	send := func(event string, data interface{}) {
+		if ctx.Err() != nil {
+			return
+		}
+		c.SSEvent(event, data)
+		c.Writer.Flush()
+	}
+	started := false
+	res, err := h.lookupSvc.LookupStream(ctx, req, service.LookupHooks{
+		Started: func(v *vo.LookupStartVO) {
+			started = true
+			setEventStreamHeaders(c)
+			send("start", v)
+		},
+		Result: func(r vo.ProviderResultVO) { send("result", r) },
+	})
+	switch {
+	case err != nil && !started:
+		writeLookupError(c, err)
+	case err != nil:
+		send("error", vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+	default:
+		send("complete", res)
+	}
+}
+
+// setEventStreamHeaders prepares a server-sent events response.
+func setEventStreamHeaders(c *gin.Context) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+}
+
+// writeLookupError answers a failed unified lookup.
+func writeLookupError(c *gin.Context, err error) {
+	var invalid *indicator.Error
+	if errors.As(err, &invalid) {
+		c.JSON(http.StatusBadRequest, invalidIndicatorVO(invalid, "indicator_type", "indicator_value"))
+		return
+	}
+	c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+}
+
 // ProviderLookup handles GET /providers/:code/:type/:value.
 // @Summary      Single-provider lookup
-// @Description  Lookup using one provider by code (e.g. abuseipdb, virustotal)
+// @Description  Lookup using one provider by code (e.g. abuseipdb, virustotal). Requires scope provider:<code>.
 // @Tags         providers
 // @Produce      json
+// @Security     ApiKeyAuth
 // @Param        code   path  string  true  "Provider code (e.g. abuseipdb)"
-// @Param        type   path  string  true  "Indicator type (ip, domain, url, hash, email)"
+// @Param        type   path  string  true  "Indicator type (ip, domain, url, hash, email, cve)"
 // @Param        value  path  string  true  "Indicator value"
 // @Success      200  {object}  vo.ProviderLookupResponseVO
+// @Success      202  {object}  vo.ProviderLookupResponseVO  "Provider still working (pending); retry later"
 // @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
 // @Failure      404  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ProviderLookupResponseVO
 // @Failure      500  {object}  vo.ProviderLookupResponseVO
 // @Router       /providers/{code}/{type}/{value} [get]
 func (h *LookupHandler) ProviderLookup(c *gin.Context) {
 	code := c.Param("code")
-	indicatorType := c.Param("type")   // ip, domain, url, hash, email
+	indicatorType := c.Param("type") // ip, domain, url, hash, email, cve
 	value := c.Param("value")
 	if code == "" || indicatorType == "" || value == "" {
 		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "code, type, and value required"})
 		return
 	}
+	if scope := auth.ProviderScope(code); !middleware.Allowed(c, scope) {
+		c.JSON(http.StatusForbidden, vo.ErrorVO{Code: "FORBIDDEN", Message: "API key lacks scope " + scope})
+		return
+	}
+	ind, err := indicator.Canonicalize(indicatorType, value, indicator.Options{AllowPrivate: h.cfg.AllowPrivateIndicators})
+	var invalid *indicator.Error
+	if errors.As(err, &invalid) {
+		c.JSON(http.StatusBadRequest, invalidIndicatorVO(invalid, "type", "value"))
+		return
+	}
 	adapter := h.registry.AdapterByCode(code)
 	if adapter == nil {
 		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: "provider not found"})
 		return
 	}
-	res, err := adapter.Lookup(c.Request.Context(), indicatorType, value)
+	if !h.registry.Enabled(code) {
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "PROVIDER_DISABLED", Message: "provider is disabled"})
+		return
+	}
+	if ind.Subtype != "" && !providerapi.AcceptsHash(adapter, ind.Subtype) {
+		c.JSON(http.StatusBadRequest, invalidIndicatorVO(&indicator.Error{
+			Type:    ind.Type,
+			Value:   value,
+			Reason:  indicator.ReasonUnsupportedHash,
+			Message: "provider does not accept " + ind.Subtype + " hashes",
+		}, "type", "value"))
+		return
+	}
+	res, err := adapter.Lookup(c.Request.Context(), ind.Type, ind.Value)
+	if providerapi.Contacted(adapter, ind.Type, err) {
+		usage.CountProviderCall(c.Request.Context())
+	}
+	if errors.Is(err, providerapi.ErrRateLimited) {
+		c.JSON(http.StatusTooManyRequests, vo.ProviderLookupResponseVO{
+			ProviderCode: code,
+			Success:      false,
+			Error:        err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, vo.ProviderLookupResponseVO{
 			ProviderCode: code,
@@ -88,10 +230,209 @@ func (h *LookupHandler) ProviderLookup(c *gin.Context) {
 		})
 		return
 	}
-	c.JSON(http.StatusOK, vo.ProviderLookupResponseVO{
+	out := vo.ProviderLookupResponseVO{
 		ProviderCode: res.ProviderCode,
 		Success:      res.Success,
 		Data:         res.Data,
 		Error:        res.Error,
-	})
+		Pending:      res.Pending,
+	}
+	if res.Pending {
+		c.JSON(http.StatusAccepted, out)
+		return
+	}
+	if res.Success && res.Data != nil {
+		out.Assessment = service.ToAssessmentVO(adapter.Assess(res.Data))
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// invalidIndicatorVO maps an indicator validation failure to a structured 400 body, naming the
+// request's type or value field.
+func invalidIndicatorVO(err *indicator.Error, typeField, valueField string) vo.ErrorVO {
+	field := valueField
+	if err.Reason == indicator.ReasonUnsupportedType {
+		field = typeField
+	}
+	return vo.ErrorVO{
+		Code:    "INVALID_INDICATOR",
+		Message: err.Error(),
+		Details: []vo.FieldErrorVO{{Field: field, Reason: err.Reason, Message: err.Message}},
+	}
+}
+
+// GetLookup handles GET /lookups/:request_id.
+// @Summary      Get past lookup
+// @Description  Reconstruct a unified lookup response from storage. Only successful provider results are stored. With format=stix the lookup is returned as a STIX 2.1 bundle: the indicator as an observable and an indicator pattern (a vulnerability for CVEs), an identity per provider, and a note per provider verdict plus a sighting per malicious or suspicious one. Object IDs are deterministic, so re-exports deduplicate. With format=misp it is returned as a MISP event with one attribute tagged with the aggregate and provider verdicts and any galaxy clusters (e.g. Malpedia families) providers name.
+// @Tags         lookup
+// @Produce      json
+// @Produce      application/stix+json
+// @Security     ApiKeyAuth
+// @Param        request_id  path   string  true   "Request ID returned by /lookup"
+// @Param        format      query  string  false  "Response format"  Enums(json, stix, misp)  default(json)
+// @Success      200  {object}  vo.LookupResponseVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ErrorVO  "Client rate limit or quota exceeded (see Retry-After)"
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /lookups/{request_id} [get]
+func (h *LookupHandler) GetLookup(c *gin.Context) {
+	requestID, err := uuid.Parse(c.Param("request_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "request_id must be a UUID"})
+		return
+	}
+	format, ok := exportFormat(c, formatJSON, formatSTIX, formatMISP)
+	if !ok {
+		return
+	}
+	var res interface{}
+	switch format {
+	case formatSTIX:
+		res, err = h.lookupSvc.STIX(c.Request.Context(), requestID)
+	case formatMISP:
+		res, err = h.lookupSvc.MISP(c.Request.Context(), requestID)
+	default:
+		res, err = h.lookupSvc.Get(c.Request.Context(), requestID)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: "lookup not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	writeExport(c, format, res)
+}
+
+// LookupEvents handles GET /lookups/:request_id/events.
+// @Summary      Follow a lookup
+// @Description  Server-sent events for a lookup with pending provider jobs: a "result" event (vo.ProviderResultVO) as each job finishes, then one "complete" event (vo.LookupResponseVO) once none is pending, after which the stream ends. A lookup without pending jobs gets "complete" at once. Streams end after 10 minutes; jobs still pending then get a final "partial" event (vo.LookupResponseVO) instead, and the lookup can be followed again.
+// @Tags         lookup
+// @Produce      text/event-stream
+// @Security     ApiKeyAuth
+// @Param        request_id  path  string  true  "Request ID returned by /lookup"
+// @Success      200  {object}  vo.LookupResponseVO  "Event stream"
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ErrorVO  "Client rate limit or quota exceeded (see Retry-After)"
+// @Router       /lookups/{request_id}/events [get]
+func (h *LookupHandler) LookupEvents(c *gin.Context) {
+	requestID, err := uuid.Parse(c.Param("request_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "request_id must be a UUID"})
+		return
+	}
+	ctx := c.Request.Context()
+	// Subscribe before reading the lookup so no job finishing in between is missed.
+	updates, unsubscribe, err := h.lookupSvc.Subscribe(ctx, requestID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: "lookup not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	defer unsubscribe()
+
+	setEventStreamHeaders(c)
+	// Jobs polled by another instance publish no event here, so the lookup is re-read regularly.
+	recheck := time.NewTicker(lookupEventsRecheck)
+	defer recheck.Stop()
+	deadline := time.NewTimer(h.eventsMax)
+	defer deadline.Stop()
+	for expired := false; ; {
+		res, err := h.lookupSvc.Get(ctx, requestID)
+		if err != nil {
+			c.SSEvent("error", vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+			return
+		}
+		if !res.Partial {
+			c.SSEvent("complete", res)
+			return
+		}
+		if expired {
+			c.SSEvent("partial", res)
+			return
+		}
+		c.Writer.Flush()
+		select {
+		case r := <-updates:
+			c.SSEvent("result", r)
+		case <-recheck.C:
+			_, _ = c.Writer.WriteString(": keep-alive\n\n")
+		case <-deadline.C:
+			expired = true
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// ListLookups handles GET /lookups.
+// @Summary      List past lookups
+// @Description  Filter past lookups; pages are linked by next_cursor. With format=csv or xlsx every matching lookup is downloaded as a spreadsheet instead (cursor and limit are ignored): one row per lookup with the aggregate verdict and, per provider (only the provider filter's, if given), its verdict, score and key fields such as AbuseIPDB confidence, VirusTotal detection ratio or PhishTank verification, plus the provider errors; XLSX files list the errors on a second sheet. Rows are streamed as they are read.
+// @Tags         lookup
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security     ApiKeyAuth
+// @Param        indicator_type   query  string  false  "ip, domain, url, hash, email or cve"
+// @Param        indicator_value  query  string  false  "Indicator value (canonicalized when indicator_type is given)"
+// @Param        provider         query  string  false  "Only lookups with a result from this provider"
+// @Param        verdict          query  string  false  "Aggregate verdict: malicious, suspicious, clean or unknown"
+// @Param        since            query  string  false  "Created at or after (RFC 3339)"
+// @Param        until            query  string  false  "Created before (RFC 3339)"
+// @Param        sort             query  string  false  "created_at, -created_at (default), score or -score"
+// @Param        cursor           query  string  false  "next_cursor of the previous page"
+// @Param        limit            query  int     false  "Page size (1-200)"  default(50)
+// @Param        format           query  string  false  "Response format"  Enums(json, csv, xlsx)  default(json)
+// @Success      200  {object}  vo.LookupHistoryVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ErrorVO  "Client rate limit or quota exceeded (see Retry-After)"
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /lookups [get]
+func (h *LookupHandler) ListLookups(c *gin.Context) {
+	var q dto.LookupHistoryQueryDTO
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	format, ok := exportFormat(c, formatJSON, formatCSV, formatXLSX)
+	if !ok {
+		return
+	}
+	var res *vo.LookupHistoryVO
+	var err error
+	if format == formatJSON {
+		res, err = h.lookupSvc.History(c.Request.Context(), &q)
+	} else {
+		err = streamReport(c, format, "lookups", func(w io.Writer) error {
+			return h.lookupSvc.Report(c.Request.Context(), &q, w, format)
+		})
+	}
+	var invalid *indicator.Error
+	if errors.As(err, &invalid) {
+		c.JSON(http.StatusBadRequest, invalidIndicatorVO(invalid, "indicator_type", "indicator_value"))
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	if res != nil {
+		c.JSON(http.StatusOK, res)
+	}
 }
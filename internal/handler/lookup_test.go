@@ -5,12 +5,18 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"hermes/internal/config"
+	"hermes/internal/middleware"
 	"hermes/internal/model"
+	"hermes/internal/service"
+	"hermes/internal/vo"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -25,12 +31,25 @@ func setupTestRouter(t *testing.T) (*gin.Engine, *LookupHandler) {
 	}
 	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
 	assert.NoError(t, err)
-	_ = db.AutoMigrate(&model.LookupRequest{}, &model.LookupResult{}, &model.AuditLog{})
+	_ = db.AutoMigrate(&model.LookupRequest{}, &model.LookupResult{}, &model.AuditLog{}, &model.Provider{}, &model.BulkJob{}, &model.BulkJobItem{}, &model.ProviderJob{}, &model.LookupObject{})
 	lh := NewLookupHandler(cfg, db)
 	r := gin.New()
-	v1 := r.Group("/api/v1")
+	// AuthRequired is off: requests without a key run unauthenticated.
+	v1 := r.Group("/api/v1", middleware.Auth(service.NewAuthService(cfg, lh.registry, db), false))
 	v1.POST("/lookup", lh.Lookup)
+	v1.POST("/lookup/stream", lh.LookupStream)
 	v1.GET("/providers/:code/:type/:value", lh.ProviderLookup)
+	ph := NewProviderHandler(service.NewProviderService(cfg, lh.registry))
+	v1.GET("/providers", ph.List)
+	v1.GET("/providers/:code/health", ph.Health)
+	v1.GET("/lookups", lh.ListLookups)
+	v1.GET("/lookups/:request_id", lh.GetLookup)
+	v1.GET("/lookups/:request_id/events", lh.LookupEvents)
+	bh := NewBulkHandler(cfg, db, lh.lookupSvc, nil)
+	v1.POST("/lookups/bulk", bh.Submit)
+	v1.GET("/lookups/bulk/:id", bh.Get)
+	v1.POST("/lookups/:request_id/misp", lh.PushMISP)
+	v1.POST("/lookups/bulk/:id/misp", bh.PushMISP)
 	return r, lh
 }
 
@@ -54,3 +73,158 @@ func TestLookupHandler_ProviderLookup_NotFound(t *testing.T) {
 	r.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
+
+func TestLookupHandler_Lookup_InvalidIndicator(t *testing.T) {
+	r, _ := setupTestRouter(t)
+	body := bytes.NewBufferString(`{"indicator_type":"ip","indicator_value":"example.com"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/lookup", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var out vo.ErrorVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+	assert.Equal(t, "INVALID_INDICATOR", out.Code)
+	assert.Equal(t, []vo.FieldErrorVO{{Field: "indicator_value", Reason: "invalid_ip", Message: "not an IPv4 or IPv6 address"}}, out.Details)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/providers/virustotal/ip/10.1.2.3", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+	assert.Equal(t, "private_ip", out.Details[0].Reason)
+}
+
+func TestBulkHandler_SubmitText(t *testing.T) {
+	r, _ := setupTestRouter(t)
+	body := bytes.NewBufferString("# incident 42\nip 8.8.8.8\n8.8.8.8\nexample.com\n\nhash xyz\n")
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/lookups/bulk?cache=only", body)
+	req.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	var job vo.BulkJobVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &job))
+	assert.Equal(t, 3, job.Total)
+	assert.Equal(t, 1, job.Duplicates)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/lookups/bulk/"+job.JobID+"?page_size=5000", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLookupHandler_History(t *testing.T) {
+	r, _ := setupTestRouter(t)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups/550e8400-e29b-41d4-a716-446655440000", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups/550e8400-e29b-41d4-a716-446655440000?format=stix", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups/550e8400-e29b-41d4-a716-446655440000?format=xml", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Without MISP_URL pushing is unavailable.
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/lookups/550e8400-e29b-41d4-a716-446655440000/misp", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups?sort=-score&since=2025-01-01T00:00:00Z", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var page vo.LookupHistoryVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Empty(t, page.Items)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups?cursor=garbage", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups?format=csv", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="lookups.csv"`, w.Header().Get("Content-Disposition"))
+	assert.True(t, strings.HasPrefix(w.Body.String(), "request_id,indicator_type,"))
+
+	// Invalid queries are still answered before the download starts.
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups?format=xlsx&indicator_type=ip&indicator_value=nope", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestLookupHandler_LookupEvents(t *testing.T) {
+	r, _ := setupTestRouter(t)
+	body := bytes.NewBufferString(`{"indicator_type":"domain","indicator_value":"example.com","cache":"only"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/lookup", body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var res vo.LookupResponseVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+
+	// Nothing pending: the stream completes at once.
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups/"+res.RequestID+"/events", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/event-stream")
+	assert.Contains(t, w.Body.String(), "event:complete")
+	assert.Contains(t, w.Body.String(), res.RequestID)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/lookups/550e8400-e29b-41d4-a716-446655440000/events", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestLookupHandler_LookupEventsCapped(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&model.LookupRequest{}, &model.LookupResult{}, &model.Provider{}, &model.ProviderJob{}))
+	req := &model.LookupRequest{RequestID: uuid.New(), IndicatorType: "url", IndicatorValue: "https://example.com/",
+		IndicatorHash: "h", Verdict: "unknown"}
+	assert.NoError(t, db.Create(req).Error)
+	assert.NoError(t, db.Create(&model.ProviderJob{LookupRequestID: req.ID, RequestID: req.RequestID, ProviderCode: "urlscan",
+		ExternalID: "scan-1", Status: model.ProviderJobPending, NextPollAt: time.Now().Add(time.Hour)}).Error)
+
+	// The job stays pending with no pending timeout: the stream ends at its cap.
+	lh := NewLookupHandler(&config.Config{CacheTTLSeconds: 3600}, db)
+	lh.eventsMax = 20 * time.Millisecond
+	r := gin.New()
+	r.GET("/lookups/:request_id/events", lh.LookupEvents)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/lookups/"+req.RequestID.String()+"/events", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "event:partial")
+	assert.NotContains(t, w.Body.String(), "event:complete")
+}
+
+func TestLookupHandler_LookupStream(t *testing.T) {
+	r, _ := setupTestRouter(t)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/lookup/stream", bytes.NewBufferString(`{"indicator_type":"domain","indicator_value":"example.com","cache":"only"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	out := w.Body.String()
+	assert.True(t, strings.HasPrefix(out, "event:start\n"))
+	assert.Contains(t, out, "event:result\n")
+	assert.Contains(t, out, "event:complete\n")
+	assert.Less(t, strings.LastIndex(out, "event:result"), strings.Index(out, "event:complete"))
+
+	// Accept: text/event-stream on /lookup streams too; invalid indicators still get JSON.
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/lookup", bytes.NewBufferString(`{"indicator_type":"ip","indicator_value":"example.com"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var e vo.ErrorVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+	assert.Equal(t, "INVALID_INDICATOR", e.Code)
+}
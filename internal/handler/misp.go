@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"hermes/internal/misp"
+	"hermes/internal/service"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PushMISP handles POST /lookups/:request_id/misp.
+// @Summary      Push lookup to MISP
+// @Description  Push a past lookup to the configured MISP instance as the event GET /lookups/{request_id}?format=misp returns. The event UUID is derived from the request ID, so pushing again updates the same event. Requires scope misp:push.
+// @Tags         lookup
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        request_id  path  string  true  "Request ID returned by /lookup"
+// @Success      200  {object}  vo.MISPPushVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      502  {object}  vo.ErrorVO  "MISP rejected the event or could not be reached"
+// @Failure      503  {object}  vo.ErrorVO  "MISP is not configured"
+// @Router       /lookups/{request_id}/misp [post]
+func (h *LookupHandler) PushMISP(c *gin.Context) {
+	requestID, err := uuid.Parse(c.Param("request_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "request_id must be a UUID"})
+		return
+	}
+	res, err := h.lookupSvc.PushMISP(c.Request.Context(), requestID)
+	writePush(c, res, err, "lookup not found")
+}
+
+// PushMISP handles POST /lookups/bulk/:id/misp.
+// @Summary      Push bulk lookup to MISP
+// @Description  Push the lookups of a bulk job's finished items to the configured MISP instance as one event (see GET /lookups/bulk/{id}?format=misp); jobs with more than 10000 lookups are rejected. Pushing again updates the same event. Requires scope misp:push.
+// @Tags         lookup
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        id  path  string  true  "Job ID"
+// @Success      200  {object}  vo.MISPPushVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      502  {object}  vo.ErrorVO  "MISP rejected the event or could not be reached"
+// @Failure      503  {object}  vo.ErrorVO  "MISP is not configured"
+// @Router       /lookups/bulk/{id}/misp [post]
+func (h *BulkHandler) PushMISP(c *gin.Context) {
+	jobID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "id must be a UUID"})
+		return
+	}
+	res, err := h.bulkSvc.PushMISP(c.Request.Context(), jobID)
+	writePush(c, res, err, "bulk job not found")
+}
+
+// writePush answers a push: 404 with notFound, 400 for a job too large for one event, 503 without MISP configured and 502 when the
+// push itself failed.
+func writePush(c *gin.Context, res *vo.MISPPushVO, err error, notFound string) {
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, res)
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: notFound})
+	case errors.Is(err, service.ErrExportTooLarge):
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "EXPORT_TOO_LARGE", Message: err.Error()})
+	case errors.Is(err, misp.ErrNotConfigured):
+		c.JSON(http.StatusServiceUnavailable, vo.ErrorVO{Code: "NOT_CONFIGURED", Message: err.Error()})
+	case errors.Is(err, service.ErrMISPPush):
+		c.JSON(http.StatusBadGateway, vo.ErrorVO{Code: "UPSTREAM_ERROR", Message: err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+	}
+}
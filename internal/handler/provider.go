@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"hermes/internal/auth"
+	"hermes/internal/middleware"
+	"hermes/internal/service"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// ProviderHandler lists providers and checks their health.
+type ProviderHandler struct {
+	providerSvc *service.ProviderService
+}
+
+// NewProviderHandler creates a provider handler.
+func NewProviderHandler(providerSvc *service.ProviderService) *ProviderHandler {
+	return &ProviderHandler{providerSvc: providerSvc}
+}
+
+// List handles GET /providers.
+// @Summary      List providers
+// @Description  Every registered provider with its supported indicator types, whether its API key is configured, enabled state, rate limit, and success rate and latency of its latest calls (at most 100, since the server started).
+// @Tags         providers
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Success      200  {array}   vo.ProviderStatusVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Router       /providers [get]
+func (h *ProviderHandler) List(c *gin.Context) {
+	c.JSON(http.StatusOK, h.providerSvc.List())
+}
+
+// Health handles GET /providers/:code/health.
+// @Summary      Provider health check
+// @Description  Looks up a harmless canary indicator (e.g. 8.8.8.8, example.com, the EICAR test file hash) with the provider and reports whether it answered. The call counts against the provider's rate limit and the client's quota. Asynchronous providers are never asked to start a scan: they are checked with a search, or reported as skipped. Requires scope provider:<code>.
+// @Tags         providers
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        code  path  string  true  "Provider code (e.g. abuseipdb)"
+// @Success      200  {object}  vo.ProviderHealthVO  "Healthy, or skipped"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      429  {object}  vo.ErrorVO  "Client rate limit or quota exceeded (see Retry-After)"
+// @Failure      503  {object}  vo.ProviderHealthVO  "Canary lookup failed"
+// @Router       /providers/{code}/health [get]
+func (h *ProviderHandler) Health(c *gin.Context) {
+	code := c.Param("code")
+	if scope := auth.ProviderScope(code); !middleware.Allowed(c, scope) {
+		c.JSON(http.StatusForbidden, vo.ErrorVO{Code: "FORBIDDEN", Message: "API key lacks scope " + scope})
+		return
+	}
+	res, err := h.providerSvc.Health(c.Request.Context(), code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: "provider not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	status := http.StatusOK
+	if !res.Healthy && res.Status != vo.HealthSkipped {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, res)
+}
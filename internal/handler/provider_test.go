@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestProviderHandler_ListAndHealth(t *testing.T) {
+	r, _ := setupTestRouter(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/providers", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list []vo.ProviderStatusVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	var vt *vo.ProviderStatusVO
+	for i := range list {
+		if list[i].Code == "virustotal" {
+			vt = &list[i]
+		}
+	}
+	if assert.NotNil(t, vt) {
+		assert.False(t, vt.Configured)
+		assert.Contains(t, vt.SupportedTypes, "ip")
+		assert.Nil(t, vt.Recent.SuccessRate)
+	}
+
+	// Without an API key the canary is not sent.
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/providers/virustotal/health", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var health vo.ProviderHealthVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.False(t, health.Healthy)
+	assert.Equal(t, "not configured", health.Error)
+	assert.NotEmpty(t, health.IndicatorValue)
+
+	// Async providers are checked with a search, or not at all, never with a new scan.
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/providers/urlscan/health", nil))
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Equal(t, "domain", health.IndicatorType)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/providers/ssllabs/health", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &health))
+	assert.Equal(t, vo.HealthSkipped, health.Status)
+	assert.False(t, health.Healthy)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/providers/nope/health", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestReady(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	r := gin.New()
+	r.GET("/health/ready", Ready(db))
+	get := func() (int, vo.ReadinessVO) {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+		var out vo.ReadinessVO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+		return w.Code, out
+	}
+
+	code, out := get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "not_ready", out.Status)
+
+	assert.NoError(t, db.Exec("CREATE TABLE schema_migrations (version bigint, dirty boolean)").Error)
+	assert.NoError(t, db.Exec("INSERT INTO schema_migrations VALUES (?, ?)", 20250101000000, false).Error)
+	code, out = get()
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "migrations pending", out.Database)
+
+	assert.NoError(t, db.Exec("UPDATE schema_migrations SET version = ?", out.LatestMigration).Error)
+	code, out = get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ready", out.Status)
+	assert.Equal(t, "ok", out.Database)
+}
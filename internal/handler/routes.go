@@ -1,21 +1,123 @@
 package handler
 
 import (
+	"context"
+	"sync"
+
+	"hermes/internal/auth"
 	"hermes/internal/config"
+	"hermes/internal/middleware"
+	"hermes/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
-// RegisterRoutes mounts API v1 routes. cfg and db are used by lookup and provider handlers.
-func RegisterRoutes(v1 *gin.RouterGroup, cfg *config.Config, db *gorm.DB) {
+// RegisterRoutes mounts API v1 routes on v1 and the TAXII 2.1 server on taxii. cfg and db are
+// used by lookup and provider handlers. Everything except /ping requires an API key (see
+// middleware.Auth); single-provider lookups and health checks additionally check provider:<code>
+// in the handler, TAXII requires taxii:read, pushing to MISP misp:push and blocklists
+// blocklist:read. Routes calling providers count against the client's rate and quotas
+// (middleware.Quota). The returned Workers run the handlers' background work; nil without db.
+func RegisterRoutes(v1, taxii *gin.RouterGroup, cfg *config.Config, db *gorm.DB) *Workers {
 	v1.GET("/ping", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong"})
 	})
 
-	if db != nil {
-		lh := NewLookupHandler(cfg, db)
-		v1.POST("/lookup", lh.Lookup)
-		v1.GET("/providers/:code/:type/:value", lh.ProviderLookup)
+	if db == nil {
+		return nil
 	}
+	lh := NewLookupHandler(cfg, db)
+	authSvc := service.NewAuthService(cfg, lh.registry, db)
+	usageSvc := service.NewUsageService(cfg, db)
+	api := v1.Group("", middleware.Auth(authSvc, cfg.AuthRequired))
+	api.GET("/usage", NewUsageHandler(usageSvc).Get)
+	ph := NewProviderHandler(service.NewProviderService(cfg, lh.registry))
+	api.GET("/providers", ph.List)
+
+	metered := api.Group("", middleware.Quota(usageSvc))
+	metered.GET("/providers/:code/health", ph.Health)
+	metered.GET("/providers/:code/:type/:value", lh.ProviderLookup)
+
+	lookups := metered.Group("", middleware.RequireScope(auth.ScopeLookupRead))
+	lookups.POST("/lookup", lh.Lookup)
+	lookups.POST("/lookup/stream", lh.LookupStream)
+	lookups.GET("/lookups", lh.ListLookups)
+	lookups.GET("/lookups/:request_id", lh.GetLookup)
+	lookups.GET("/lookups/:request_id/events", lh.LookupEvents)
+
+	bh := NewBulkHandler(cfg, db, lh.lookupSvc, usageSvc)
+	lookups.POST("/lookups/bulk", bh.Submit)
+	lookups.GET("/lookups/bulk/:id", bh.Get)
+
+	push := api.Group("", middleware.RequireScope(auth.ScopeMISPPush))
+	push.POST("/lookups/:request_id/misp", lh.PushMISP)
+	push.POST("/lookups/bulk/:id/misp", bh.PushMISP)
+
+	blSvc := service.NewBlocklistService(cfg, db)
+	blh := NewBlocklistHandler(blSvc)
+	api.GET("/blocklists/:name", middleware.RequireScope(auth.ScopeBlocklistRead), blh.Get)
+
+	ah := NewAdminHandler(authSvc)
+	admin := api.Group("/admin", middleware.RequireScope(auth.ScopeAdmin))
+	admin.POST("/clients", ah.CreateClient)
+	admin.GET("/clients", ah.ListClients)
+	admin.PUT("/clients/:id/limits", ah.SetClientLimits)
+	admin.POST("/clients/:id/keys", ah.CreateKey)
+	admin.POST("/keys/:key_id/rotate", ah.RotateKey)
+	admin.DELETE("/keys/:key_id", ah.RevokeKey)
+	admin.GET("/blocklists", blh.List)
+	admin.POST("/blocklists", blh.Create)
+	admin.PUT("/blocklists/:name", blh.Update)
+	admin.DELETE("/blocklists/:name", blh.Delete)
+	admin.GET("/allowlist", blh.ListAllowlist)
+	admin.POST("/allowlist", blh.AddAllowlistEntry)
+	admin.DELETE("/allowlist/:id", blh.DeleteAllowlistEntry)
+
+	th := NewTAXIIHandler(service.NewTAXIIService(lh.lookupSvc, db))
+	admin.POST("/taxii/collections", th.CreateCollection)
+	admin.DELETE("/taxii/collections/:id", th.DeleteCollection)
+	admin.POST("/taxii/collections/:id/lookups", th.AddLookup)
+	admin.DELETE("/taxii/collections/:id/lookups/:request_id", th.RemoveLookup)
+
+	t := taxii.Group("", middleware.Auth(authSvc, cfg.AuthRequired), middleware.RequireScope(auth.ScopeTAXIIRead), TAXIIAccept())
+	t.GET("/", th.Discovery)
+	t.GET("/api/", th.APIRoot)
+	t.GET("/api/collections/", th.Collections)
+	t.GET("/api/collections/:id/", th.Collection)
+	t.GET("/api/collections/:id/objects/", th.Objects)
+	t.GET("/api/collections/:id/objects/:object_id/", th.Object)
+	t.GET("/api/collections/:id/objects/:object_id/versions/", th.Versions)
+	t.GET("/api/collections/:id/manifest/", th.Manifest)
+	return &Workers{lookup: lh.lookupSvc, usage: usageSvc, bulk: bh.bulkSvc, blocklist: blSvc}
+}
+
+// Workers is the background work behind the routes: asynchronous provider jobs, bulk jobs,
+// indexing the STIX objects of lookups stored before they were recorded and refreshing
+// blocklists.
+type Workers struct {
+	lookup    *service.LookupService
+	usage     *service.UsageService
+	bulk      *service.BulkService
+	blocklist *service.BlocklistService
+}
+
+// Run runs the workers until ctx is cancelled and returns once they have stopped.
+func (w *Workers) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		w.lookup.RunJobs(ctx, w.usage)
+	}()
+	go func() {
+		defer wg.Done()
+		w.blocklist.Run(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		w.lookup.IndexObjects(ctx)
+	}()
+	w.bulk.Run(ctx)
+	wg.Wait()
 }
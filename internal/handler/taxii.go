@@ -0,0 +1,338 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"hermes/internal/dto"
+	"hermes/internal/service"
+	"hermes/internal/stix"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// taxiiMediaType is the content type of TAXII 2.1 resources.
+const taxiiMediaType = "application/taxii+json;version=2.1"
+
+// TAXIIHandler serves the TAXII 2.1 API under /taxii2/ (discovery, one API root "api" and its
+// read-only collections) and manages curated collections under /admin/taxii.
+type TAXIIHandler struct {
+	taxiiSvc *service.TAXIIService
+}
+
+// NewTAXIIHandler creates a TAXII handler.
+func NewTAXIIHandler(taxiiSvc *service.TAXIIService) *TAXIIHandler {
+	return &TAXIIHandler{taxiiSvc: taxiiSvc}
+}
+
+// TAXIIAccept answers 406 to requests whose Accept header rules out TAXII 2.1 responses.
+func TAXIIAccept() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		accept := c.GetHeader("Accept")
+		if accept == "" || strings.Contains(accept, "*/*") || strings.Contains(accept, "application/*") {
+			c.Next()
+			return
+		}
+		for _, part := range strings.Split(accept, ",") {
+			media := strings.ToLower(strings.ReplaceAll(part, " ", ""))
+			if strings.HasPrefix(media, "application/taxii+json") && (!strings.Contains(media, "version=") || strings.Contains(media, "version=2.1")) {
+				c.Next()
+				return
+			}
+		}
+		taxiiError(c, http.StatusNotAcceptable, "Not Acceptable", "this server serves "+taxiiMediaType)
+		c.Abort()
+	}
+}
+
+// Discovery handles GET /taxii2/.
+func (h *TAXIIHandler) Discovery(c *gin.Context) {
+	root := baseURL(c) + "/taxii2/api/"
+	taxiiJSON(c, http.StatusOK, vo.TAXIIDiscoveryVO{
+		Title:       "Hermes TAXII",
+		Description: "Indicators from Hermes lookups as STIX 2.1",
+		Default:     root,
+		APIRoots:    []string{root},
+	})
+}
+
+// APIRoot handles GET /taxii2/api/.
+func (h *TAXIIHandler) APIRoot(c *gin.Context) {
+	taxiiJSON(c, http.StatusOK, vo.TAXIIAPIRootVO{
+		Title:       "Hermes",
+		Description: "Built-in collections derived from lookup verdicts and curated collections",
+		Versions:    []string{taxiiMediaType},
+	})
+}
+
+// Collections handles GET /taxii2/api/collections/.
+func (h *TAXIIHandler) Collections(c *gin.Context) {
+	list, err := h.taxiiSvc.Collections()
+	if err != nil {
+		taxiiError(c, http.StatusInternalServerError, "Internal Error", err.Error())
+		return
+	}
+	taxiiJSON(c, http.StatusOK, vo.TAXIICollectionsVO{Collections: list})
+}
+
+// Collection handles GET /taxii2/api/collections/:id/.
+func (h *TAXIIHandler) Collection(c *gin.Context) {
+	res, err := h.taxiiSvc.Collection(c.Param("id"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		taxiiError(c, http.StatusNotFound, "Not Found", "collection not found")
+		return
+	}
+	if err != nil {
+		taxiiError(c, http.StatusInternalServerError, "Internal Error", err.Error())
+		return
+	}
+	taxiiJSON(c, http.StatusOK, res)
+}
+
+// Objects handles GET /taxii2/api/collections/:id/objects/: a page of the collection's STIX
+// objects, filtered by added_after, match[type] and match[id], resumed with next.
+func (h *TAXIIHandler) Objects(c *gin.Context) {
+	page, ok := h.page(c, "")
+	if !ok {
+		return
+	}
+	taxiiJSON(c, http.StatusOK, envelope(page))
+}
+
+// Object handles GET /taxii2/api/collections/:id/objects/:object_id/.
+func (h *TAXIIHandler) Object(c *gin.Context) {
+	page, ok := h.page(c, c.Param("object_id"))
+	if !ok {
+		return
+	}
+	if len(page.Objects) == 0 && !page.More {
+		taxiiError(c, http.StatusNotFound, "Not Found", "object not found")
+		return
+	}
+	taxiiJSON(c, http.StatusOK, envelope(page))
+}
+
+// Versions handles GET /taxii2/api/collections/:id/objects/:object_id/versions/.
+func (h *TAXIIHandler) Versions(c *gin.Context) {
+	page, ok := h.page(c, c.Param("object_id"))
+	if !ok {
+		return
+	}
+	if len(page.Objects) == 0 && !page.More {
+		taxiiError(c, http.StatusNotFound, "Not Found", "object not found")
+		return
+	}
+	res := vo.TAXIIVersionsVO{More: page.More}
+	for _, o := range page.Objects {
+		res.Versions = append(res.Versions, version(o))
+	}
+	sort.Strings(res.Versions)
+	taxiiJSON(c, http.StatusOK, res)
+}
+
+// Manifest handles GET /taxii2/api/collections/:id/manifest/, filtered and paged like Objects.
+func (h *TAXIIHandler) Manifest(c *gin.Context) {
+	page, ok := h.page(c, "")
+	if !ok {
+		return
+	}
+	res := vo.TAXIIManifestVO{More: page.More, Next: page.Next}
+	for _, o := range page.Objects {
+		res.Objects = append(res.Objects, vo.TAXIIManifestRecordVO{
+			ID:        o.Object.ID(),
+			DateAdded: stix.Timestamp(o.DateAdded),
+			Version:   version(o),
+			MediaType: stix.MediaType,
+		})
+	}
+	taxiiJSON(c, http.StatusOK, res)
+}
+
+// page loads the page of objects the request asks for, restricted to objectID when set, and
+// sets the X-TAXII-Date-Added-* headers. It answers errors itself.
+func (h *TAXIIHandler) page(c *gin.Context, objectID string) (*service.TAXIIPage, bool) {
+	var q dto.TAXIIQueryDTO
+	if err := c.ShouldBindQuery(&q); err != nil {
+		taxiiError(c, http.StatusBadRequest, "Bad Request", err.Error())
+		return nil, false
+	}
+	if objectID != "" {
+		q.IDs = objectID
+	}
+	page, err := h.taxiiSvc.Objects(c.Param("id"), &q)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		taxiiError(c, http.StatusNotFound, "Not Found", "collection not found")
+		return nil, false
+	}
+	if errors.Is(err, service.ErrInvalidCursor) {
+		taxiiError(c, http.StatusBadRequest, "Bad Request", "invalid next")
+		return nil, false
+	}
+	if err != nil {
+		taxiiError(c, http.StatusInternalServerError, "Internal Error", err.Error())
+		return nil, false
+	}
+	if n := len(page.Objects); n > 0 {
+		c.Header("X-TAXII-Date-Added-First", stix.Timestamp(page.Objects[0].DateAdded))
+		c.Header("X-TAXII-Date-Added-Last", stix.Timestamp(page.Objects[n-1].DateAdded))
+	}
+	return page, true
+}
+
+// CreateCollection handles POST /admin/taxii/collections.
+// @Summary      Create curated TAXII collection
+// @Description  Creates an empty collection served under /taxii2/api/collections/{id}/; add lookups to fill it.
+// @Tags         taxii
+// @Accept       json
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        body  body  dto.CreateTAXIICollectionDTO  true  "Collection"
+// @Success      201  {object}  vo.TAXIICollectionVO
+// @Failure      400  {object}  vo.ErrorVO  "Invalid body, or the alias is taken or a UUID"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/taxii/collections [post]
+func (h *TAXIIHandler) CreateCollection(c *gin.Context) {
+	var req dto.CreateTAXIICollectionDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	res, err := h.taxiiSvc.CreateCollection(&req)
+	if err != nil {
+		writeCollectionError(c, err, "")
+		return
+	}
+	c.JSON(http.StatusCreated, res)
+}
+
+// DeleteCollection handles DELETE /admin/taxii/collections/:id.
+// @Summary      Delete curated TAXII collection
+// @Tags         taxii
+// @Security     ApiKeyAuth
+// @Param        id  path  string  true  "Collection ID or alias"
+// @Success      204
+// @Failure      400  {object}  vo.ErrorVO  "Built-in collection"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/taxii/collections/{id} [delete]
+func (h *TAXIIHandler) DeleteCollection(c *gin.Context) {
+	if err := h.taxiiSvc.DeleteCollection(c.Param("id")); err != nil {
+		writeCollectionError(c, err, "collection not found")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// AddLookup handles POST /admin/taxii/collections/:id/lookups.
+// @Summary      Add lookup to curated TAXII collection
+// @Description  The lookup's STIX objects (see GET /lookups/{request_id}?format=stix) join the collection, dated now. Adding a lookup twice keeps its first date.
+// @Tags         taxii
+// @Accept       json
+// @Security     ApiKeyAuth
+// @Param        id    path  string              true  "Collection ID or alias"
+// @Param        body  body  dto.TAXIILookupDTO  true  "Lookup"
+// @Success      204
+// @Failure      400  {object}  vo.ErrorVO  "Invalid body or built-in collection"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO  "Collection or lookup not found"
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/taxii/collections/{id}/lookups [post]
+func (h *TAXIIHandler) AddLookup(c *gin.Context) {
+	var req dto.TAXIILookupDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+		return
+	}
+	if err := h.taxiiSvc.AddLookup(c.Param("id"), uuid.MustParse(req.RequestID)); err != nil {
+		writeCollectionError(c, err, "collection or lookup not found")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveLookup handles DELETE /admin/taxii/collections/:id/lookups/:request_id.
+// @Summary      Remove lookup from curated TAXII collection
+// @Tags         taxii
+// @Security     ApiKeyAuth
+// @Param        id          path  string  true  "Collection ID or alias"
+// @Param        request_id  path  string  true  "Request ID of the lookup"
+// @Success      204
+// @Failure      400  {object}  vo.ErrorVO  "Built-in collection"
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO  "Collection or lookup not found, or the lookup is not in the collection"
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /admin/taxii/collections/{id}/lookups/{request_id} [delete]
+func (h *TAXIIHandler) RemoveLookup(c *gin.Context) {
+	requestID, err := uuid.Parse(c.Param("request_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "request_id must be a UUID"})
+		return
+	}
+	if err := h.taxiiSvc.RemoveLookup(c.Param("id"), requestID); err != nil {
+		writeCollectionError(c, err, "lookup not in collection")
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func writeCollectionError(c *gin.Context, err error, notFound string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidCollection):
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: notFound})
+	default:
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+	}
+}
+
+func envelope(page *service.TAXIIPage) vo.TAXIIEnvelopeVO {
+	env := vo.TAXIIEnvelopeVO{More: page.More, Next: page.Next}
+	for _, o := range page.Objects {
+		env.Objects = append(env.Objects, o.Object)
+	}
+	return env
+}
+
+// version is an object's TAXII version: modified, else created, else when it was added.
+func version(o service.TAXIIObject) string {
+	for _, k := range []string{"modified", "created"} {
+		if v, ok := o.Object[k].(string); ok {
+			return v
+		}
+	}
+	return stix.Timestamp(o.DateAdded)
+}
+
+func taxiiJSON(c *gin.Context, status int, v interface{}) {
+	c.Header("Content-Type", taxiiMediaType)
+	c.JSON(status, v)
+}
+
+func taxiiError(c *gin.Context, status int, title, description string) {
+	taxiiJSON(c, status, vo.TAXIIErrorVO{Title: title, Description: description, HTTPStatus: strconv.Itoa(status)})
+}
+
+// baseURL is the scheme and host the client reached, honoring X-Forwarded-Proto.
+func baseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if p := c.GetHeader("X-Forwarded-Proto"); p == "http" || p == "https" {
+		scheme = p
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"hermes/internal/config"
+	"hermes/internal/model"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// setupTAXIIRouter stores two malicious lookups and a clean one, the first made ten days ago,
+// and mounts RegisterRoutes without authentication.
+func setupTAXIIRouter(t *testing.T) (func(method, path, body string) *httptest.ResponseRecorder, []uuid.UUID) {
+	gin.SetMode(gin.TestMode)
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&model.LookupRequest{}, &model.LookupResult{}, &model.AuditLog{}, &model.Provider{},
+		&model.BulkJob{}, &model.BulkJobItem{}, &model.ProviderJob{}, &model.TAXIICollection{}, &model.TAXIICollectionLookup{}, &model.LookupObject{}))
+
+	var ids []uuid.UUID
+	for i, l := range []struct{ typ, value, verdict string }{
+		{"ip", "203.0.113.7", "malicious"},
+		{"domain", "evil.example", "malicious"},
+		{"domain", "example.com", "clean"},
+	} {
+		req := &model.LookupRequest{RequestID: uuid.New(), IndicatorType: l.typ, IndicatorValue: l.value, Verdict: l.verdict,
+			CreatedAt: time.Now().Add(time.Duration(i-10) * time.Minute)}
+		if i == 0 {
+			// Made before the window, turned malicious within it: served as added now.
+			req.CreatedAt = time.Now().Add(-10 * 24 * time.Hour)
+		}
+		assert.NoError(t, db.Create(req).Error)
+		assert.NoError(t, db.Create(&model.LookupResult{LookupRequestID: req.ID, ProviderCode: "virustotal",
+			RawResponse: model.JSONB{}, Verdict: l.verdict, Score: 90}).Error)
+		ids = append(ids, req.RequestID)
+	}
+
+	r := gin.New()
+	workers := RegisterRoutes(r.Group("/api/v1"), r.Group("/taxii2"), &config.Config{CacheTTLSeconds: 3600}, db)
+	// The lookups were stored directly: index their objects as at startup.
+	workers.lookup.IndexObjects(context.Background())
+	return func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", taxiiMediaType)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}, ids
+}
+
+func TestTAXII_DiscoveryAndCollections(t *testing.T) {
+	do, _ := setupTAXIIRouter(t)
+
+	w := do(http.MethodGet, "/taxii2/", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, taxiiMediaType, w.Header().Get("Content-Type"))
+	var disc vo.TAXIIDiscoveryVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &disc))
+	assert.Equal(t, []string{"http://example.com/taxii2/api/"}, disc.APIRoots)
+
+	w = do(http.MethodGet, "/taxii2/api/collections/", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var cols vo.TAXIICollectionsVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &cols))
+	if assert.Len(t, cols.Collections, 1) {
+		assert.Equal(t, "malicious-last-7d", cols.Collections[0].Alias)
+		assert.True(t, cols.Collections[0].CanRead)
+	}
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/taxii2/api/collections/nope/", "").Code)
+
+	r := gin.New()
+	r.GET("/", TAXIIAccept(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	for accept, code := range map[string]int{"application/xml": http.StatusNotAcceptable,
+		"application/taxii+json;version=2.0": http.StatusNotAcceptable, "application/taxii+json": http.StatusOK} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, code, w.Code, accept)
+	}
+}
+
+func TestTAXII_ObjectsPaging(t *testing.T) {
+	do, _ := setupTAXIIRouter(t)
+	base := "/taxii2/api/collections/malicious-last-7d/"
+
+	var all []map[string]interface{}
+	next := ""
+	for pages := 0; pages < 20; pages++ {
+		w := do(http.MethodGet, base+"objects/?limit=3&next="+url.QueryEscape(next), "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		var env vo.TAXIIEnvelopeVO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+		assert.LessOrEqual(t, len(env.Objects), 3)
+		all = append(all, env.Objects...)
+		if !env.More {
+			break
+		}
+		next = env.Next
+	}
+	indicators := map[string]string{}
+	for _, o := range all {
+		if o["type"] == "indicator" {
+			indicators[o["id"].(string)] = o["pattern"].(string)
+		}
+	}
+	assert.ElementsMatch(t, []string{"[ipv4-addr:value = '203.0.113.7']", "[domain-name:value = 'evil.example']"}, values(indicators))
+
+	w := do(http.MethodGet, base+"objects/?match[type]=indicator,domain-name", "")
+	var env vo.TAXIIEnvelopeVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.Len(t, env.Objects, 3)
+	assert.False(t, env.More)
+	assert.NotEmpty(t, w.Header().Get("X-TAXII-Date-Added-First"))
+
+	for id := range indicators {
+		w = do(http.MethodGet, base+"objects/"+id+"/", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = do(http.MethodGet, base+"objects/"+id+"/versions/", "")
+		var versions vo.TAXIIVersionsVO
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &versions))
+		assert.Len(t, versions.Versions, 1)
+	}
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, base+"objects/indicator--"+uuid.NewString()+"/", "").Code)
+
+	w = do(http.MethodGet, base+"manifest/?match[type]=ipv4-addr&added_after="+url.QueryEscape(time.Now().Add(-time.Hour).Format(time.RFC3339)), "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var added vo.TAXIIManifestVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &added))
+	assert.Len(t, added.Objects, 1)
+
+	w = do(http.MethodGet, base+"manifest/?added_after=2999-01-01T00:00:00Z", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var manifest vo.TAXIIManifestVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &manifest))
+	assert.Empty(t, manifest.Objects)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodGet, base+"objects/?next=garbage", "").Code)
+}
+
+func TestTAXII_CuratedCollection(t *testing.T) {
+	do, ids := setupTAXIIRouter(t)
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/admin/taxii/collections", `{"title":"x","alias":"malicious-last-7d"}`).Code)
+	w := do(http.MethodPost, "/api/v1/admin/taxii/collections", `{"title":"Watchlist","alias":"watch"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var col vo.TAXIICollectionVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &col))
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodPost, "/api/v1/admin/taxii/collections/watch/lookups", `{"request_id":"`+ids[2].String()+`"}`).Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodPost, "/api/v1/admin/taxii/collections/watch/lookups", `{"request_id":"`+uuid.NewString()+`"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/admin/taxii/collections/malicious-last-7d/lookups", `{"request_id":"`+ids[2].String()+`"}`).Code)
+
+	w = do(http.MethodGet, "/taxii2/api/collections/"+col.ID+"/manifest/?match[type]=indicator", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var manifest vo.TAXIIManifestVO
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &manifest))
+	assert.Len(t, manifest.Objects, 1)
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/v1/admin/taxii/collections/watch/lookups/"+ids[2].String(), "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/api/v1/admin/taxii/collections/watch/lookups/"+ids[2].String(), "").Code)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/v1/admin/taxii/collections/watch", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/taxii2/api/collections/watch/", "").Code)
+}
+
+func values(m map[string]string) []string {
+	out := make([]string, 0, len(m))
+	for _, v := range m {
+		out = append(out, v)
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"hermes/internal/auth"
+	"hermes/internal/service"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// UsageHandler reports client usage.
+type UsageHandler struct {
+	usageSvc *service.UsageService
+}
+
+// NewUsageHandler creates a usage handler.
+func NewUsageHandler(usageSvc *service.UsageService) *UsageHandler {
+	return &UsageHandler{usageSvc: usageSvc}
+}
+
+// Get handles GET /usage.
+// @Summary      Current usage
+// @Description  Requests and provider calls of the calling client today and this month (UTC), with its limits. Quotas count provider calls: one /lookup may call many providers.
+// @Tags         usage
+// @Produce      json
+// @Security     ApiKeyAuth
+// @Param        client  query  string  false  "Client name (admin only; default: the caller)"
+// @Success      200  {object}  vo.UsageVO
+// @Failure      400  {object}  vo.ErrorVO
+// @Failure      401  {object}  vo.ErrorVO
+// @Failure      403  {object}  vo.ErrorVO
+// @Failure      404  {object}  vo.ErrorVO
+// @Failure      500  {object}  vo.ErrorVO
+// @Router       /usage [get]
+func (h *UsageHandler) Get(c *gin.Context) {
+	p := auth.FromContext(c.Request.Context())
+	if p == nil {
+		c.JSON(http.StatusBadRequest, vo.ErrorVO{Code: "BAD_REQUEST", Message: "usage is tracked per API key; send one"})
+		return
+	}
+	client := c.Query("client")
+	if client != "" && client != p.ClientName && !p.IsAdmin() {
+		c.JSON(http.StatusForbidden, vo.ErrorVO{Code: "FORBIDDEN", Message: "only admins may read other clients' usage"})
+		return
+	}
+	res, err := h.usageSvc.Usage(p, client)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, vo.ErrorVO{Code: "NOT_FOUND", Message: "client not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,348 @@
+// Package indicator validates and canonicalizes indicator values before they are sent to
+// providers or used as cache keys: defanged input is refanged, IPs, domains, URLs and emails are
+// normalized, hashes are classified by algorithm, and private/reserved IPs are rejected unless
+// allowed.
+package indicator
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"hermes/internal/cve"
+
+	"golang.org/x/net/idna"
+)
+
+// Indicator types.
+const (
+	TypeIP     = "ip"
+	TypeDomain = "domain"
+	TypeURL    = "url"
+	TypeHash   = "hash"
+	TypeEmail  = "email"
+	TypeCVE    = "cve"
+)
+
+// Hash subtypes reported in Indicator.Subtype.
+const (
+	HashMD5    = "md5"
+	HashSHA1   = "sha1"
+	HashSHA256 = "sha256"
+	HashSHA512 = "sha512"
+	HashSSDEEP = "ssdeep"
+	HashTLSH   = "tlsh"
+)
+
+// Error reasons reported in Error.Reason.
+const (
+	ReasonUnsupportedType = "unsupported_type"
+	ReasonInvalidIP       = "invalid_ip"
+	ReasonPrivateIP       = "private_ip"
+	ReasonInvalidDomain   = "invalid_domain"
+	ReasonInvalidURL      = "invalid_url"
+	ReasonInvalidHash     = "invalid_hash"
+	ReasonInvalidEmail    = "invalid_email"
+	ReasonInvalidCVE      = "invalid_cve"
+	// ReasonUnsupportedHash is reported by callers when a provider does not accept the hash's subtype.
+	ReasonUnsupportedHash = "unsupported_hash_type"
+)
+
+// ErrInvalid is wrapped by every *Error.
+var ErrInvalid = errors.New("invalid indicator")
+
+// Error describes why a value was rejected.
+type Error struct {
+	Type  string
+	Value string
+	// Reason is one of the Reason* constants.
+	Reason  string
+	Message string
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("invalid %s %q: %s", e.Type, e.Value, e.Message) }
+
+// Unwrap lets errors.Is(err, ErrInvalid) match.
+func (e *Error) Unwrap() error { return ErrInvalid }
+
+// Indicator is a validated, canonical indicator.
+type Indicator struct {
+	Type  string
+	Value string
+	// Subtype is the hash algorithm (Hash* constants) for hash indicators, empty otherwise.
+	Subtype string
+}
+
+// Options controls Canonicalize.
+type Options struct {
+	// AllowPrivate accepts private, loopback, link-local and other reserved IPs (also as URL hosts).
+	AllowPrivate bool
+}
+
+// Canonicalize validates value for indicatorType and returns its canonical form. Failures are *Error.
+func Canonicalize(indicatorType, value string, opts Options) (Indicator, error) {
+	raw := value
+	value = strings.TrimSpace(value)
+	fail := func(reason, msg string) (Indicator, error) {
+		return Indicator{}, &Error{Type: indicatorType, Value: raw, Reason: reason, Message: msg}
+	}
+
+	var (
+		out string
+		sub string
+		err *Error
+	)
+	switch indicatorType {
+	case TypeIP:
+		out, err = canonicalIP(Refang(value), opts)
+	case TypeDomain:
+		out, err = canonicalDomain(Refang(value))
+	case TypeURL:
+		out, err = canonicalURL(Refang(value), opts)
+	case TypeEmail:
+		out, err = canonicalEmail(Refang(value))
+	case TypeHash:
+		out, sub, err = canonicalHash(value)
+	case TypeCVE:
+		out = cve.Normalize(value)
+		if !cve.Valid(out) {
+			return fail(ReasonInvalidCVE, "expected CVE-YYYY-NNNN")
+		}
+	default:
+		return fail(ReasonUnsupportedType, "unsupported indicator type")
+	}
+	if err != nil {
+		return fail(err.Reason, err.Message)
+	}
+	return Indicator{Type: indicatorType, Value: out, Subtype: sub}, nil
+}
+
+// Detect guesses the type of a bare (possibly defanged) value, for inputs that do not state it.
+// The guess is only a routing hint; Canonicalize still validates the value.
+func Detect(value string) string {
+	v := Refang(strings.TrimSpace(value))
+	switch {
+	case cve.Valid(cve.Normalize(v)):
+		return TypeCVE
+	case strings.Contains(v, "://"):
+		return TypeURL
+	case strings.Contains(v, "@"):
+		return TypeEmail
+	}
+	if _, err := netip.ParseAddr(strings.Trim(v, "[]")); err == nil {
+		return TypeIP
+	}
+	if _, _, err := canonicalHash(v); err == nil {
+		return TypeHash
+	}
+	if strings.Contains(v, "/") {
+		return TypeURL
+	}
+	return TypeDomain
+}
+
+// refangs undoes the usual defanging of IOCs shared in reports (hxxp://, [.], [at], ...).
+var refangs = strings.NewReplacer(
+	"[.]", ".", "(.)", ".", "{.}", ".", "[dot]", ".", "(dot)", ".",
+	"[:]", ":", "[://]", "://",
+	"[@]", "@", "[at]", "@", "(at)", "@",
+	"[/]", "/",
+)
+
+var defangedScheme = regexp.MustCompile(`(?i)^(hxxps?|fxp)(\[?:\]?//)`)
+
+// Refang restores a defanged indicator, e.g. "hxxps://evil[.]example/x" -> "https://evil.example/x".
+func Refang(s string) string {
+	s = refangs.Replace(s)
+	return defangedScheme.ReplaceAllStringFunc(s, func(m string) string {
+		scheme, _, _ := strings.Cut(strings.ToLower(m), ":")
+		scheme = strings.TrimSuffix(scheme, "[")
+		switch scheme {
+		case "hxxps":
+			return "https://"
+		case "fxp":
+			return "ftp://"
+		default:
+			return "http://"
+		}
+	})
+}
+
+// reserved are special-purpose ranges not covered by netip's Is* methods (RFC 6890 and friends).
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b:1::/48"),
+	netip.MustParsePrefix("100::/64"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// IsPrivate reports whether addr is private, loopback, link-local, multicast, unspecified or
+// otherwise reserved, i.e. not a meaningful public indicator.
+func IsPrivate(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified() {
+		return true
+	}
+	for _, p := range reserved {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func canonicalIP(s string, opts Options) (string, *Error) {
+	s = strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return "", &Error{Reason: ReasonInvalidIP, Message: "not an IPv4 or IPv6 address"}
+	}
+	// Zones (fe80::1%eth0) are local to the sender; IPv4-mapped IPv6 is reported as IPv4.
+	addr = addr.WithZone("").Unmap()
+	if !opts.AllowPrivate && IsPrivate(addr) {
+		return "", &Error{Reason: ReasonPrivateIP, Message: "private or reserved address"}
+	}
+	return addr.String(), nil
+}
+
+func canonicalDomain(s string) (string, *Error) {
+	s = strings.TrimSuffix(strings.ToLower(s), ".")
+	if _, err := netip.ParseAddr(s); err == nil {
+		return "", &Error{Reason: ReasonInvalidDomain, Message: "an IP address is not a domain; use type ip"}
+	}
+	ascii, err := idna.Lookup.ToASCII(s)
+	if err != nil {
+		return "", &Error{Reason: ReasonInvalidDomain, Message: "not a valid (IDN) domain name"}
+	}
+	if msg := checkHostname(ascii); msg != "" {
+		return "", &Error{Reason: ReasonInvalidDomain, Message: msg}
+	}
+	return ascii, nil
+}
+
+// checkHostname validates an ASCII (punycode) hostname; it returns a message when invalid.
+func checkHostname(h string) string {
+	if len(h) > 253 {
+		return "longer than 253 characters"
+	}
+	labels := strings.Split(h, ".")
+	if len(labels) < 2 {
+		return "expected at least two labels (e.g. example.com)"
+	}
+	for _, l := range labels {
+		if l == "" || len(l) > 63 {
+			return "labels must be 1-63 characters"
+		}
+		if l[0] == '-' || l[len(l)-1] == '-' {
+			return "labels must not start or end with a hyphen"
+		}
+		for _, r := range l {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+				return fmt.Sprintf("invalid character %q", r)
+			}
+		}
+	}
+	if _, err := strconv.Atoi(labels[len(labels)-1]); err == nil {
+		return "top-level label must not be numeric"
+	}
+	return ""
+}
+
+var defaultPorts = map[string]string{"http": "80", "https": "443", "ftp": "21"}
+
+func canonicalURL(s string, opts Options) (string, *Error) {
+	if !strings.Contains(s, "://") {
+		s = "http://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", &Error{Reason: ReasonInvalidURL, Message: "cannot be parsed as a URL"}
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if _, ok := defaultPorts[u.Scheme]; !ok {
+		return "", &Error{Reason: ReasonInvalidURL, Message: "scheme must be http, https or ftp"}
+	}
+	host := u.Hostname()
+	if host == "" {
+		return "", &Error{Reason: ReasonInvalidURL, Message: "missing host"}
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		if !opts.AllowPrivate && IsPrivate(addr) {
+			return "", &Error{Reason: ReasonPrivateIP, Message: "URL host is a private or reserved address"}
+		}
+		host = addr.WithZone("").Unmap().String()
+		if addr.Is6() && !addr.Is4In6() {
+			host = "[" + host + "]"
+		}
+	} else {
+		h, e := canonicalDomain(host)
+		if e != nil {
+			return "", &Error{Reason: ReasonInvalidURL, Message: "host: " + e.Message}
+		}
+		host = h
+	}
+	if port := u.Port(); port != "" && port != defaultPorts[u.Scheme] {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment = ""
+	u.RawFragment = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String(), nil
+}
+
+func canonicalEmail(s string) (string, *Error) {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s || addr.Name != "" {
+		return "", &Error{Reason: ReasonInvalidEmail, Message: "expected a bare address like user@example.com"}
+	}
+	local, domain, _ := strings.Cut(strings.ToLower(addr.Address), "@")
+	d, e := canonicalDomain(domain)
+	if e != nil {
+		return "", &Error{Reason: ReasonInvalidEmail, Message: "domain: " + e.Message}
+	}
+	return local + "@" + d, nil
+}
+
+var (
+	hexPattern    = regexp.MustCompile(`^[0-9a-fA-F]+$`)
+	ssdeepPattern = regexp.MustCompile(`^\d+:[0-9A-Za-z/+]+:[0-9A-Za-z/+]+$`)
+	tlshPattern   = regexp.MustCompile(`^(?i:t1)?[0-9a-fA-F]{70}$`)
+)
+
+var hexLengths = map[int]string{32: HashMD5, 40: HashSHA1, 64: HashSHA256, 128: HashSHA512}
+
+// canonicalHash detects the algorithm from the value's shape. Hex digests are lowercased; TLSH
+// is upper-cased with its T1 version prefix; SSDEEP is case-sensitive and kept as is.
+func canonicalHash(s string) (string, string, *Error) {
+	switch {
+	case ssdeepPattern.MatchString(s):
+		return s, HashSSDEEP, nil
+	case tlshPattern.MatchString(s):
+		s = strings.ToUpper(s)
+		if !strings.HasPrefix(s, "T1") {
+			s = "T1" + s
+		}
+		return s, HashTLSH, nil
+	case hexPattern.MatchString(s):
+		if sub, ok := hexLengths[len(s)]; ok {
+			return strings.ToLower(s), sub, nil
+		}
+	}
+	return "", "", &Error{Reason: ReasonInvalidHash, Message: "not an MD5, SHA-1, SHA-256, SHA-512, SSDEEP or TLSH hash"}
+}
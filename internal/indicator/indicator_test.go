@@ -0,0 +1,86 @@
+package indicator
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		typ, in, want, sub string
+	}{
+		{TypeIP, " 8.8.8.8 ", "8.8.8.8", ""},
+		{TypeIP, "8.8.8[.]8", "8.8.8.8", ""},
+		{TypeIP, "2001:4860:4860:0:0:0:0:8888", "2001:4860:4860::8888", ""},
+		{TypeIP, "2606:4700::1111%eth0", "2606:4700::1111", ""},
+		{TypeIP, "::ffff:1.1.1.1", "1.1.1.1", ""},
+		{TypeDomain, "Example.COM.", "example.com", ""},
+		{TypeDomain, "evil[.]example", "evil.example", ""},
+		{TypeDomain, "bücher.example", "xn--bcher-kva.example", ""},
+		{TypeURL, "hxxps://Evil[.]Example:443/a?b=1#frag", "https://evil.example/a?b=1", ""},
+		{TypeURL, "example.com", "http://example.com/", ""},
+		{TypeURL, "http://[2606:4700::1111]:8080/x", "http://[2606:4700::1111]:8080/x", ""},
+		{TypeEmail, "Alice[at]Example.COM", "alice@example.com", ""},
+		{TypeHash, "D41D8CD98F00B204E9800998ECF8427E", "d41d8cd98f00b204e9800998ecf8427e", HashMD5},
+		{TypeHash, "da39a3ee5e6b4b0d3255bfef95601890afd80709", "da39a3ee5e6b4b0d3255bfef95601890afd80709", HashSHA1},
+		{TypeHash, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", HashSHA256},
+		{TypeHash, "3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C", "3:AXGBicFlgVNhBGcL6wCrFQEv:AXGHsNhxLsr2C", HashSSDEEP},
+		{TypeHash, "t1a3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855a1b2c3", "T1A3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855A1B2C3", HashTLSH},
+		{TypeCVE, "cve-2024-3094", "CVE-2024-3094", ""},
+	}
+	for _, tt := range tests {
+		got, err := Canonicalize(tt.typ, tt.in, Options{})
+		if assert.NoError(t, err, tt.in) {
+			assert.Equal(t, tt.want, got.Value, tt.in)
+			assert.Equal(t, tt.sub, got.Subtype, tt.in)
+		}
+	}
+}
+
+func TestCanonicalize_Rejects(t *testing.T) {
+	tests := []struct {
+		typ, in, reason string
+	}{
+		{TypeIP, "example.com", ReasonInvalidIP},
+		{TypeIP, "10.0.0.1", ReasonPrivateIP},
+		{TypeIP, "127.0.0.1", ReasonPrivateIP},
+		{TypeIP, "fe80::1%eth0", ReasonPrivateIP},
+		{TypeIP, "192.0.2.1", ReasonPrivateIP},
+		{TypeDomain, "8.8.8.8", ReasonInvalidDomain},
+		{TypeDomain, "localhost", ReasonInvalidDomain},
+		{TypeDomain, "-bad.example", ReasonInvalidDomain},
+		{TypeURL, "javascript:alert(1)", ReasonInvalidURL},
+		{TypeURL, "http://192.168.1.1/admin", ReasonPrivateIP},
+		{TypeEmail, "Alice <alice@example.com>", ReasonInvalidEmail},
+		{TypeHash, "xyz", ReasonInvalidHash},
+		{TypeHash, "d41d8cd98f00b204e9800998ecf8427", ReasonInvalidHash},
+		{TypeCVE, "CVE-2024-1", ReasonInvalidCVE},
+		{"asn", "AS15169", ReasonUnsupportedType},
+	}
+	for _, tt := range tests {
+		_, err := Canonicalize(tt.typ, tt.in, Options{})
+		var ie *Error
+		if assert.True(t, errors.As(err, &ie), tt.in) {
+			assert.Equal(t, tt.reason, ie.Reason, tt.in)
+			assert.Equal(t, tt.in, ie.Value)
+		}
+		assert.ErrorIs(t, err, ErrInvalid)
+	}
+
+	got, err := Canonicalize(TypeIP, "10.0.0.1", Options{AllowPrivate: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", got.Value)
+}
+
+func TestDetect(t *testing.T) {
+	assert.Equal(t, TypeIP, Detect("8.8.8[.]8"))
+	assert.Equal(t, TypeIP, Detect("2001:db8::1"))
+	assert.Equal(t, TypeURL, Detect("hxxp://evil.example/x"))
+	assert.Equal(t, TypeURL, Detect("evil.example/payload.exe"))
+	assert.Equal(t, TypeEmail, Detect("alice@example.com"))
+	assert.Equal(t, TypeHash, Detect("d41d8cd98f00b204e9800998ecf8427e"))
+	assert.Equal(t, TypeCVE, Detect("cve-2024-3094"))
+	assert.Equal(t, TypeDomain, Detect("example.com"))
+}
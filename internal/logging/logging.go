@@ -0,0 +1,112 @@
+// Package logging configures log/slog: JSON lines on stderr, levels per component, request and
+// trace IDs taken from the context, and redaction of indicators and credentials in every record.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+
+	"hermes/internal/config"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// root is the handler every component logger writes to; Setup replaces it.
+var root atomic.Pointer[settings]
+
+type settings struct {
+	handler slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+}
+
+func init() {
+	root.Store(&settings{handler: newRedactingHandler(slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))})
+}
+
+// Setup applies LOG_LEVEL and LOG_LEVELS and makes the redacting JSON logger the default, so
+// the standard log package and slog's top-level functions go through it too.
+func Setup(cfg *config.Config) error {
+	var level slog.Level
+	if cfg.LogLevel != "" {
+		if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+			return err
+		}
+	}
+	cur := root.Load()
+	root.Store(&settings{handler: cur.handler, level: level, levels: cfg.LogLevels})
+	slog.SetDefault(For(""))
+	return nil
+}
+
+// For returns the logger of a component (e.g. "http", "service", "jobs"), logging at the
+// component's level from LOG_LEVELS, or LOG_LEVEL. Loggers may be created before Setup.
+func For(component string) *slog.Logger {
+	return slog.New(&componentHandler{component: component})
+}
+
+type requestIDKey struct{}
+
+// WithRequestID returns a context whose log records carry request_id.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the ID attached by WithRequestID, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// componentHandler resolves the root handler and level when a record is logged, so package-level
+// loggers follow Setup.
+type componentHandler struct {
+	component string
+	// with replays WithAttrs and WithGroup calls on the root handler.
+	with []func(slog.Handler) slog.Handler
+}
+
+func (h *componentHandler) level() slog.Level {
+	s := root.Load()
+	if l, ok := s.levels[h.component]; ok {
+		return l
+	}
+	return s.level
+}
+
+func (h *componentHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level()
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	next := root.Load().handler
+	if h.component != "" {
+		next = next.WithAttrs([]slog.Attr{slog.String("component", h.component)})
+	}
+	for _, f := range h.with {
+		next = f(next)
+	}
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+	return next.Handle(ctx, r)
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.plus(func(next slog.Handler) slog.Handler { return next.WithAttrs(attrs) })
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return h.plus(func(next slog.Handler) slog.Handler { return next.WithGroup(name) })
+}
+
+func (h *componentHandler) plus(f func(slog.Handler) slog.Handler) *componentHandler {
+	with := make([]func(slog.Handler) slog.Handler, len(h.with), len(h.with)+1)
+	copy(with, h.with)
+	return &componentHandler{component: h.component, with: append(with, f)}
+}
@@ -0,0 +1,48 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func capture(t *testing.T, level slog.Level, levels map[string]slog.Level) *bytes.Buffer {
+	var buf bytes.Buffer
+	prev := root.Load()
+	root.Store(&settings{handler: newRedactingHandler(slog.NewJSONHandler(&buf, nil)), level: level, levels: levels})
+	t.Cleanup(func() { root.Store(prev) })
+	return &buf
+}
+
+func TestFor_RedactsAndAddsRequestID(t *testing.T) {
+	buf := capture(t, slog.LevelInfo, nil)
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	For("service").With("provider", "malshare").InfoContext(ctx, "lookup failed",
+		"indicator_value", "203.0.113.77",
+		"error", errors.New(`Get "https://malshare.com/api.php?api_key=abc123": EOF`))
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	assert.Equal(t, "service", line["component"])
+	assert.Equal(t, "malshare", line["provider"])
+	assert.Equal(t, "req-1", line["request_id"])
+	assert.Equal(t, "203.***", line["indicator_value"])
+	assert.Equal(t, `Get "https://malshare.com/api.php?api_key=REDACTED": EOF`, line["error"])
+}
+
+func TestFor_ComponentLevels(t *testing.T) {
+	buf := capture(t, slog.LevelWarn, map[string]slog.Level{"jobs": slog.LevelDebug})
+
+	For("http").Info("dropped")
+	For("jobs").Debug("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "kept")
+}
@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"hermes/internal/redact"
+)
+
+// redactingHandler shortens values under redact.SensitiveKeys and strips credentials from the
+// message and every string or error attribute before records reach next.
+type redactingHandler struct {
+	next slog.Handler
+}
+
+func newRedactingHandler(next slog.Handler) slog.Handler {
+	return &redactingHandler{next: next}
+}
+
+func (h *redactingHandler) Enabled(ctx context.Context, l slog.Level) bool {
+	return h.next.Enabled(ctx, l)
+}
+
+func (h *redactingHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, redact.Credentials(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *redactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = redactAttr(a)
+	}
+	return &redactingHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactingHandler) WithGroup(name string) slog.Handler {
+	return &redactingHandler{next: h.next.WithGroup(name)}
+}
+
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, sub := range attrs {
+			redacted[i] = redactAttr(sub)
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(redacted...)}
+	case slog.KindString:
+		if redact.SensitiveKeys[a.Key] {
+			return slog.String(a.Key, redact.Value(v.String()))
+		}
+		return slog.String(a.Key, redact.Credentials(v.String()))
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			return slog.String(a.Key, redact.Credentials(x.Error()))
+		case []string:
+			if redact.SensitiveKeys[a.Key] {
+				out := make([]string, len(x))
+				for i, s := range x {
+					out[i] = redact.Value(s)
+				}
+				return slog.Any(a.Key, out)
+			}
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"hermes/internal/providerapi"
+)
+
+// Adapter wraps a providerapi.Adapter to record its calls: count, latency and error class per
+// provider. Async adapters keep their Submit, Poll and Collect, which are recorded the same way.
+type Adapter struct {
+	providerapi.Adapter
+}
+
+// Wrap returns a, instrumented.
+func Wrap(a providerapi.Adapter) *Adapter {
+	return &Adapter{Adapter: a}
+}
+
+// Lookup implements providerapi.Adapter.
+func (a *Adapter) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	start := time.Now()
+	res, err := a.Adapter.Lookup(ctx, indicatorType, value)
+	a.observe("lookup", res, err, start)
+	return res, err
+}
+
+// Submit implements providerapi.AsyncAdapter for adapters underneath that do.
+func (a *Adapter) Submit(ctx context.Context, indicatorType string, value string) (*providerapi.Job, providerapi.Result, error) {
+	start := time.Now()
+	job, res, err := providerapi.Submit(ctx, a.Adapter, indicatorType, value)
+	observed := res
+	observed.Pending = observed.Pending || job != nil
+	a.observe("submit", observed, err, start)
+	return job, res, err
+}
+
+// Poll implements providerapi.AsyncAdapter.
+func (a *Adapter) Poll(ctx context.Context, job providerapi.Job) (providerapi.JobStatus, error) {
+	start := time.Now()
+	st, err := providerapi.Poll(ctx, a.Adapter, job)
+	res := providerapi.Result{Success: st.Done, Pending: !st.Done && st.Failed == "", Error: st.Failed}
+	a.observe("poll", res, err, start)
+	return st, err
+}
+
+// Collect implements providerapi.AsyncAdapter.
+func (a *Adapter) Collect(ctx context.Context, job providerapi.Job) (providerapi.Result, error) {
+	start := time.Now()
+	res, err := providerapi.Collect(ctx, a.Adapter, job)
+	a.observe("collect", res, err, start)
+	return res, err
+}
+
+// Unwrap returns the wrapped adapter.
+func (a *Adapter) Unwrap() providerapi.Adapter { return a.Adapter }
+
+func (a *Adapter) observe(call string, res providerapi.Result, err error, start time.Time) {
+	class := ErrorClass(res, err)
+	outcome := "ok"
+	switch {
+	case class != "":
+		outcome = "error"
+	case res.Pending:
+		outcome = "pending"
+	}
+	observeCall(a.Code(), call, outcome, class, time.Since(start))
+}
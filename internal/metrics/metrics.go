@@ -0,0 +1,119 @@
+// Package metrics defines the Prometheus metrics served on /metrics: HTTP requests, provider
+// calls (through Wrap), cache lookups and rate-limiter rejections.
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"hermes/internal/providerapi"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "hermes_http_requests_total",
+		Help: "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hermes_http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	providerCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "hermes_provider_calls_total",
+		Help: "Provider calls by provider, call (lookup, submit, poll, collect) and outcome (ok, pending, error).",
+	}, []string{"provider", "call", "outcome"})
+	providerDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "hermes_provider_call_duration_seconds",
+		Help:    "Provider call latency, including retries, by provider and call.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+	}, []string{"provider", "call"})
+	providerErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "hermes_provider_errors_total",
+		Help: "Failed provider calls by provider and class (timeout, canceled, http_<status>, decode, not_configured, unsupported, rate_limited, transport, other).",
+	}, []string{"provider", "class"})
+	rateLimitRejections = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "hermes_ratelimit_rejections_total",
+		Help: "Provider calls refused by the local rate limiter.",
+	}, []string{"provider"})
+
+	cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "hermes_cache_lookups_total",
+		Help: "Result cache lookups by provider and result (hit, miss).",
+	}, []string{"provider", "result"})
+)
+
+// ObserveHTTP records one HTTP request; route is the matched route pattern.
+func ObserveHTTP(method, route string, status int, elapsed time.Duration) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	httpDuration.WithLabelValues(method, route).Observe(elapsed.Seconds())
+}
+
+// ObserveCache records whether a provider's result was served from the cache.
+func ObserveCache(provider string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(provider, result).Inc()
+}
+
+// observeCall records one provider call; class is "" for calls that did not fail. Polls of
+// async jobs are left out of Recent, which describes lookups.
+func observeCall(provider, call, outcome, class string, elapsed time.Duration) {
+	providerCalls.WithLabelValues(provider, call, outcome).Inc()
+	if call != "poll" {
+		observeRecent(provider, class == "", elapsed)
+	}
+	providerDuration.WithLabelValues(provider, call).Observe(elapsed.Seconds())
+	if class != "" {
+		providerErrors.WithLabelValues(provider, class).Inc()
+	}
+	if class == "rate_limited" {
+		rateLimitRejections.WithLabelValues(provider).Inc()
+	}
+}
+
+var httpStatusError = regexp.MustCompile(`^HTTP (\d{3})\b`)
+
+// ErrorClass classifies a failed provider call by its error and result message; it returns ""
+// for successful and pending results.
+func ErrorClass(res providerapi.Result, err error) string {
+	var netErr net.Error
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == nil && (res.Success || res.Pending):
+		return ""
+	case errors.Is(err, providerapi.ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, context.DeadlineExceeded) || errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF):
+		return "decode"
+	case err != nil && netErr != nil:
+		return "transport"
+	case res.Error == "not configured":
+		return "not_configured"
+	}
+	if m := httpStatusError.FindStringSubmatch(res.Error); m != nil {
+		return "http_" + m[1]
+	}
+	if strings.HasPrefix(res.Error, "unsupported") {
+		return "unsupported"
+	}
+	return "other"
+}
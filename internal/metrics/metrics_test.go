@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"hermes/internal/providerapi"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubAdapter struct {
+	res providerapi.Result
+	err error
+}
+
+func (s stubAdapter) Code() string             { return "stub" }
+func (s stubAdapter) SupportedTypes() []string { return []string{"ip"} }
+func (s stubAdapter) Lookup(context.Context, string, string) (providerapi.Result, error) {
+	return s.res, s.err
+}
+func (s stubAdapter) Assess(map[string]interface{}) providerapi.Assessment {
+	return providerapi.Assessment{Verdict: providerapi.VerdictUnknown}
+}
+
+func TestErrorClass(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	cases := []struct {
+		res  providerapi.Result
+		err  error
+		want string
+	}{
+		{providerapi.Result{Success: true}, nil, ""},
+		{providerapi.Result{Pending: true}, nil, ""},
+		{providerapi.Result{Error: "rate limited"}, providerapi.ErrRateLimited, "rate_limited"},
+		{providerapi.Result{}, fmt.Errorf("get: %w", context.DeadlineExceeded), "timeout"},
+		{providerapi.Result{}, syntaxErr, "decode"},
+		{providerapi.Result{Error: "not configured"}, nil, "not_configured"},
+		{providerapi.Result{Error: "HTTP 503"}, nil, "http_503"},
+		{providerapi.Result{Error: "unsupported type: email"}, nil, "unsupported"},
+		{providerapi.Result{}, errors.New("boom"), "other"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, ErrorClass(c.res, c.err), "%+v %v", c.res, c.err)
+	}
+}
+
+func TestWrap_CountsCalls(t *testing.T) {
+	ok := Wrap(stubAdapter{res: providerapi.Result{Success: true}})
+	failed := Wrap(stubAdapter{res: providerapi.Result{Error: "HTTP 429"}})
+	before := testutil.ToFloat64(providerCalls.WithLabelValues("stub", "lookup", "ok"))
+	beforeErr := testutil.ToFloat64(providerErrors.WithLabelValues("stub", "http_429"))
+
+	_, _ = ok.Lookup(context.Background(), "ip", "8.8.8.8")
+	_, _ = failed.Lookup(context.Background(), "ip", "8.8.8.8")
+
+	assert.Equal(t, before+1, testutil.ToFloat64(providerCalls.WithLabelValues("stub", "lookup", "ok")))
+	assert.Equal(t, beforeErr+1, testutil.ToFloat64(providerErrors.WithLabelValues("stub", "http_429")))
+	assert.False(t, providerapi.IsAsync(ok))
+	assert.Equal(t, "stub", providerapi.Base(ok).Code())
+}
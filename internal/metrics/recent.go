@@ -0,0 +1,83 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// recentCalls is how many of a provider's latest calls Recent summarizes.
+const recentCalls = 100
+
+// RecentStats summarizes a provider's latest calls (at most 100) since the process started.
+type RecentStats struct {
+	Calls int
+	// Succeeded counts calls that answered or were accepted as pending.
+	Succeeded  int
+	AvgLatency time.Duration
+	P95Latency time.Duration
+	LastCallAt time.Time
+}
+
+type call struct {
+	ok      bool
+	elapsed time.Duration
+}
+
+// window is a ring of a provider's latest calls.
+type window struct {
+	calls []call
+	next  int
+	last  time.Time
+}
+
+var (
+	recentMu sync.Mutex
+	recent   = make(map[string]*window)
+)
+
+func observeRecent(provider string, ok bool, elapsed time.Duration) {
+	recentMu.Lock()
+	defer recentMu.Unlock()
+	w := recent[provider]
+	if w == nil {
+		w = &window{calls: make([]call, 0, recentCalls)}
+		recent[provider] = w
+	}
+	c := call{ok: ok, elapsed: elapsed}
+	if len(w.calls) < recentCalls {
+		w.calls = append(w.calls, c)
+	} else {
+		w.calls[w.next] = c
+		w.next = (w.next + 1) % recentCalls
+	}
+	w.last = time.Now()
+}
+
+// Recent returns the provider's recent calls as recorded by Wrap; Calls is 0 when there were none.
+func Recent(provider string) RecentStats {
+	recentMu.Lock()
+	w := recent[provider]
+	if w == nil {
+		recentMu.Unlock()
+		return RecentStats{}
+	}
+	calls := append([]call(nil), w.calls...)
+	last := w.last
+	recentMu.Unlock()
+
+	out := RecentStats{Calls: len(calls), LastCallAt: last}
+	latencies := make([]time.Duration, len(calls))
+	var total time.Duration
+	for i, c := range calls {
+		if c.ok {
+			out.Succeeded++
+		}
+		latencies[i] = c.elapsed
+		total += c.elapsed
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	out.AvgLatency = total / time.Duration(len(calls))
+	out.P95Latency = latencies[(len(latencies)*95+99)/100-1]
+	return out
+}
@@ -5,17 +5,14 @@ import (
 	"encoding/json"
 	"io"
 
+	"hermes/internal/redact"
+
 	"github.com/gin-gonic/gin"
 )
 
-// SensitiveKeys are JSON keys whose values should be anonymized in logs.
-var SensitiveKeys = map[string]bool{
-	"indicator_value": true,
-	"url":             true,
-	"email":           true,
-	"ip":              true,
-	"ipAddress":       true,
-}
+// SensitiveKeys are JSON keys whose values should be anonymized in logs (shared with redact, so
+// keys added here apply to every log line).
+var SensitiveKeys = redact.SensitiveKeys
 
 // AnonymizeBody returns a copy of the request body with sensitive fields redacted for logging.
 func AnonymizeBody(c *gin.Context) string {
@@ -28,24 +25,30 @@ func AnonymizeBody(c *gin.Context) string {
 	}
 	c.Request.Body = io.NopCloser(bytes.NewReader(body))
 
-	var m map[string]interface{}
-	if err := json.Unmarshal(body, &m); err != nil {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
 		return "[not json]"
 	}
-	redact(m)
-	b, _ := json.Marshal(m)
+	b, _ := json.Marshal(redactFields(v, false))
 	return string(b)
 }
 
-func redact(m map[string]interface{}) {
-	for k, v := range m {
-		if SensitiveKeys[k] {
-			if s, ok := v.(string); ok {
-				m[k] = Anonymize(s)
-			}
+// redactFields anonymizes the strings under sensitive keys, also in arrays (e.g. a bulk lookup's
+// indicators); sensitive is set for values and array elements under such a key.
+func redactFields(v interface{}, sensitive bool) interface{} {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		for k, sub := range x {
+			x[k] = redactFields(sub, SensitiveKeys[k])
+		}
+	case []interface{}:
+		for i, sub := range x {
+			x[i] = redactFields(sub, sensitive)
 		}
-		if sub, ok := v.(map[string]interface{}); ok {
-			redact(sub)
+	case string:
+		if sensitive {
+			return Anonymize(x)
 		}
 	}
+	return v
 }
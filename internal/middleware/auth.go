@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"hermes/internal/auth"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticator resolves an API key to its principal.
+type Authenticator interface {
+	Authenticate(key string) (*auth.Principal, error)
+}
+
+// anonymousKey marks requests let through without a key because authentication is not required.
+const anonymousKey = "auth.anonymous"
+
+// Auth authenticates the API key in "Authorization: Bearer <key>", "X-API-Key" or, for clients
+// such as TAXII feeds that only speak HTTP Basic, the Basic password, and attaches the principal
+// to the request context. Missing or invalid keys get 401, unless required is false and
+// no key was sent (local development), in which case the request continues unauthenticated.
+func Auth(a Authenticator, required bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := apiKey(c.Request)
+		if key == "" && !required {
+			c.Set(anonymousKey, true)
+			c.Next()
+			return
+		}
+		p, err := a.Authenticate(key)
+		if err != nil || p == nil {
+			c.Header("WWW-Authenticate", `Bearer realm="hermes"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, vo.ErrorVO{Code: "UNAUTHORIZED", Message: "invalid or missing API key"})
+			return
+		}
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose principal lacks scope with 403.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !Allowed(c, scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, vo.ErrorVO{Code: "FORBIDDEN", Message: "API key lacks scope " + scope})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Allowed reports whether the request may use scope: its principal has it, or Auth let the
+// request through unauthenticated.
+func Allowed(c *gin.Context, scope string) bool {
+	if c.GetBool(anonymousKey) {
+		return true
+	}
+	return auth.FromContext(c.Request.Context()).Allows(scope)
+}
+
+func apiKey(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); len(h) > 7 && strings.EqualFold(h[:7], "Bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	if _, password, ok := r.BasicAuth(); ok {
+		return strings.TrimSpace(password)
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
@@ -1,35 +1,73 @@
 package middleware
 
 import (
-	"log"
+	"log/slog"
+	"strings"
 	"time"
 
+	"hermes/internal/logging"
+	"hermes/internal/redact"
+
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
+// RequestIDHeader carries the request ID assigned by Logger; a caller's own ID is kept.
+const RequestIDHeader = "X-Request-ID"
+
+var logger = logging.For("http")
+
 // Anonymize redacts sensitive parts of a value for logging (e.g. indicator_value).
 // Returns a short prefix + "***" if len > 8, else "***".
 func Anonymize(s string) string {
-	if s == "" {
-		return ""
-	}
-	if len(s) <= 8 {
-		return "***"
-	}
-	return s[:4] + "***"
+	return redact.Value(s)
 }
 
-// Logger logs each request with method, path, status, latency. Does not log body by default.
-func Logger() gin.HandlerFunc {
+// Logger logs each request with method, route, path, status, latency and request/trace IDs, and
+// echoes the request ID in X-Request-ID. Path parameters under SensitiveKeys are anonymized.
+// With logBodies, JSON request bodies are logged through AnonymizeBody.
+func Logger(logBodies bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
-		path := c.Request.URL.Path
-		clientIP := c.ClientIP()
-		method := c.Request.Method
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		var body string
+		if logBodies && c.Request.ContentLength != 0 && strings.Contains(c.ContentType(), "json") {
+			body = AnonymizeBody(c)
+		}
 		c.Next()
 
 		statusCode := c.Writer.Status()
-		latency := time.Since(start)
-		log.Printf("[%s] %d %s %s %v", method, statusCode, path, clientIP, latency)
+		attrs := []any{
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"path", loggedPath(c),
+			"status", statusCode,
+			"latency_ms", time.Since(start).Milliseconds(),
+			"client_ip", c.ClientIP(),
+		}
+		if body != "" {
+			attrs = append(attrs, "body", body)
+		}
+		level := slog.LevelInfo
+		if statusCode >= 500 {
+			level = slog.LevelError
+		}
+		logger.Log(c.Request.Context(), level, "request", attrs...)
+	}
+}
+
+// loggedPath is the request path with sensitive parameters (e.g. an indicator value) anonymized.
+func loggedPath(c *gin.Context) string {
+	path := c.Request.URL.Path
+	for _, p := range c.Params {
+		if SensitiveKeys[p.Key] && p.Value != "" {
+			path = strings.Replace(path, p.Value, Anonymize(p.Value), 1)
+		}
 	}
+	return path
 }
\ No newline at end of file
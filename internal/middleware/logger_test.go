@@ -1,7 +1,13 @@
 package middleware
 
 import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"github.com/gin-gonic/gin"
 )
 
 func TestAnonymize(t *testing.T) {
@@ -24,3 +30,18 @@ func TestAnonymize(t *testing.T) {
 		})
 	}
 }
+
+func TestAnonymizeBody(t *testing.T) {
+	body := `{"indicator_type":"ip","indicator_value":"203.0.113.77","indicators":["198.51.100.23",{"indicator_type":"url","indicator_value":"https://example.com/a"}]}`
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v1/lookup", strings.NewReader(body))
+
+	got := AnonymizeBody(c)
+	want := `{"indicator_type":"ip","indicator_value":"203.***","indicators":["198.***",{"indicator_type":"url","indicator_value":"http***"}]}`
+	if got != want {
+		t.Errorf("AnonymizeBody = %s, want %s", got, want)
+	}
+	if rest, _ := io.ReadAll(c.Request.Body); string(rest) != body {
+		t.Errorf("body not restored: %s", rest)
+	}
+}
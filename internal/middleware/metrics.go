@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"time"
+
+	"hermes/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records each request's count and latency by method, route pattern and status.
+// Requests matching no route are grouped under "unmatched" to keep label cardinality bounded.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.ObserveHTTP(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
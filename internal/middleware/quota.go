@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"hermes/internal/auth"
+	"hermes/internal/usage"
+	"hermes/internal/vo"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaEnforcer admits requests against a client's limits and records what they used.
+type QuotaEnforcer interface {
+	Admit(p *auth.Principal) (usage.Status, error)
+	Record(clientName string, requests, providerCalls int64) error
+}
+
+// Quota enforces the authenticated client's request rate and daily/monthly provider-call quotas.
+// Rejected requests get 429 with Retry-After; every response carries X-RateLimit-* headers for
+// the limits that apply. Provider calls made while handling the request are counted through a
+// usage.Meter in the request context. Must run after Auth; unauthenticated requests pass.
+func Quota(q QuotaEnforcer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := auth.FromContext(c.Request.Context())
+		if p == nil {
+			c.Next()
+			return
+		}
+		st, err := q.Admit(p)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, vo.ErrorVO{Code: "INTERNAL_ERROR", Message: err.Error()})
+			return
+		}
+		setRateLimitHeaders(c, st)
+		if st.Exceeded != "" {
+			c.Header("Retry-After", strconv.Itoa(seconds(st.RetryAfter)))
+			code, msg := "QUOTA_EXCEEDED", st.Exceeded+" provider-call quota exceeded"
+			if st.Exceeded == usage.ExceededRate {
+				code, msg = "RATE_LIMITED", "request rate limit exceeded"
+			}
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, vo.ErrorVO{Code: code, Message: msg})
+			return
+		}
+
+		meter := &usage.Meter{}
+		c.Request = c.Request.WithContext(usage.WithMeter(c.Request.Context(), meter))
+		c.Next()
+		if err := q.Record(p.ClientName, 1, meter.ProviderCalls()); err != nil {
+			logger.ErrorContext(c.Request.Context(), "record usage", "client", p.ClientName, "error", err)
+		}
+	}
+}
+
+func setRateLimitHeaders(c *gin.Context, st usage.Status) {
+	now := time.Now()
+	if st.Rate.Limit > 0 {
+		c.Header("X-RateLimit-Limit", strconv.FormatInt(st.Rate.Limit, 10))
+		c.Header("X-RateLimit-Remaining", strconv.FormatInt(st.Rate.Remaining(), 10))
+		c.Header("X-RateLimit-Reset", strconv.Itoa(seconds(st.Rate.Reset.Sub(now))))
+	}
+	if st.Daily.Limit > 0 {
+		c.Header("X-RateLimit-Limit-Day", strconv.FormatInt(st.Daily.Limit, 10))
+		c.Header("X-RateLimit-Remaining-Day", strconv.FormatInt(st.Daily.Remaining(), 10))
+	}
+	if st.Monthly.Limit > 0 {
+		c.Header("X-RateLimit-Limit-Month", strconv.FormatInt(st.Monthly.Limit, 10))
+		c.Header("X-RateLimit-Remaining-Month", strconv.FormatInt(st.Monthly.Remaining(), 10))
+	}
+}
+
+// seconds rounds d up to whole seconds (never negative).
+func seconds(d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(math.Ceil(d.Seconds()))
+}
@@ -1,7 +1,7 @@
 package middleware
 
 import (
-	"log"
+	"fmt"
 	"net/http"
 
 	"hermes/internal/vo"
@@ -14,7 +14,7 @@ func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("panic: %v", err)
+				logger.ErrorContext(c.Request.Context(), "panic", "error", fmt.Sprint(err))
 				c.AbortWithStatusJSON(http.StatusInternalServerError, vo.ErrorVO{
 					Code:    "INTERNAL_ERROR",
 					Message: "internal server error",
@@ -0,0 +1,109 @@
+package misp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"hermes/internal/providerapi"
+)
+
+// ErrNotConfigured is returned when pushing without a MISP URL and key.
+var ErrNotConfigured = errors.New("MISP is not configured")
+
+// pushTimeout bounds each call to MISP.
+const pushTimeout = 30 * time.Second
+
+// Client pushes events to a MISP instance through its REST API.
+type Client struct {
+	baseURL string
+	key     string
+	http    *http.Client
+}
+
+// NewClient returns a client for the MISP instance at baseURL authenticating with key, or nil
+// when either is empty.
+func NewClient(baseURL, key string) *Client {
+	if baseURL == "" || key == "" {
+		return nil
+	}
+	hc := providerapi.NewHTTPClient()
+	hc.Timeout = pushTimeout
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"), key: key, http: hc}
+}
+
+// Push creates the event, or replaces it when MISP already has its UUID (a re-push). It
+// returns the MISP event ID and whether the event was created.
+func (c *Client) Push(ctx context.Context, e *Envelope) (string, bool, error) {
+	if c == nil {
+		return "", false, ErrNotConfigured
+	}
+	status, _, err := c.do(ctx, http.MethodGet, "/events/view/"+e.Event.UUID, nil)
+	if err != nil {
+		return "", false, err
+	}
+	path, created := "/events/edit/"+e.Event.UUID, false
+	switch status {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusForbidden:
+		// MISP answers 403 "Invalid event" as well as 404 for unknown events.
+		path, created = "/events/add", true
+	default:
+		return "", false, fmt.Errorf("view event: HTTP %d", status)
+	}
+
+	status, body, err := c.do(ctx, http.MethodPost, path, e)
+	if err != nil {
+		return "", false, err
+	}
+	if status != http.StatusOK {
+		return "", false, fmt.Errorf("push event: HTTP %d%s", status, errorMessage(body))
+	}
+	var out Envelope
+	if err := json.Unmarshal(body, &out); err != nil {
+		return "", false, fmt.Errorf("decode response: %w", err)
+	}
+	return out.Event.ID, created, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in interface{}) (int, []byte, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return 0, nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", c.key)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	return resp.StatusCode, b, err
+}
+
+// errorMessage extracts ": <message>" from a MISP error response, or "".
+func errorMessage(body []byte) string {
+	var e struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &e) != nil || e.Message == "" {
+		return ""
+	}
+	return ": " + e.Message
+}
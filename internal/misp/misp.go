@@ -0,0 +1,259 @@
+// Package misp converts lookup results into MISP events and pushes them to a MISP instance.
+// Each lookup becomes an attribute tagged with the aggregate and per-provider verdicts and with
+// the galaxy clusters providers name; a bulk job becomes one event holding all its lookups.
+// UUIDs are derived from the request and job IDs, so a re-pushed event updates the same one.
+package misp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"hermes/internal/indicator"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+)
+
+// Event distribution levels.
+const (
+	DistributionOrganisation = 0
+	DistributionCommunity    = 1
+	DistributionConnected    = 2
+	DistributionAll          = 3
+	// distributionInherit makes attributes follow their event.
+	distributionInherit = 5
+)
+
+// Threat levels, from the worst verdict of an event's lookups.
+const (
+	threatHigh      = "1"
+	threatMedium    = "2"
+	threatLow       = "3"
+	threatUndefined = "4"
+)
+
+// analysisCompleted marks events whose lookups are done.
+const analysisCompleted = "2"
+
+// namespace derives event and attribute UUIDs.
+var namespace = uuid.MustParse("eb811dd7-5bae-4158-8600-a79bf3db6132")
+
+// Galaxy is a MISP galaxy cluster, referenced by its tag.
+type Galaxy struct {
+	// Type is the galaxy (e.g. "malpedia"), Value the cluster's value (e.g. "Emotet").
+	Type  string
+	Value string
+}
+
+// Tag is the galaxy tag MISP resolves to the cluster.
+func (g Galaxy) Tag() string {
+	return fmt.Sprintf("misp-galaxy:%s=%q", g.Type, g.Value)
+}
+
+// FamilyParser is implemented by adapters whose responses name malware families by their
+// Malpedia name; each family is tagged with its malpedia galaxy cluster (see Families).
+type FamilyParser interface {
+	MalwareFamilies(data map[string]interface{}) []string
+}
+
+// Families returns the malpedia galaxy clusters of malware families.
+func Families(names []string) []Galaxy {
+	out := make([]Galaxy, 0, len(names))
+	for _, n := range names {
+		out = append(out, Galaxy{Type: "malpedia", Value: n})
+	}
+	return out
+}
+
+// Envelope is the JSON MISP exports and accepts for an event.
+type Envelope struct {
+	Event Event `json:"Event"`
+}
+
+// Event is a MISP event.
+type Event struct {
+	ID            string      `json:"id,omitempty"`
+	UUID          string      `json:"uuid"`
+	Info          string      `json:"info"`
+	Date          string      `json:"date"`
+	Timestamp     string      `json:"timestamp"`
+	ThreatLevelID string      `json:"threat_level_id"`
+	Analysis      string      `json:"analysis"`
+	Distribution  string      `json:"distribution"`
+	Published     bool        `json:"published"`
+	Tag           []Tag       `json:"Tag,omitempty"`
+	Attribute     []Attribute `json:"Attribute"`
+}
+
+// Attribute is a MISP attribute.
+type Attribute struct {
+	UUID         string `json:"uuid"`
+	Type         string `json:"type"`
+	Category     string `json:"category"`
+	Value        string `json:"value"`
+	ToIDS        bool   `json:"to_ids"`
+	Comment      string `json:"comment,omitempty"`
+	Timestamp    string `json:"timestamp"`
+	Distribution string `json:"distribution"`
+	Tag          []Tag  `json:"Tag,omitempty"`
+}
+
+// Tag is a MISP tag.
+type Tag struct {
+	Name string `json:"name"`
+}
+
+// Options set the event fields not derived from lookups.
+type Options struct {
+	// Distribution is one of the Distribution constants.
+	Distribution int
+	Published    bool
+}
+
+// Lookup is a lookup to export with the galaxy clusters its providers named.
+type Lookup struct {
+	Response *vo.LookupResponseVO
+	Galaxies []Galaxy
+}
+
+// hashTypes maps indicator hash subtypes to MISP attribute types.
+var hashTypes = map[string]string{
+	indicator.HashMD5:    "md5",
+	indicator.HashSHA1:   "sha1",
+	indicator.HashSHA256: "sha256",
+	indicator.HashSHA512: "sha512",
+	indicator.HashSSDEEP: "ssdeep",
+	indicator.HashTLSH:   "tlsh",
+}
+
+// verdictRank orders verdicts from worst to unknown.
+var verdictRank = map[string]int{"malicious": 3, "suspicious": 2, "clean": 1}
+
+// NewEvent builds the event of one or more lookups. key identifies it (e.g. "lookup:<request_id>")
+// and info is its title. Lookups of unsupported types are skipped.
+func NewEvent(key, info string, lookups []Lookup, opts Options) *Envelope {
+	e := Event{
+		UUID:          uuid.NewSHA1(namespace, []byte("event:"+key)).String(),
+		Info:          info,
+		ThreatLevelID: threatUndefined,
+		Analysis:      analysisCompleted,
+		Distribution:  strconv.Itoa(opts.Distribution),
+		Published:     opts.Published,
+		Attribute:     []Attribute{},
+	}
+	var latest, modified time.Time
+	worst := ""
+	for _, l := range lookups {
+		a, ok := attribute(l)
+		if !ok {
+			continue
+		}
+		e.Attribute = append(e.Attribute, a)
+		if r := l.Response; r.CreatedAt.After(latest) {
+			latest = r.CreatedAt
+		}
+		if t := updatedAt(l.Response); t.After(modified) {
+			modified = t
+		}
+		if v := verdictOf(l.Response); verdictRank[v] > verdictRank[worst] {
+			worst = v
+		}
+	}
+	if latest.IsZero() {
+		latest, modified = time.Now(), time.Now()
+	}
+	e.Date = latest.UTC().Format("2006-01-02")
+	e.Timestamp = strconv.FormatInt(modified.Unix(), 10)
+	switch worst {
+	case "malicious":
+		e.ThreatLevelID = threatHigh
+	case "suspicious":
+		e.ThreatLevelID = threatMedium
+	case "clean":
+		e.ThreatLevelID = threatLow
+	}
+	if worst != "" {
+		e.Tag = []Tag{{Name: verdictTag("verdict", worst)}}
+	}
+	return &Envelope{Event: e}
+}
+
+// updatedAt is when the lookup last changed: MISP timestamps are modification times, and MISP
+// only takes an edited attribute whose timestamp moved.
+func updatedAt(r *vo.LookupResponseVO) time.Time {
+	if r.UpdatedAt.After(r.CreatedAt) {
+		return r.UpdatedAt
+	}
+	return r.CreatedAt
+}
+
+// attribute converts a lookup; ok is false for indicator types MISP has no attribute type for.
+func attribute(l Lookup) (Attribute, bool) {
+	r := l.Response
+	typ, category := "", "Network activity"
+	switch r.IndicatorType {
+	case indicator.TypeIP:
+		typ = "ip-dst"
+	case indicator.TypeDomain:
+		typ = "domain"
+	case indicator.TypeURL:
+		typ = "url"
+	case indicator.TypeEmail:
+		typ, category = "email-src", "Payload delivery"
+	case indicator.TypeHash:
+		typ, category = hashTypes[r.HashType], "Payload delivery"
+	case indicator.TypeCVE:
+		typ, category = "vulnerability", "External analysis"
+	}
+	if typ == "" {
+		return Attribute{}, false
+	}
+
+	verdict := verdictOf(r)
+	a := Attribute{
+		UUID:         uuid.NewSHA1(namespace, []byte("attribute:"+r.RequestID)).String(),
+		Type:         typ,
+		Category:     category,
+		Value:        r.IndicatorValue,
+		ToIDS:        verdict == "malicious" && typ != "vulnerability",
+		Timestamp:    strconv.FormatInt(updatedAt(r).Unix(), 10),
+		Distribution: strconv.Itoa(distributionInherit),
+	}
+	if r.Verdict != nil {
+		a.Comment = r.Verdict.Explanation
+		a.Tag = append(a.Tag, Tag{Name: verdictTag("verdict", verdict)})
+	}
+	codes := make([]string, 0, len(r.Results))
+	for code := range r.Results {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if res := r.Results[code]; res.Success && res.Assessment != nil && res.Assessment.Verdict != "unknown" {
+			a.Tag = append(a.Tag, Tag{Name: verdictTag(code, res.Assessment.Verdict)})
+		}
+	}
+	seen := make(map[string]bool)
+	for _, g := range l.Galaxies {
+		if t := g.Tag(); !seen[t] {
+			seen[t] = true
+			a.Tag = append(a.Tag, Tag{Name: t})
+		}
+	}
+	return a, true
+}
+
+// verdictTag is the machine tag hermes:<predicate>="<verdict>"; the predicate is "verdict" for
+// the aggregate or a provider code.
+func verdictTag(predicate, verdict string) string {
+	return fmt.Sprintf("hermes:%s=%q", predicate, verdict)
+}
+
+func verdictOf(r *vo.LookupResponseVO) string {
+	if r.Verdict == nil {
+		return ""
+	}
+	return r.Verdict.Verdict
+}
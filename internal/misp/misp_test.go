@@ -0,0 +1,125 @@
+package misp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"hermes/internal/vo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lookups() []Lookup {
+	created := time.Date(2025, 2, 20, 9, 0, 0, 0, time.UTC)
+	return []Lookup{
+		{
+			Response: &vo.LookupResponseVO{
+				RequestID:      "550e8400-e29b-41d4-a716-446655440000",
+				IndicatorType:  "hash",
+				IndicatorValue: "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f",
+				HashType:       "sha256",
+				CreatedAt:      created,
+				Verdict:        &vo.AggregateVerdictVO{Verdict: "malicious", Score: 90, Explanation: "2 of 2 providers flag it"},
+				Results: map[string]vo.ProviderResultVO{
+					"virustotal":    {Success: true, Assessment: &vo.AssessmentVO{Verdict: "malicious"}},
+					"malwarebazaar": {Success: true, Assessment: &vo.AssessmentVO{Verdict: "malicious"}},
+					"hybrid":        {Status: vo.StatusError, Error: "HTTP 500"},
+				},
+			},
+			Galaxies: []Galaxy{{Type: "malpedia", Value: "Emotet"}, {Type: "malpedia", Value: "Emotet"}},
+		},
+		{Response: &vo.LookupResponseVO{
+			RequestID:      "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
+			IndicatorType:  "cve",
+			IndicatorValue: "CVE-2021-44228",
+			CreatedAt:      created.Add(time.Hour),
+			Verdict:        &vo.AggregateVerdictVO{Verdict: "malicious"},
+		}},
+		{Response: &vo.LookupResponseVO{RequestID: "6ba7b811-9dad-11d1-80b4-00c04fd430c8", IndicatorType: "asn", IndicatorValue: "AS15169"}},
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	env := NewEvent("bulk:1", "Hermes bulk lookup", lookups(), Options{Distribution: DistributionCommunity})
+	e := env.Event
+	assert.Equal(t, NewEvent("bulk:1", "other", nil, Options{}).Event.UUID, e.UUID)
+	assert.Equal(t, "2025-02-20", e.Date)
+	assert.Equal(t, threatHigh, e.ThreatLevelID)
+	assert.Equal(t, "1", e.Distribution)
+	assert.Equal(t, []Tag{{Name: `hermes:verdict="malicious"`}}, e.Tag)
+
+	// The ASN has no MISP attribute type.
+	if assert.Len(t, e.Attribute, 2) {
+		hash, vuln := e.Attribute[0], e.Attribute[1]
+		assert.Equal(t, "sha256", hash.Type)
+		assert.Equal(t, "Payload delivery", hash.Category)
+		assert.True(t, hash.ToIDS)
+		assert.Equal(t, "2 of 2 providers flag it", hash.Comment)
+		assert.Equal(t, []Tag{
+			{Name: `hermes:verdict="malicious"`},
+			{Name: `hermes:malwarebazaar="malicious"`},
+			{Name: `hermes:virustotal="malicious"`},
+			{Name: `misp-galaxy:malpedia="Emotet"`},
+		}, hash.Tag)
+		assert.Equal(t, "vulnerability", vuln.Type)
+		assert.False(t, vuln.ToIDS)
+	}
+}
+
+// fakeMISP stands in for MISP's events API, keeping events by UUID.
+func fakeMISP(t *testing.T) *httptest.Server {
+	events := map[string]Event{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"name":"Authentication failed.","message":"Authentication failed."}`))
+			return
+		}
+		var in Envelope
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/events/view/"):
+			e, ok := events[strings.TrimPrefix(r.URL.Path, "/events/view/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(Envelope{Event: e})
+			return
+		case r.URL.Path == "/events/add":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+			in.Event.ID = "42"
+		case strings.HasPrefix(r.URL.Path, "/events/edit/"):
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&in))
+			in.Event.ID = events[in.Event.UUID].ID
+		}
+		events[in.Event.UUID] = in.Event
+		_ = json.NewEncoder(w).Encode(in)
+	}))
+}
+
+func TestClient_Push(t *testing.T) {
+	srv := fakeMISP(t)
+	defer srv.Close()
+	assert.Nil(t, NewClient("", "secret"))
+
+	env := NewEvent("lookup:1", "Hermes lookup", lookups()[:1], Options{})
+	c := NewClient(srv.URL+"/", "secret")
+	id, created, err := c.Push(context.Background(), env)
+	assert.NoError(t, err)
+	assert.Equal(t, "42", id)
+	assert.True(t, created)
+
+	// A re-push edits the event MISP already has.
+	id, created, err = c.Push(context.Background(), env)
+	assert.NoError(t, err)
+	assert.Equal(t, "42", id)
+	assert.False(t, created)
+
+	_, _, err = NewClient(srv.URL, "wrong").Push(context.Background(), env)
+	assert.Error(t, err)
+}
@@ -0,0 +1,38 @@
+package model
+
+import "time"
+
+// Blocklist is a named list of indicators of one type, selected from each provider's latest
+// stored result for the indicator that is at most MaxAgeHours old (0 = any age): aggregated,
+// their verdict is one of Verdicts with at least MinScore, and at least MinProviders of them
+// have a verdict among Verdicts.
+// ContentHash and ModifiedAt track when its entries last changed.
+type Blocklist struct {
+	ID            int64      `gorm:"primaryKey;autoIncrement"`
+	Name          string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Description   string     `gorm:"type:text"`
+	IndicatorType string     `gorm:"type:varchar(32);not null"`
+	Format        string     `gorm:"type:varchar(16);not null"`
+	Verdicts      StringList `gorm:"type:jsonb"`
+	MinScore      int        `gorm:"type:smallint;not null;default:0"`
+	MaxAgeHours   int        `gorm:"not null;default:0"`
+	MinProviders  int        `gorm:"not null;default:0"`
+	ContentHash   string     `gorm:"type:varchar(64)"`
+	ModifiedAt    *time.Time
+	CreatedAt     time.Time `gorm:"not null;autoCreateTime"`
+	UpdatedAt     time.Time `gorm:"not null;autoUpdateTime"`
+}
+
+func (Blocklist) TableName() string { return "blocklists" }
+
+// AllowlistEntry keeps an indicator off every blocklist. IP entries may be CIDR blocks and
+// domain entries cover subdomains.
+type AllowlistEntry struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement"`
+	IndicatorType string    `gorm:"type:varchar(32);not null;uniqueIndex:idx_allowlist_entries_unique,priority:1"`
+	Value         string    `gorm:"type:varchar(2048);not null;uniqueIndex:idx_allowlist_entries_unique,priority:2"`
+	Comment       string    `gorm:"type:text"`
+	CreatedAt     time.Time `gorm:"not null;autoCreateTime"`
+}
+
+func (AllowlistEntry) TableName() string { return "allowlist_entries" }
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bulk job statuses.
+const (
+	BulkJobQueued    = "queued"
+	BulkJobRunning   = "running"
+	BulkJobCompleted = "completed"
+	BulkJobFailed    = "failed"
+)
+
+// Bulk job item statuses.
+const (
+	BulkItemPending = "pending"
+	BulkItemDone    = "done"
+	BulkItemInvalid = "invalid"
+	BulkItemError   = "error"
+)
+
+// BulkJob is one bulk lookup. Total counts the de-duplicated items; Completed and Failed count
+// finished ones, Duplicates the input lines dropped as repeats. Error says why a failed job
+// stopped.
+type BulkJob struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement"`
+	JobID      uuid.UUID  `gorm:"type:uuid;uniqueIndex;not null"`
+	Status     string     `gorm:"type:varchar(16);not null;default:queued;index"`
+	Providers  StringList `gorm:"type:jsonb"`
+	CacheMode  string     `gorm:"type:varchar(16)"`
+	Total      int        `gorm:"not null;default:0"`
+	Completed  int        `gorm:"not null;default:0"`
+	Failed     int        `gorm:"not null;default:0"`
+	Duplicates int        `gorm:"not null;default:0"`
+	UserID     *string    `gorm:"type:varchar(128)"`
+	Error      string     `gorm:"type:text"`
+	CreatedAt  time.Time  `gorm:"not null;autoCreateTime"`
+	UpdatedAt  time.Time  `gorm:"not null;autoUpdateTime"`
+	FinishedAt *time.Time
+}
+
+func (BulkJob) TableName() string { return "bulk_jobs" }
+
+// BulkJobItem is one unique indicator of a bulk job, in input order (Position).
+type BulkJobItem struct {
+	ID              int64      `gorm:"primaryKey;autoIncrement"`
+	BulkJobID       int64      `gorm:"not null;uniqueIndex:idx_bulk_job_items_unique,priority:1;index:idx_bulk_job_items_job_position,priority:1"`
+	Position        int        `gorm:"not null;index:idx_bulk_job_items_job_position,priority:2"`
+	IndicatorType   string     `gorm:"type:varchar(32);not null;uniqueIndex:idx_bulk_job_items_unique,priority:2"`
+	IndicatorValue  string     `gorm:"type:varchar(2048);not null;uniqueIndex:idx_bulk_job_items_unique,priority:3"`
+	Status          string     `gorm:"type:varchar(16);not null;default:pending"`
+	LookupRequestID *uuid.UUID `gorm:"type:uuid"`
+	Verdict         string     `gorm:"type:varchar(16)"`
+	Score           int        `gorm:"type:smallint"`
+	Error           string     `gorm:"type:text"`
+	UpdatedAt       time.Time  `gorm:"not null;autoUpdateTime"`
+}
+
+func (BulkJobItem) TableName() string { return "bulk_job_items" }
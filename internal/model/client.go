@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// Client is an API consumer; its Name is stored as user_id on the records it creates, so it is
+// never changed after creation.
+type Client struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement"`
+	Name      string    `gorm:"type:varchar(128);uniqueIndex;not null"`
+	CreatedAt time.Time `gorm:"not null;autoCreateTime"`
+	UpdatedAt time.Time `gorm:"not null;autoUpdateTime"`
+	APIKeys   []APIKey  `gorm:"foreignKey:ClientID"`
+	// Limit overrides; nil uses the configured default, 0 means unlimited.
+	RateLimitPerMin *int
+	DailyQuota      *int
+	MonthlyQuota    *int
+}
+
+func (Client) TableName() string { return "clients" }
+
+// APIKey is a client credential. Only the SHA-256 of the key is stored; KeyID is the public
+// identifier used to manage it.
+type APIKey struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement"`
+	ClientID   int64      `gorm:"not null;index"`
+	KeyID      string     `gorm:"type:varchar(16);uniqueIndex;not null"`
+	KeyHash    string     `gorm:"type:varchar(64);uniqueIndex;not null"`
+	Scopes     StringList `gorm:"type:jsonb;not null"`
+	ExpiresAt  *time.Time
+	RevokedAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time `gorm:"not null;autoCreateTime"`
+	Client     *Client   `gorm:"foreignKey:ClientID"`
+}
+
+func (APIKey) TableName() string { return "api_keys" }
+
+// Active reports whether the key is neither revoked nor expired at now.
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
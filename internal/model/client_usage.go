@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// ClientUsage counts a client's requests and provider calls for one UTC day.
+type ClientUsage struct {
+	ClientName    string    `gorm:"type:varchar(128);primaryKey"`
+	Day           time.Time `gorm:"type:date;primaryKey"`
+	Requests      int64     `gorm:"not null;default:0"`
+	ProviderCalls int64     `gorm:"not null;default:0"`
+	UpdatedAt     time.Time `gorm:"not null;autoUpdateTime"`
+}
+
+func (ClientUsage) TableName() string { return "client_usage" }
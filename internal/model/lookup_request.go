@@ -7,13 +7,19 @@ import (
 )
 
 // LookupRequest is one row per lookup; indicator_value may be hashed for anonymization.
+// IndicatorHash is the sha256 of the normalized value and backs the read-through cache index;
+// Verdict/Score are the aggregate across providers; UpdatedAt is when they were last stored.
 type LookupRequest struct {
 	ID             int64     `gorm:"primaryKey;autoIncrement"`
 	RequestID      uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
-	IndicatorType  string    `gorm:"type:varchar(32);not null"`
+	IndicatorType  string    `gorm:"type:varchar(32);not null;index:idx_lookup_requests_indicator_type_hash,priority:1"`
 	IndicatorValue string    `gorm:"type:varchar(2048);not null"`
+	IndicatorHash  string    `gorm:"type:varchar(64);index:idx_lookup_requests_indicator_type_hash,priority:2"`
 	UserID         *string   `gorm:"type:varchar(128)"`
+	Verdict        string    `gorm:"type:varchar(16)"`
+	Score          int       `gorm:"type:smallint"`
 	CreatedAt      time.Time `gorm:"not null;autoCreateTime"`
+	UpdatedAt      time.Time `gorm:"not null;autoUpdateTime"`
 }
 
 func (LookupRequest) TableName() string { return "lookup_requests" }
@@ -29,14 +29,44 @@ func (j *JSONB) Scan(value interface{}) error {
 	return json.Unmarshal(b, j)
 }
 
-// LookupResult is cache/history per provider.
+// StringList is a []string stored as a jsonb array.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return nil, nil
+	}
+	return json.Marshal(l)
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	if value == nil {
+		*l = nil
+		return nil
+	}
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	}
+	return errors.New("invalid type for StringList")
+}
+
+// LookupResult is cache/history per provider. Verdict/Score/Tags/FirstSeen/LastSeen hold the
+// adapter's normalized assessment of RawResponse.
 type LookupResult struct {
-	ID               int64     `gorm:"primaryKey;autoIncrement"`
-	LookupRequestID  int64     `gorm:"not null;uniqueIndex:idx_lookup_request_provider"`
-	ProviderCode     string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_lookup_request_provider"`
-	RawResponse      JSONB     `gorm:"type:jsonb"`
-	CachedAt         time.Time `gorm:"not null;autoCreateTime"`
-	TTLSeconds       int       `gorm:"not null;default:3600"`
+	ID              int64      `gorm:"primaryKey;autoIncrement"`
+	LookupRequestID int64      `gorm:"not null;uniqueIndex:idx_lookup_request_provider"`
+	ProviderCode    string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_lookup_request_provider"`
+	RawResponse     JSONB      `gorm:"type:jsonb"`
+	CachedAt        time.Time  `gorm:"not null;autoCreateTime"`
+	TTLSeconds      int        `gorm:"not null;default:3600"`
+	Verdict         string     `gorm:"type:varchar(16)"`
+	Score           int        `gorm:"type:smallint"`
+	Tags            StringList `gorm:"type:jsonb"`
+	FirstSeen       *time.Time
+	LastSeen        *time.Time
 }
 
 func (LookupResult) TableName() string { return "lookup_results" }
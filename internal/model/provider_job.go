@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Provider job statuses.
+const (
+	ProviderJobPending = "pending"
+	ProviderJobDone    = "done"
+	ProviderJobFailed  = "failed"
+	ProviderJobExpired = "expired"
+)
+
+// ProviderJob is an asynchronous provider job (providerapi.AsyncAdapter) started by a lookup.
+// ExternalID and Data are the provider's job; NextPollAt schedules the next poll and doubles as
+// the lease of the worker polling it. Failures counts consecutive failed polls for backoff.
+type ProviderJob struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement"`
+	LookupRequestID int64     `gorm:"not null;uniqueIndex:idx_provider_jobs_request_provider"`
+	RequestID       uuid.UUID `gorm:"type:uuid;not null;index"`
+	ProviderCode    string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_provider_jobs_request_provider"`
+	ExternalID      string    `gorm:"type:varchar(2048);not null"`
+	Data            JSONB     `gorm:"type:jsonb"`
+	Status          string    `gorm:"type:varchar(16);not null;default:pending"`
+	Polls           int       `gorm:"not null;default:0"`
+	Failures        int       `gorm:"not null;default:0"`
+	NextPollAt      time.Time `gorm:"not null;index:idx_provider_jobs_due"`
+	ExpiresAt       *time.Time
+	Error           string    `gorm:"type:text"`
+	CreatedAt       time.Time `gorm:"not null;autoCreateTime"`
+	UpdatedAt       time.Time `gorm:"not null;autoUpdateTime"`
+	FinishedAt      *time.Time
+}
+
+func (ProviderJob) TableName() string { return "provider_jobs" }
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TAXIICollection is a curated TAXII collection; its objects are the STIX exports of the lookups
+// added to it. Alias, when set, can stand in for CollectionID in TAXII URLs.
+type TAXIICollection struct {
+	ID           int64     `gorm:"primaryKey;autoIncrement"`
+	CollectionID uuid.UUID `gorm:"type:uuid;uniqueIndex;not null"`
+	Alias        *string   `gorm:"type:varchar(64);uniqueIndex"`
+	Title        string    `gorm:"type:varchar(256);not null"`
+	Description  string    `gorm:"type:text"`
+	CreatedAt    time.Time `gorm:"not null;autoCreateTime"`
+}
+
+func (TAXIICollection) TableName() string { return "taxii_collections" }
+
+// TAXIICollectionLookup adds a lookup to a curated collection; DateAdded is the TAXII date_added
+// of the lookup's objects.
+type TAXIICollectionLookup struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement"`
+	CollectionID    int64     `gorm:"not null;uniqueIndex:idx_taxii_collection_lookups_unique,priority:1;index:idx_taxii_collection_lookups_added,priority:1"`
+	LookupRequestID int64     `gorm:"not null;uniqueIndex:idx_taxii_collection_lookups_unique,priority:2"`
+	DateAdded       time.Time `gorm:"not null;autoCreateTime;index:idx_taxii_collection_lookups_added,priority:2"`
+}
+
+func (TAXIICollectionLookup) TableName() string { return "taxii_collection_lookups" }
+
+// LookupObject records that the STIX export of a lookup contains an object, so TAXII match[id]
+// queries find the lookups serving an object without converting them.
+type LookupObject struct {
+	ObjectID        string `gorm:"type:varchar(128);primaryKey"`
+	LookupRequestID int64  `gorm:"primaryKey;index:idx_lookup_objects_lookup_request"`
+}
+
+func (LookupObject) TableName() string { return "lookup_objects" }
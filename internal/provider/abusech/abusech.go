@@ -0,0 +1,71 @@
+// Package abusech holds the request plumbing shared by the abuse.ch adapters (MalwareBazaar,
+// URLhaus, ThreatFox, Feodo Tracker): every API takes the account's key in the Auth-Key header
+// and answers JSON.
+package abusech
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// NewFormRequest builds a form-encoded POST carrying apiKey. The body is replayable so the
+// provider retry policy can resend it.
+func NewFormRequest(ctx context.Context, endpoint, apiKey string, form url.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	setHeaders(req, apiKey)
+	return req, nil
+}
+
+// NewJSONRequest builds a JSON POST carrying apiKey.
+func NewJSONRequest(ctx context.Context, endpoint, apiKey string, payload interface{}) (*http.Request, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	setHeaders(req, apiKey)
+	return req, nil
+}
+
+// NewGetRequest builds a GET carrying apiKey when one is configured (downloads are also served
+// anonymously).
+func NewGetRequest(ctx context.Context, endpoint, apiKey string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	setHeaders(req, apiKey)
+	return req, nil
+}
+
+// Do sends req and decodes the JSON response into out, returning the HTTP status code.
+func Do(client *http.Client, req *http.Request, out interface{}) (int, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return resp.StatusCode, err
+	}
+	return resp.StatusCode, nil
+}
+
+func setHeaders(req *http.Request, apiKey string) {
+	if apiKey != "" {
+		req.Header.Set("Auth-Key", apiKey)
+	}
+	req.Header.Set("Accept", "application/json")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
 
 	"hermes/internal/providerapi"
 )
@@ -22,13 +23,16 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "abuseipdb" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"ip"}
@@ -78,3 +82,41 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: data}, nil
 }
+
+// Assess implements providerapi.Adapter. abuseConfidenceScore is already 0–100; whitelisted IPs are clean.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	score, ok := providerapi.Number(data, "abuseConfidenceScore")
+	if !ok {
+		return providerapi.Unknown()
+	}
+	a := providerapi.Assess(providerapi.VerdictForScore(int(score)), int(score))
+	if providerapi.Bool(data, "isWhitelisted") {
+		a = providerapi.Assess(providerapi.VerdictClean, 0)
+		a.Tags = append(a.Tags, "whitelisted")
+	}
+	if providerapi.Bool(data, "isTor") {
+		a.Tags = append(a.Tags, "tor")
+	}
+	if usage := providerapi.String(data, "usageType"); usage != "" {
+		a.Tags = append(a.Tags, usage)
+	}
+	a.LastSeen = providerapi.Time(data, "lastReportedAt")
+	return a
+}
+
+// ReportFields implements report.Fielder.
+func (c *Client) ReportFields() []string {
+	return []string{"confidence", "reports", "country"}
+}
+
+// ReportValues implements report.Fielder.
+func (c *Client) ReportValues(data map[string]interface{}) []string {
+	out := []string{"", "", providerapi.String(data, "countryCode")}
+	if n, ok := providerapi.Number(data, "abuseConfidenceScore"); ok {
+		out[0] = strconv.Itoa(int(n))
+	}
+	if n, ok := providerapi.Number(data, "totalReports"); ok {
+		out[1] = strconv.Itoa(int(n))
+	}
+	return out
+}
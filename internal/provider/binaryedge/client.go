@@ -22,13 +22,16 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "binaryedge" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"ip", "domain"}
@@ -98,3 +101,19 @@ func (c *Client) lookupDomain(ctx context.Context, domain string) (providerapi.R
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. BinaryEdge reports exposure (open ports, subdomains), not
+// reputation, so the verdict is unknown; open ports are kept as tags.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	a := providerapi.Unknown()
+	for _, e := range providerapi.List(data, "events") {
+		ev, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if port := providerapi.String(ev, "port"); port != "" {
+			a.Tags = append(a.Tags, "port:"+port)
+		}
+	}
+	return a
+}
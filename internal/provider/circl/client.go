@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"hermes/internal/cve"
 	"hermes/internal/providerapi"
 )
 
@@ -19,7 +21,7 @@ type Client struct {
 
 // NewClient creates a CIRCL CVE client.
 func NewClient() *Client {
-	return &Client{client: &http.Client{}}
+	return &Client{client: providerapi.NewHTTPClient()}
 }
 
 // Code implements providerapi.Adapter.
@@ -27,7 +29,7 @@ func (c *Client) Code() string { return "circl_cve" }
 
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
-	return []string{"hash"} // CVE-ID
+	return []string{"cve"}
 }
 
 // Lookup implements providerapi.Adapter. value should be a CVE-ID (e.g. CVE-2024-1234).
@@ -58,3 +60,118 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: success, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. A CVE record has no malicious/clean reading; only its
+// publication dates are normalized.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	a := providerapi.Unknown()
+	// CVE JSON 5 (vulnerability-lookup) with a fallback to the legacy cve-search layout.
+	a.FirstSeen = providerapi.Earliest(providerapi.Time(data, "cveMetadata", "datePublished"), providerapi.Time(data, "Published"))
+	a.LastSeen = providerapi.Latest(providerapi.Time(data, "cveMetadata", "dateUpdated"), providerapi.Time(data, "Modified"))
+	return a
+}
+
+// cvssKeys maps CVE JSON 5 metric keys to CVSS versions.
+var cvssKeys = []struct{ key, version string }{
+	{"cvssV4_0", "4.0"}, {"cvssV3_1", "3.1"}, {"cvssV3_0", "3.0"}, {"cvssV2_0", "2.0"},
+}
+
+// ParseCVE implements cve.Parser. It reads the CNA and ADP containers of a CVE JSON 5 record and
+// falls back to the legacy cve-search layout.
+func (c *Client) ParseCVE(data map[string]interface{}) (cve.Record, bool) {
+	rec := cve.Record{Sources: []string{c.Code()}}
+	if meta := providerapi.Map(data, "cveMetadata"); meta != nil {
+		rec.ID = providerapi.String(meta, "cveId")
+		rec.Published = providerapi.Time(meta, "datePublished")
+		rec.Modified = providerapi.Time(meta, "dateUpdated")
+		containers := []map[string]interface{}{providerapi.Map(data, "containers", "cna")}
+		for _, a := range providerapi.List(data, "containers", "adp") {
+			adp, _ := a.(map[string]interface{})
+			containers = append(containers, adp)
+		}
+		for _, ct := range containers {
+			c.parseContainer(ct, &rec)
+		}
+		return rec, rec.ID != ""
+	}
+
+	// Legacy cve-search.
+	rec.ID = providerapi.String(data, "id")
+	if rec.ID == "" {
+		return cve.Record{}, false
+	}
+	rec.Description = providerapi.String(data, "summary")
+	rec.Published = providerapi.Time(data, "Published")
+	rec.Modified = providerapi.Time(data, "Modified")
+	if v := providerapi.String(data, "cvss-vector"); v != "" {
+		score, _ := providerapi.Number(data, "cvss")
+		rec.CVSS = append(rec.CVSS, cve.CVSS{Version: cve.VersionFromVector(v), Vector: v, BaseScore: score, Source: c.Code()})
+	}
+	if v := providerapi.String(data, "cwe"); strings.HasPrefix(v, "CWE-") {
+		rec.CWEs = append(rec.CWEs, v)
+	}
+	for _, r := range providerapi.List(data, "references") {
+		if u, ok := r.(string); ok {
+			rec.References = append(rec.References, u)
+		}
+	}
+	for _, v := range providerapi.List(data, "vulnerable_configuration") {
+		switch cpe := v.(type) {
+		case string:
+			rec.CPEs = append(rec.CPEs, cpe)
+		case map[string]interface{}:
+			rec.CPEs = append(rec.CPEs, providerapi.String(cpe, "id"))
+		}
+	}
+	return rec, true
+}
+
+func (c *Client) parseContainer(ct map[string]interface{}, rec *cve.Record) {
+	if ct == nil {
+		return
+	}
+	for _, d := range providerapi.List(ct, "descriptions") {
+		desc, _ := d.(map[string]interface{})
+		if rec.Description == "" && strings.HasPrefix(providerapi.String(desc, "lang"), "en") {
+			rec.Description = providerapi.String(desc, "value")
+		}
+	}
+	for _, m := range providerapi.List(ct, "metrics") {
+		metric, _ := m.(map[string]interface{})
+		for _, k := range cvssKeys {
+			data := providerapi.Map(metric, k.key)
+			if data == nil {
+				continue
+			}
+			score, _ := providerapi.Number(data, "baseScore")
+			rec.CVSS = append(rec.CVSS, cve.CVSS{
+				Version:   k.version,
+				Vector:    providerapi.String(data, "vectorString"),
+				BaseScore: score,
+				Severity:  providerapi.String(data, "baseSeverity"),
+				Source:    c.Code(),
+			})
+		}
+	}
+	for _, p := range providerapi.List(ct, "problemTypes") {
+		pt, _ := p.(map[string]interface{})
+		for _, d := range providerapi.List(pt, "descriptions") {
+			desc, _ := d.(map[string]interface{})
+			if id := providerapi.String(desc, "cweId"); id != "" {
+				rec.CWEs = append(rec.CWEs, id)
+			}
+		}
+	}
+	for _, r := range providerapi.List(ct, "references") {
+		ref, _ := r.(map[string]interface{})
+		rec.References = append(rec.References, providerapi.String(ref, "url"))
+	}
+	for _, a := range providerapi.List(ct, "affected") {
+		affected, _ := a.(map[string]interface{})
+		for _, cpe := range providerapi.List(affected, "cpes") {
+			if s, ok := cpe.(string); ok {
+				rec.CPEs = append(rec.CPEs, s)
+			}
+		}
+	}
+}
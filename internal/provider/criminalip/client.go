@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
+	"strings"
 
 	"hermes/internal/providerapi"
 )
@@ -22,13 +24,16 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "criminalip" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"ip", "domain"}
@@ -107,3 +112,50 @@ func (c *Client) lookupDomain(ctx context.Context, domain string) (providerapi.R
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// criminalIPLevels maps Criminal IP's inbound/outbound risk levels to scores.
+var criminalIPLevels = map[string]int{
+	"critical":  100,
+	"dangerous": 80,
+	"moderate":  50,
+	"low":       20,
+	"safe":      0,
+}
+
+// Assess implements providerapi.Adapter. IP summaries use the worse of the inbound/outbound
+// levels plus issue flags as tags; domain quick views carry a malicious/safe result.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	if result := strings.ToLower(providerapi.String(data, "data", "result")); result != "" {
+		switch result {
+		case "malicious":
+			return providerapi.Assess(providerapi.VerdictMalicious, 90)
+		case "suspicious":
+			return providerapi.Assess(providerapi.VerdictSuspicious, 50)
+		case "safe", "normal":
+			return providerapi.Assess(providerapi.VerdictClean, 0)
+		}
+		return providerapi.Unknown()
+	}
+
+	score, found := 0, false
+	for _, dir := range []string{"inbound", "outbound"} {
+		if s, ok := criminalIPLevels[strings.ToLower(providerapi.String(data, "score", dir))]; ok {
+			found = true
+			if s > score {
+				score = s
+			}
+		}
+	}
+	if !found {
+		return providerapi.Unknown()
+	}
+	a := providerapi.Assess(providerapi.VerdictForScore(score), score)
+	issues := providerapi.Map(data, "issues")
+	for k := range issues {
+		if providerapi.Bool(issues, k) {
+			a.Tags = append(a.Tags, strings.TrimPrefix(k, "is_"))
+		}
+	}
+	sort.Strings(a.Tags)
+	return a
+}
@@ -22,7 +22,7 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
@@ -66,3 +66,32 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. Malicious activity or blacklisting is malicious, the
+// suspicious flag is suspicious; otherwise the reputation level sets a clean score.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	if _, ok := data["reputation"]; !ok {
+		return providerapi.Unknown()
+	}
+	details := providerapi.Map(data, "details")
+	var a providerapi.Assessment
+	switch {
+	case providerapi.Bool(details, "malicious_activity_recent"):
+		a = providerapi.Assess(providerapi.VerdictMalicious, 95)
+	case providerapi.Bool(details, "malicious_activity"), providerapi.Bool(details, "blacklisted"):
+		a = providerapi.Assess(providerapi.VerdictMalicious, 80)
+	case providerapi.Bool(data, "suspicious"):
+		a = providerapi.Assess(providerapi.VerdictSuspicious, 50)
+	default:
+		score := map[string]int{"high": 0, "medium": 10, "low": 20}[providerapi.String(data, "reputation")]
+		a = providerapi.Assess(providerapi.VerdictClean, score)
+	}
+	for _, flag := range []string{"blacklisted", "malicious_activity", "credentials_leaked", "data_breach", "spam", "disposable", "spoofable"} {
+		if providerapi.Bool(details, flag) {
+			a.Tags = append(a.Tags, flag)
+		}
+	}
+	a.FirstSeen = providerapi.Time(details, "first_seen")
+	a.LastSeen = providerapi.Time(details, "last_seen")
+	return a
+}
@@ -0,0 +1,147 @@
+package feodotracker
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"hermes/internal/provider/abusech"
+	"hermes/internal/providerapi"
+)
+
+const (
+	blocklistURL = "https://feodotracker.abuse.ch/downloads/ipblocklist.json"
+	// refreshEvery matches the blocklist's publishing interval.
+	refreshEvery = 5 * time.Minute
+)
+
+// Client checks IPs against the Feodo Tracker (abuse.ch) botnet C2 blocklist. Feodo Tracker has
+// no per-indicator API, so the blocklist is downloaded and kept in memory between refreshes.
+type Client struct {
+	apiKey       string
+	blocklistURL string
+	client       *http.Client
+
+	mu        sync.Mutex
+	entries   map[string][]map[string]interface{}
+	fetchedAt time.Time
+}
+
+// NewClient creates a Feodo Tracker client. apiKey is optional: the blocklist is public, and the
+// key is only sent as Auth-Key when set.
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:       apiKey,
+		blocklistURL: blocklistURL,
+		client:       providerapi.NewHTTPClient(),
+	}
+}
+
+// Code implements providerapi.Adapter.
+func (c *Client) Code() string { return "feodotracker" }
+
+// SupportedTypes implements providerapi.Adapter.
+func (c *Client) SupportedTypes() []string {
+	return []string{"ip"}
+}
+
+// Lookup implements providerapi.Adapter. Data mirrors the other abuse.ch APIs: query_status "ok"
+// with the blocklist entries for the IP (one per C2 port), or "no_results".
+func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	if indicatorType != "ip" {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
+	}
+
+	entries, err := c.blocklist(ctx)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	matches, ok := entries[value]
+	if !ok {
+		return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: map[string]interface{}{"query_status": "no_results"}}, nil
+	}
+	data := make([]interface{}, 0, len(matches))
+	for _, m := range matches {
+		data = append(data, m)
+	}
+	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: map[string]interface{}{"query_status": "ok", "data": data}}, nil
+}
+
+// blocklist returns the cached blocklist indexed by IP, downloading it when older than
+// refreshEvery. A failed refresh keeps serving the previous copy.
+func (c *Client) blocklist(ctx context.Context) (map[string][]map[string]interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries != nil && time.Since(c.fetchedAt) < refreshEvery {
+		return c.entries, nil
+	}
+
+	entries, err := c.download(ctx)
+	if err != nil {
+		if c.entries != nil {
+			return c.entries, nil
+		}
+		return nil, err
+	}
+	c.entries = entries
+	c.fetchedAt = time.Now()
+	return entries, nil
+}
+
+func (c *Client) download(ctx context.Context) (map[string][]map[string]interface{}, error) {
+	req, err := abusech.NewGetRequest(ctx, c.blocklistURL, c.apiKey)
+	if err != nil {
+		return nil, err
+	}
+	var list []map[string]interface{}
+	status, err := abusech.Do(c.client, req, &list)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", status)
+	}
+	entries := make(map[string][]map[string]interface{}, len(list))
+	for _, e := range list {
+		ip := providerapi.String(e, "ip_address")
+		if ip != "" {
+			entries[ip] = append(entries[ip], e)
+		}
+	}
+	return entries, nil
+}
+
+// Assess implements providerapi.Adapter. A listed IP is a botnet C2: malicious while online,
+// suspicious once it has gone offline. An unlisted IP is unknown.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	entries := providerapi.List(data, "data")
+	if providerapi.String(data, "query_status") != "ok" || len(entries) == 0 {
+		return providerapi.Unknown()
+	}
+
+	online := false
+	var tags []string
+	var firstSeen, lastSeen *time.Time
+	seen := make(map[string]bool)
+	for _, e := range entries {
+		entry, _ := e.(map[string]interface{})
+		online = online || providerapi.String(entry, "status") == "online"
+		if m := providerapi.String(entry, "malware"); m != "" && !seen[m] {
+			seen[m] = true
+			tags = append(tags, m)
+		}
+		firstSeen = providerapi.Earliest(firstSeen, providerapi.Time(entry, "first_seen"))
+		lastSeen = providerapi.Latest(lastSeen, providerapi.Time(entry, "last_online"))
+	}
+
+	a := providerapi.Assess(providerapi.VerdictSuspicious, 60)
+	if online {
+		a = providerapi.Assess(providerapi.VerdictMalicious, 100)
+	}
+	a.Tags = append([]string{"botnet_cc"}, tags...)
+	a.FirstSeen = firstSeen
+	a.LastSeen = lastSeen
+	return a
+}
@@ -0,0 +1,65 @@
+package feodotracker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"hermes/internal/providerapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const blocklist = `[
+  {"ip_address":"192.0.2.10","port":443,"status":"online","first_seen":"2024-01-02 03:04:05","last_online":"2024-06-01","malware":"QakBot"},
+  {"ip_address":"192.0.2.10","port":8080,"status":"offline","first_seen":"2023-12-01 00:00:00","last_online":"2024-02-01","malware":"QakBot"},
+  {"ip_address":"198.51.100.7","port":447,"status":"offline","first_seen":"2022-05-05 10:00:00","last_online":"2022-06-01","malware":"TrickBot"}
+]`
+
+func TestLookup_BlocklistIsCached(t *testing.T) {
+	var downloads int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&downloads, 1)
+		assert.Equal(t, "test-key", r.Header.Get("Auth-Key"))
+		_, _ = w.Write([]byte(blocklist))
+	}))
+	defer srv.Close()
+	c := NewClient("test-key")
+	c.blocklistURL = srv.URL
+
+	res, err := c.Lookup(context.Background(), "ip", "192.0.2.10")
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Len(t, res.Data["data"], 2)
+	a := c.Assess(res.Data)
+	assert.Equal(t, providerapi.VerdictMalicious, a.Verdict)
+	assert.Equal(t, []string{"botnet_cc", "QakBot"}, a.Tags)
+	assert.Equal(t, 2023, a.FirstSeen.Year())
+
+	res, err = c.Lookup(context.Background(), "ip", "198.51.100.7")
+	assert.NoError(t, err)
+	assert.Equal(t, providerapi.VerdictSuspicious, c.Assess(res.Data).Verdict)
+
+	res, err = c.Lookup(context.Background(), "ip", "203.0.113.1")
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, providerapi.VerdictUnknown, c.Assess(res.Data).Verdict)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&downloads))
+}
+
+func TestLookup_DownloadFailure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	c := NewClient("")
+	c.blocklistURL = srv.URL
+
+	res, err := c.Lookup(context.Background(), "ip", "192.0.2.10")
+	assert.Error(t, err)
+	assert.False(t, res.Success)
+}
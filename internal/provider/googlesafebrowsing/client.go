@@ -0,0 +1,139 @@
+package googlesafebrowsing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"hermes/internal/providerapi"
+)
+
+const baseURL = "https://safebrowsing.googleapis.com/v4"
+
+// threatTypes are the Safe Browsing v4 lists queried for every lookup.
+var threatTypes = []string{"MALWARE", "SOCIAL_ENGINEERING", "UNWANTED_SOFTWARE", "POTENTIALLY_HARMFUL_APPLICATION"}
+
+// Client calls Google Safe Browsing Lookup API v4 (threatMatches:find).
+type Client struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a Safe Browsing client. apiKey may be empty (Lookup will return not configured).
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  providerapi.NewHTTPClient(),
+	}
+}
+
+// Code implements providerapi.Adapter.
+func (c *Client) Code() string { return "googlesafebrowsing" }
+
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
+// SupportedTypes implements providerapi.Adapter.
+func (c *Client) SupportedTypes() []string {
+	return []string{"url", "domain"}
+}
+
+// Lookup implements providerapi.Adapter. A domain is checked as its http and https root URLs,
+// which Safe Browsing matches against host-level list entries.
+func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	if c.apiKey == "" {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "not configured"}, nil
+	}
+
+	var urls []string
+	switch indicatorType {
+	case "url":
+		urls = []string{value}
+	case "domain":
+		urls = []string{"http://" + value + "/", "https://" + value + "/"}
+	default:
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
+	}
+
+	entries := make([]map[string]string, 0, len(urls))
+	for _, u := range urls {
+		entries = append(entries, map[string]string{"url": u})
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"client": map[string]string{"clientId": "hermes", "clientVersion": "1.0"},
+		"threatInfo": map[string]interface{}{
+			"threatTypes":      threatTypes,
+			"platformTypes":    []string{"ANY_PLATFORM"},
+			"threatEntryTypes": []string{"URL"},
+			"threatEntries":    entries,
+		},
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/threatMatches:find", bytes.NewReader(payload))
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	// Key in a header rather than ?key= so it never appears in logged URLs or transport errors.
+	req.Header.Set("X-Goog-Api-Key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	defer resp.Body.Close()
+
+	var out map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", resp.StatusCode), Data: out}, nil
+	}
+	// No match is an empty object; make it explicit for consumers of Data.
+	if _, ok := out["matches"]; !ok {
+		out["matches"] = []interface{}{}
+	}
+	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
+}
+
+// Assess implements providerapi.Adapter. Malware and social-engineering matches are malicious,
+// unwanted/potentially harmful software is suspicious, no match is clean. Threat types and
+// platforms become tags.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	matches := providerapi.List(data, "matches")
+	if matches == nil {
+		return providerapi.Unknown()
+	}
+	if len(matches) == 0 {
+		return providerapi.Assess(providerapi.VerdictClean, 0)
+	}
+
+	verdict := providerapi.VerdictSuspicious
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		match, _ := m.(map[string]interface{})
+		threat := providerapi.String(match, "threatType")
+		if threat == "MALWARE" || threat == "SOCIAL_ENGINEERING" {
+			verdict = providerapi.VerdictMalicious
+		}
+		for _, tag := range []string{threat, providerapi.String(match, "platformType")} {
+			if tag != "" {
+				seen[strings.ToLower(tag)] = true
+			}
+		}
+	}
+	a := providerapi.Assess(verdict, 100)
+	for tag := range seen {
+		a.Tags = append(a.Tags, tag)
+	}
+	sort.Strings(a.Tags)
+	return a
+}
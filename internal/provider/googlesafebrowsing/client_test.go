@@ -0,0 +1,103 @@
+package googlesafebrowsing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hermes/internal/providerapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer stands in for threatMatches:find; URLs containing "malware" match.
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/threatMatches:find", r.URL.Path)
+		if r.Header.Get("X-Goog-Api-Key") != "test-key" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":403,"message":"API key not valid"}}`))
+			return
+		}
+		var body struct {
+			ThreatInfo struct {
+				ThreatEntries []struct {
+					URL string `json:"url"`
+				} `json:"threatEntries"`
+			} `json:"threatInfo"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		var matches []map[string]interface{}
+		for _, e := range body.ThreatInfo.ThreatEntries {
+			if e.URL == "http://malware.testing.google.test/testing/malware/" {
+				matches = append(matches, map[string]interface{}{
+					"threatType":      "MALWARE",
+					"platformType":    "ANY_PLATFORM",
+					"threatEntryType": "URL",
+					"threat":          map[string]string{"url": e.URL},
+					"cacheDuration":   "300s",
+				})
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if len(matches) == 0 {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"matches": matches})
+	}))
+}
+
+func newTestClient(apiKey, url string) *Client {
+	c := NewClient(apiKey)
+	c.baseURL = url
+	return c
+}
+
+func TestLookup_Match(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	c := newTestClient("test-key", srv.URL)
+
+	res, err := c.Lookup(context.Background(), "url", "http://malware.testing.google.test/testing/malware/")
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Len(t, res.Data["matches"], 1)
+
+	a := c.Assess(res.Data)
+	assert.Equal(t, providerapi.VerdictMalicious, a.Verdict)
+	assert.Equal(t, []string{"any_platform", "malware"}, a.Tags)
+}
+
+func TestLookup_NoMatchIsClean(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	c := newTestClient("test-key", srv.URL)
+
+	res, err := c.Lookup(context.Background(), "domain", "example.com")
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, providerapi.VerdictClean, c.Assess(res.Data).Verdict)
+}
+
+func TestLookup_Errors(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	res, err := newTestClient("", srv.URL).Lookup(context.Background(), "url", "http://example.com/")
+	assert.NoError(t, err)
+	assert.Equal(t, "not configured", res.Error)
+
+	res, err = newTestClient("test-key", srv.URL).Lookup(context.Background(), "ip", "8.8.8.8")
+	assert.NoError(t, err)
+	assert.False(t, res.Success)
+
+	res, err = newTestClient("wrong", srv.URL).Lookup(context.Background(), "url", "http://example.com/")
+	assert.NoError(t, err)
+	assert.False(t, res.Success)
+	assert.Equal(t, "HTTP 403", res.Error)
+}
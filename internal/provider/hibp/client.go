@@ -21,13 +21,16 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "hibp" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"email"}
@@ -68,3 +71,26 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: success, Data: data}, nil
 }
+
+// Assess implements providerapi.Adapter. Appearing in breaches makes an address suspicious (more
+// breaches, higher score); it never makes it malicious on its own.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	breaches := providerapi.List(data, "breaches")
+	if len(breaches) == 0 {
+		return providerapi.Assess(providerapi.VerdictClean, 0)
+	}
+	a := providerapi.Assess(providerapi.VerdictSuspicious, 25+5*len(breaches))
+	for _, b := range breaches {
+		m, ok := b.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name := providerapi.String(m, "Name"); name != "" {
+			a.Tags = append(a.Tags, "breach:"+name)
+		}
+		when := providerapi.Time(m, "BreachDate")
+		a.FirstSeen = providerapi.Earliest(a.FirstSeen, when)
+		a.LastSeen = providerapi.Latest(a.LastSeen, when)
+	}
+	return a
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"hermes/internal/indicator"
 	"hermes/internal/providerapi"
 )
 
@@ -22,18 +24,26 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "hybridanalysis" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"hash", "url"}
 }
 
+// HashSubtypes implements providerapi.HashSubtyper.
+func (c *Client) HashSubtypes() []string {
+	return []string{indicator.HashMD5, indicator.HashSHA1, indicator.HashSHA256}
+}
+
 // Lookup implements providerapi.Adapter.
 func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
 	if c.apiKey == "" {
@@ -79,3 +89,39 @@ func (c *Client) lookupHash(ctx context.Context, hash string) (providerapi.Resul
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. Uses the sandbox verdict and threat_score of the report
+// (the top-level object or the first entry of reports).
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	report := data
+	if reports := providerapi.List(data, "reports"); len(reports) > 0 {
+		if m, ok := reports[0].(map[string]interface{}); ok {
+			report = m
+		}
+	}
+	score, hasScore := providerapi.Number(report, "threat_score")
+	var a providerapi.Assessment
+	switch strings.ToLower(providerapi.String(report, "verdict")) {
+	case "malicious":
+		a = providerapi.Assess(providerapi.VerdictMalicious, int(score))
+	case "suspicious":
+		a = providerapi.Assess(providerapi.VerdictSuspicious, int(score))
+	case "no specific threat", "whitelisted", "no verdict":
+		a = providerapi.Assess(providerapi.VerdictClean, int(score))
+	default:
+		if !hasScore {
+			return providerapi.Unknown()
+		}
+		a = providerapi.Assess(providerapi.VerdictForScore(int(score)), int(score))
+	}
+	if family := providerapi.String(report, "vx_family"); family != "" {
+		a.Tags = append(a.Tags, family)
+	}
+	for _, t := range providerapi.List(report, "tags") {
+		if s, ok := t.(string); ok {
+			a.Tags = append(a.Tags, s)
+		}
+	}
+	a.LastSeen = providerapi.Time(report, "analysis_start_time")
+	return a
+}
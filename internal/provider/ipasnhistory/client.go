@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"sort"
 
 	"hermes/internal/providerapi"
 )
@@ -20,7 +21,7 @@ type Client struct {
 // NewClient creates an IP ASN History client.
 func NewClient(apiKey string) *Client {
 	return &Client{
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
@@ -64,3 +65,22 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. ASN history carries no reputation signal; announcing
+// ASNs are kept as tags.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	a := providerapi.Unknown()
+	seen := make(map[string]bool)
+	for _, day := range providerapi.Map(data, "response") {
+		ips, _ := day.(map[string]interface{})
+		for _, info := range ips {
+			m, _ := info.(map[string]interface{})
+			if asn := providerapi.String(m, "asn"); asn != "" && !seen[asn] {
+				seen[asn] = true
+				a.Tags = append(a.Tags, "asn:"+asn)
+			}
+		}
+	}
+	sort.Strings(a.Tags)
+	return a
+}
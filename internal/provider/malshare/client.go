@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"hermes/internal/indicator"
 	"hermes/internal/providerapi"
 )
 
@@ -22,18 +23,26 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "malshare" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"hash"}
 }
 
+// HashSubtypes implements providerapi.HashSubtyper.
+func (c *Client) HashSubtypes() []string {
+	return []string{indicator.HashMD5, indicator.HashSHA1, indicator.HashSHA256}
+}
+
 // Lookup implements providerapi.Adapter.
 func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
 	if c.apiKey == "" {
@@ -71,3 +80,16 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. Malshare only stores malware, so a sample record is
+// malicious; a miss says nothing about the file.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	if providerapi.String(data, "SHA256") == "" && providerapi.String(data, "MD5") == "" {
+		return providerapi.Unknown()
+	}
+	a := providerapi.Assess(providerapi.VerdictMalicious, 90)
+	if ft := providerapi.String(data, "F_TYPE"); ft != "" {
+		a.Tags = append(a.Tags, ft)
+	}
+	return a
+}
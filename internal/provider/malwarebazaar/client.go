@@ -2,13 +2,12 @@ package malwarebazaar
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"net/url"
-	"strings"
 
+	"hermes/internal/indicator"
+	"hermes/internal/provider/abusech"
 	"hermes/internal/providerapi"
 )
 
@@ -24,18 +23,26 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "malwarebazaar" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"hash"}
 }
 
+// HashSubtypes implements providerapi.HashSubtyper.
+func (c *Client) HashSubtypes() []string {
+	return []string{indicator.HashMD5, indicator.HashSHA1, indicator.HashSHA256}
+}
+
 // Lookup implements providerapi.Adapter.
 func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
 	if c.apiKey == "" {
@@ -49,29 +56,52 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	form.Set("query", "get_info")
 	form.Set("hash", value)
 
-	body := form.Encode()
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL, strings.NewReader(body))
+	req, err := abusech.NewFormRequest(ctx, baseURL, c.apiKey, form)
 	if err != nil {
 		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
 	}
-	req.Header.Set("Auth-Key", c.apiKey)
-	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("Accept", "application/json")
-	req.ContentLength = int64(len(body))
-	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(body)), nil }
-
-	resp, err := c.client.Do(req)
+	var out map[string]interface{}
+	status, err := abusech.Do(c.client, req, &out)
 	if err != nil {
 		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
 	}
-	defer resp.Body.Close()
+	if status != http.StatusOK {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", status), Data: out}, nil
+	}
+	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
+}
+
+// Assess implements providerapi.Adapter. MalwareBazaar only stores malware, so a sample record is
+// malicious; hash_not_found says nothing about the file.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	samples := providerapi.List(data, "data")
+	if providerapi.String(data, "query_status") != "ok" || len(samples) == 0 {
+		return providerapi.Unknown()
+	}
+	sample, _ := samples[0].(map[string]interface{})
+	a := providerapi.Assess(providerapi.VerdictMalicious, 100)
+	if sig := providerapi.String(sample, "signature"); sig != "" {
+		a.Tags = append(a.Tags, sig)
+	}
+	for _, t := range providerapi.List(sample, "tags") {
+		if s, ok := t.(string); ok {
+			a.Tags = append(a.Tags, s)
+		}
+	}
+	a.FirstSeen = providerapi.Time(sample, "first_seen")
+	a.LastSeen = providerapi.Time(sample, "last_seen")
+	return a
+}
 
-	var out map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+// MalwareFamilies implements misp.FamilyParser: a sample's signature is its Malpedia family.
+func (c *Client) MalwareFamilies(data map[string]interface{}) []string {
+	samples := providerapi.List(data, "data")
+	if len(samples) == 0 {
+		return nil
 	}
-	if resp.StatusCode != http.StatusOK {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", resp.StatusCode), Data: out}, nil
+	sample, _ := samples[0].(map[string]interface{})
+	if sig := providerapi.String(sample, "signature"); sig != "" {
+		return []string{sig}
 	}
-	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
+	return nil
 }
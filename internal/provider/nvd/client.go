@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
+	"hermes/internal/cve"
 	"hermes/internal/providerapi"
 )
 
@@ -22,7 +24,7 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
@@ -31,16 +33,17 @@ func (c *Client) Code() string { return "nvd" }
 
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
-	return []string{"hash"} // CVE id / keyword
+	return []string{"cve"}
 }
 
-// Lookup implements providerapi.Adapter. value can be a CVE-ID (e.g. CVE-2024-1234) or keyword.
+// Lookup implements providerapi.Adapter. value is a CVE-ID (e.g. CVE-2024-1234).
 func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
-	// NVD supports CVE lookup; we accept hash type for CVE-ID or add a generic "cve" type. Use indicator as keyword.
+	if indicatorType != "cve" {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
+	}
 	u, _ := url.Parse(baseURL)
 	q := u.Query()
-	q.Set("keywordSearch", value)
-	q.Set("resultsPerPage", "10")
+	q.Set("cveId", value)
 	u.RawQuery = q.Encode()
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
@@ -68,3 +71,83 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: success, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. CVE records carry no malicious/clean reading; only the
+// publication dates of the first match are normalized.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	a := providerapi.Unknown()
+	vulns := providerapi.List(data, "vulnerabilities")
+	if len(vulns) == 0 {
+		return a
+	}
+	first, _ := vulns[0].(map[string]interface{})
+	a.FirstSeen = providerapi.Time(first, "cve", "published")
+	a.LastSeen = providerapi.Time(first, "cve", "lastModified")
+	return a
+}
+
+// ParseCVE implements cve.Parser for a CVE API 2.0 response.
+func (c *Client) ParseCVE(data map[string]interface{}) (cve.Record, bool) {
+	vulns := providerapi.List(data, "vulnerabilities")
+	if len(vulns) == 0 {
+		return cve.Record{}, false
+	}
+	first, _ := vulns[0].(map[string]interface{})
+	item := providerapi.Map(first, "cve")
+	rec := cve.Record{
+		ID:        providerapi.String(item, "id"),
+		Published: providerapi.Time(item, "published"),
+		Modified:  providerapi.Time(item, "lastModified"),
+		Sources:   []string{c.Code()},
+	}
+	for _, d := range providerapi.List(item, "descriptions") {
+		desc, _ := d.(map[string]interface{})
+		if providerapi.String(desc, "lang") == "en" {
+			rec.Description = providerapi.String(desc, "value")
+			break
+		}
+	}
+	for _, key := range []string{"cvssMetricV40", "cvssMetricV31", "cvssMetricV30", "cvssMetricV2"} {
+		for _, m := range providerapi.List(item, "metrics", key) {
+			metric, _ := m.(map[string]interface{})
+			score, _ := providerapi.Number(metric, "cvssData", "baseScore")
+			severity := providerapi.String(metric, "cvssData", "baseSeverity")
+			if severity == "" {
+				severity = providerapi.String(metric, "baseSeverity") // v2 keeps it outside cvssData
+			}
+			rec.CVSS = append(rec.CVSS, cve.CVSS{
+				Version:   providerapi.String(metric, "cvssData", "version"),
+				Vector:    providerapi.String(metric, "cvssData", "vectorString"),
+				BaseScore: score,
+				Severity:  severity,
+				Source:    c.Code(),
+			})
+		}
+	}
+	for _, w := range providerapi.List(item, "weaknesses") {
+		weakness, _ := w.(map[string]interface{})
+		for _, d := range providerapi.List(weakness, "description") {
+			desc, _ := d.(map[string]interface{})
+			if v := providerapi.String(desc, "value"); strings.HasPrefix(v, "CWE-") {
+				rec.CWEs = append(rec.CWEs, v)
+			}
+		}
+	}
+	for _, r := range providerapi.List(item, "references") {
+		ref, _ := r.(map[string]interface{})
+		rec.References = append(rec.References, providerapi.String(ref, "url"))
+	}
+	for _, cf := range providerapi.List(item, "configurations") {
+		conf, _ := cf.(map[string]interface{})
+		for _, n := range providerapi.List(conf, "nodes") {
+			node, _ := n.(map[string]interface{})
+			for _, m := range providerapi.List(node, "cpeMatch") {
+				match, _ := m.(map[string]interface{})
+				if providerapi.Bool(match, "vulnerable") {
+					rec.CPEs = append(rec.CPEs, providerapi.String(match, "criteria"))
+				}
+			}
+		}
+	}
+	return rec, true
+}
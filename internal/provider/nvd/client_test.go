@@ -0,0 +1,37 @@
+package nvd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `{"vulnerabilities":[{"cve":{
+  "id":"CVE-2024-3094","published":"2024-03-29T17:15:21.150","lastModified":"2025-02-06T09:15:10.820",
+  "descriptions":[{"lang":"es","value":"..."},{"lang":"en","value":"Malicious code was discovered in the upstream tarballs of xz."}],
+  "metrics":{"cvssMetricV31":[{"source":"secalert@redhat.com","cvssData":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H","baseScore":10.0,"baseSeverity":"CRITICAL"}}]},
+  "weaknesses":[{"description":[{"lang":"en","value":"CWE-506"},{"lang":"en","value":"NVD-CWE-noinfo"}]}],
+  "configurations":[{"nodes":[{"cpeMatch":[{"vulnerable":true,"criteria":"cpe:2.3:a:tukaani:xz:5.6.0:*:*:*:*:*:*:*"},{"vulnerable":false,"criteria":"cpe:2.3:o:linux:linux_kernel:-:*:*:*:*:*:*:*"}]}]}],
+  "references":[{"url":"https://www.openwall.com/lists/oss-security/2024/03/29/4"}]
+}}]}`
+
+func TestParseCVE(t *testing.T) {
+	var data map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(sample), &data))
+
+	rec, ok := NewClient("").ParseCVE(data)
+	assert.True(t, ok)
+	assert.Equal(t, "CVE-2024-3094", rec.ID)
+	assert.Equal(t, "Malicious code was discovered in the upstream tarballs of xz.", rec.Description)
+	assert.Equal(t, 2024, rec.Published.Year())
+	assert.Len(t, rec.CVSS, 1)
+	assert.Equal(t, "3.1", rec.CVSS[0].Version)
+	assert.Equal(t, 10.0, rec.CVSS[0].BaseScore)
+	assert.Equal(t, []string{"CWE-506"}, rec.CWEs)
+	assert.Equal(t, []string{"cpe:2.3:a:tukaani:xz:5.6.0:*:*:*:*:*:*:*"}, rec.CPEs)
+	assert.Len(t, rec.References, 1)
+
+	_, ok = NewClient("").ParseCVE(map[string]interface{}{"vulnerabilities": []interface{}{}})
+	assert.False(t, ok)
+}
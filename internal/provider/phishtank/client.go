@@ -23,7 +23,7 @@ type Client struct {
 func NewClient(appKey string) *Client {
 	return &Client{
 		appKey: appKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
@@ -71,3 +71,47 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: success, Data: out, Error: errMsg}, nil
 }
+
+// Assess implements providerapi.Adapter. A verified, still-valid phish is malicious; an unverified
+// submission or a verified phish that is offline is suspicious; a URL not in the database is clean.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	res := providerapi.Map(data, "results")
+	if res == nil {
+		return providerapi.Unknown()
+	}
+	if !providerapi.Bool(res, "in_database") {
+		return providerapi.Assess(providerapi.VerdictClean, 0)
+	}
+	var a providerapi.Assessment
+	switch {
+	case providerapi.Bool(res, "verified") && providerapi.Bool(res, "valid"):
+		a = providerapi.Assess(providerapi.VerdictMalicious, 100)
+		a.Tags = []string{"phishing"}
+	case providerapi.Bool(res, "verified"):
+		a = providerapi.Assess(providerapi.VerdictSuspicious, 30)
+		a.Tags = []string{"phishing", "offline"}
+	default:
+		a = providerapi.Assess(providerapi.VerdictSuspicious, 60)
+		a.Tags = []string{"phishing", "unverified"}
+	}
+	a.LastSeen = providerapi.Time(res, "verified_at")
+	return a
+}
+
+// ReportFields implements report.Fielder.
+func (c *Client) ReportFields() []string {
+	return []string{"verified"}
+}
+
+// ReportValues implements report.Fielder: "yes" or "no" for URLs in the database, "" otherwise.
+func (c *Client) ReportValues(data map[string]interface{}) []string {
+	res := providerapi.Map(data, "results")
+	switch {
+	case !providerapi.Bool(res, "in_database"):
+		return []string{""}
+	case providerapi.Bool(res, "verified"):
+		return []string{"yes"}
+	default:
+		return []string{"no"}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"hermes/internal/providerapi"
 )
@@ -22,13 +23,16 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "pulsedive" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"ip", "domain", "url"}
@@ -63,3 +67,34 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// pulsediveRisk maps Pulsedive risk levels to scores.
+var pulsediveRisk = map[string]int{
+	"critical": 100,
+	"high":     80,
+	"medium":   50,
+	"low":      20,
+	"none":     0,
+}
+
+// Assess implements providerapi.Adapter. The score follows Pulsedive's risk level; threat and
+// feed names become tags.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	score, ok := pulsediveRisk[strings.ToLower(providerapi.String(data, "risk"))]
+	if !ok {
+		return providerapi.Unknown()
+	}
+	a := providerapi.Assess(providerapi.VerdictForScore(score), score)
+	for _, key := range []string{"threats", "feeds"} {
+		for _, t := range providerapi.List(data, key) {
+			if m, ok := t.(map[string]interface{}); ok {
+				if name := providerapi.String(m, "name"); name != "" {
+					a.Tags = append(a.Tags, name)
+				}
+			}
+		}
+	}
+	a.FirstSeen = providerapi.Time(data, "stamp_added")
+	a.LastSeen = providerapi.Time(data, "stamp_seen")
+	return a
+}
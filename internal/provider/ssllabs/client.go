@@ -3,23 +3,56 @@ package ssllabs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"time"
 
 	"hermes/internal/providerapi"
 )
 
-const baseURL = "https://api.ssllabs.com/api/v3"
+const defaultBaseURL = "https://api.ssllabs.com/api/v3"
+
+// Assessment statuses reported by /analyze.
+const (
+	statusDNS        = "DNS"
+	statusInProgress = "IN_PROGRESS"
+	statusReady      = "READY"
+	statusError      = "ERROR"
+)
+
+// gradeOrder ranks grades from best to worst (T: untrusted certificate, M: name mismatch).
+var gradeOrder = []string{"A+", "A", "A-", "B", "C", "D", "E", "F", "T", "M"}
 
 // Client calls SSL Labs (Qualys) API. Public API v3 does not require an API key.
+//
+// Assessments take minutes and run as providerapi.AsyncAdapter jobs: Submit returns a cached
+// report (fromCache, maxAge) when there is one and otherwise the assessment as a job, which Poll
+// checks at the intervals SSL Labs advises.
 type Client struct {
-	client *http.Client
+	client  *http.Client
+	baseURL string
+	// maxAgeHours is the oldest cached report accepted; 0 always starts a new assessment.
+	maxAgeHours int
+	// pollDNS and pollInProgress are the poll intervals advised for the DNS and IN_PROGRESS
+	// phases; pollBusy is used after SSL Labs answers 429, 503 or 529.
+	pollDNS        time.Duration
+	pollInProgress time.Duration
+	pollBusy       time.Duration
 }
 
-// NewClient creates an SSL Labs client.
-func NewClient() *Client {
-	return &Client{client: &http.Client{}}
+// NewClient creates an SSL Labs client that reuses reports up to maxAgeHours old.
+func NewClient(maxAgeHours int) *Client {
+	return &Client{
+		client:         providerapi.NewHTTPClient(),
+		baseURL:        defaultBaseURL,
+		maxAgeHours:    maxAgeHours,
+		pollDNS:        5 * time.Second,
+		pollInProgress: 10 * time.Second,
+		pollBusy:       30 * time.Second,
+	}
 }
 
 // Code implements providerapi.Adapter.
@@ -30,35 +63,327 @@ func (c *Client) SupportedTypes() []string {
 	return []string{"domain"}
 }
 
-// Lookup implements providerapi.Adapter. value is the hostname to assess (e.g. example.com).
+// Lookup implements providerapi.Adapter. value is the hostname to assess (e.g. example.com); an
+// assessment still running is returned as pending.
 func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	job, res, err := c.Submit(ctx, indicatorType, value)
+	if job != nil {
+		return providerapi.PendingResult(c.Code(), job), nil
+	}
+	return res, err
+}
+
+// Submit implements providerapi.AsyncAdapter. A new assessment is started at most once, here
+// (fromCache starts one on a cache miss; startNew when cached reports are not accepted); Poll
+// never restarts it.
+func (c *Client) Submit(ctx context.Context, indicatorType string, value string) (*providerapi.Job, providerapi.Result, error) {
 	if indicatorType != "domain" {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
+		return nil, providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
 	}
+	q := url.Values{"host": {value}, "all": {"done"}}
+	if c.maxAgeHours > 0 {
+		q.Set("fromCache", "on")
+		q.Set("maxAge", strconv.Itoa(c.maxAgeHours))
+	} else {
+		q.Set("startNew", "on")
+	}
+	out, status, err := c.analyze(ctx, q)
+	if err != nil {
+		return nil, providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	if status != http.StatusOK {
+		return nil, providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", status), Data: out}, nil
+	}
+	switch st := providerapi.String(out, "status"); st {
+	case statusReady, statusError:
+		return nil, c.result(out), nil
+	default:
+		return &providerapi.Job{ID: value, Data: map[string]interface{}{
+			"host":              value,
+			"assessment_status": st,
+			"started_at":        time.Now().UTC().Format(time.RFC3339),
+		}}, providerapi.Result{}, nil
+	}
+}
 
-	u, _ := url.Parse(baseURL + "/analyze")
-	q := u.Query()
-	q.Set("host", value)
-	u.RawQuery = q.Encode()
+// Poll implements providerapi.AsyncAdapter. Polls omit all=done: the full report is fetched once,
+// by Collect.
+func (c *Client) Poll(ctx context.Context, job providerapi.Job) (providerapi.JobStatus, error) {
+	out, status, err := c.analyze(ctx, url.Values{"host": {job.ID}})
+	switch {
+	case err != nil:
+		return providerapi.JobStatus{}, err
+	case status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable || status == 529:
+		return providerapi.JobStatus{RetryAfter: c.pollBusy}, nil
+	case status != http.StatusOK:
+		return providerapi.JobStatus{}, fmt.Errorf("HTTP %d", status)
+	}
+	data := make(map[string]interface{}, len(job.Data))
+	for k, v := range job.Data {
+		data[k] = v
+	}
+	switch st := providerapi.String(out, "status"); st {
+	case statusReady:
+		return providerapi.JobStatus{Done: true}, nil
+	case statusError:
+		return providerapi.JobStatus{Failed: c.result(out).Error}, nil
+	case statusDNS:
+		data["assessment_status"] = st
+		return providerapi.JobStatus{Data: data, RetryAfter: c.pollDNS}, nil
+	default:
+		data["assessment_status"] = st
+		return providerapi.JobStatus{Data: data, RetryAfter: c.pollInProgress}, nil
+	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+// Collect implements providerapi.AsyncAdapter.
+func (c *Client) Collect(ctx context.Context, job providerapi.Job) (providerapi.Result, error) {
+	out, status, err := c.analyze(ctx, url.Values{"host": {job.ID}, "all": {"done"}})
 	if err != nil {
 		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
 	}
-	req.Header.Set("Accept", "application/json")
+	if status != http.StatusOK {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", status)}, fmt.Errorf("HTTP %d", status)
+	}
+	if st := providerapi.String(out, "status"); st != statusReady && st != statusError {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "assessment not finished"}, errors.New("assessment not finished")
+	}
+	return c.result(out), nil
+}
 
+// result maps a finished /analyze response (READY or ERROR) to a result.
+func (c *Client) result(out map[string]interface{}) providerapi.Result {
+	if providerapi.String(out, "status") == statusReady {
+		return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: summarize(out)}
+	}
+	msg := providerapi.String(out, "statusMessage")
+	if msg == "" {
+		msg = "assessment failed"
+	}
+	return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: msg, Data: out}
+}
+
+func (c *Client) analyze(ctx context.Context, q url.Values) (map[string]interface{}, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/analyze?"+q.Encode(), nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+		return nil, 0, err
 	}
 	defer resp.Body.Close()
-
 	var out map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, resp.StatusCode, err
+	}
+	return out, resp.StatusCode, nil
+}
+
+// summarize extracts grades, weaknesses and the leaf certificate from a READY report; the full
+// report (every cipher suite and handshake simulation per endpoint) is too large to store.
+func summarize(raw map[string]interface{}) map[string]interface{} {
+	out := map[string]interface{}{
+		"host":   providerapi.String(raw, "host"),
+		"status": statusReady,
+	}
+	if t := msTime(raw, "testTime"); !t.IsZero() {
+		out["test_time"] = t.Format(time.RFC3339)
+	}
+	worst := -1
+	endpoints := make([]interface{}, 0)
+	weaknesses := newSet()
+	for _, e := range providerapi.List(raw, "endpoints") {
+		ep, _ := e.(map[string]interface{})
+		grade := providerapi.String(ep, "grade")
+		if i := gradeIndex(grade); i > worst {
+			worst = i
+		}
+		details := providerapi.Map(ep, "details")
+		w := endpointWeaknesses(details)
+		weaknesses.add(w...)
+		endpoints = append(endpoints, map[string]interface{}{
+			"ip_address":          providerapi.String(ep, "ipAddress"),
+			"server_name":         providerapi.String(ep, "serverName"),
+			"grade":               grade,
+			"grade_trust_ignored": providerapi.String(ep, "gradeTrustIgnored"),
+			"has_warnings":        providerapi.Bool(ep, "hasWarnings"),
+			"status_message":      providerapi.String(ep, "statusMessage"),
+			"protocols":           protocols(details),
+			"weaknesses":          w,
+		})
+	}
+	out["endpoints"] = endpoints
+	out["weaknesses"] = weaknesses.list
+	if worst >= 0 {
+		out["grade"] = gradeOrder[worst]
+	}
+	if certs := providerapi.List(raw, "certs"); len(certs) > 0 {
+		leaf, _ := certs[0].(map[string]interface{})
+		notAfter := msTime(leaf, "notAfter")
+		cert := map[string]interface{}{
+			"subject": providerapi.String(leaf, "subject"),
+			"issuer":  providerapi.String(leaf, "issuerSubject"),
+		}
+		if nb := msTime(leaf, "notBefore"); !nb.IsZero() {
+			cert["not_before"] = nb.Format(time.RFC3339)
+		}
+		if !notAfter.IsZero() {
+			cert["not_after"] = notAfter.Format(time.RFC3339)
+			cert["days_remaining"] = int(time.Until(notAfter).Hours() / 24)
+			cert["expired"] = time.Now().After(notAfter)
+		}
+		out["certificate"] = cert
+	}
+	return out
+}
+
+// protocols lists the endpoint's supported protocols, e.g. "TLS 1.2".
+func protocols(details map[string]interface{}) []string {
+	out := make([]string, 0)
+	for _, p := range providerapi.List(details, "protocols") {
+		pm, _ := p.(map[string]interface{})
+		out = append(out, providerapi.String(pm, "name")+" "+providerapi.String(pm, "version"))
+	}
+	return out
+}
+
+// vulnerabilities maps SSL Labs detail fields to the values that mean "vulnerable".
+var vulnerabilities = []struct {
+	field, name string
+	values      []float64
+}{
+	{"heartbleed", "heartbleed", nil},
+	{"vulnBeast", "beast", nil},
+	{"poodle", "poodle", nil},
+	{"freak", "freak", nil},
+	{"logjam", "logjam", nil},
+	{"drownVulnerable", "drown", nil},
+	{"supportsRc4", "rc4", nil},
+	{"poodleTls", "poodle_tls", []float64{2}},
+	{"openSslCcs", "openssl_ccs", []float64{3}},
+	{"openSSLLuckyMinus20", "lucky_minus20", []float64{2}},
+	{"ticketbleed", "ticketbleed", []float64{2}},
+	{"bleichenbacher", "robot", []float64{2, 3}},
+	{"zombiePoodle", "zombie_poodle", []float64{2, 3}},
+	{"goldenDoodle", "golden_doodle", []float64{4, 5}},
+	{"zeroLengthPaddingOracle", "zero_length_padding_oracle", []float64{6, 7}},
+	{"sleepingPoodle", "sleeping_poodle", []float64{10, 11}},
+}
+
+// endpointWeaknesses lists legacy protocols, insecure or weak cipher suites and known
+// vulnerabilities, e.g. "protocol:TLS 1.0", "insecure_cipher:TLS_RSA_WITH_RC4_128_SHA", "heartbleed".
+func endpointWeaknesses(details map[string]interface{}) []string {
+	out := newSet()
+	for _, p := range protocols(details) {
+		switch p {
+		case "SSL 2.0", "SSL 3.0", "TLS 1.0", "TLS 1.1":
+			out.add("protocol:" + p)
+		}
+	}
+	for _, s := range providerapi.List(details, "suites") {
+		sm, _ := s.(map[string]interface{})
+		for _, suite := range providerapi.List(sm, "list") {
+			cs, _ := suite.(map[string]interface{})
+			if q, ok := providerapi.Number(cs, "q"); ok {
+				switch q {
+				case 0:
+					out.add("insecure_cipher:" + providerapi.String(cs, "name"))
+				case 1:
+					out.add("weak_cipher:" + providerapi.String(cs, "name"))
+				}
+			}
+		}
+	}
+	for _, v := range vulnerabilities {
+		if v.values == nil {
+			if providerapi.Bool(details, v.field) {
+				out.add(v.name)
+			}
+			continue
+		}
+		n, _ := providerapi.Number(details, v.field)
+		for _, bad := range v.values {
+			if n == bad {
+				out.add(v.name)
+			}
+		}
+	}
+	return out.list
+}
+
+// msTime reads a Unix timestamp in milliseconds (zero when missing).
+func msTime(m map[string]interface{}, key string) time.Time {
+	ms, ok := providerapi.Number(m, key)
+	if !ok || ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(ms)).UTC()
+}
+
+func gradeIndex(grade string) int {
+	for i, g := range gradeOrder {
+		if g == grade {
+			return i
+		}
+	}
+	return -1
+}
+
+// stringList reads a list of strings as summarized ([]string) or decoded from JSON ([]interface{}).
+func stringList(v interface{}) []string {
+	switch l := v.(type) {
+	case []string:
+		return l
+	case []interface{}:
+		out := make([]string, 0, len(l))
+		for _, e := range l {
+			if s, ok := e.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// set is an insertion-ordered string set.
+type set struct {
+	seen map[string]bool
+	list []string
+}
+
+func newSet() *set { return &set{seen: make(map[string]bool), list: make([]string, 0)} }
+
+func (s *set) add(vs ...string) {
+	for _, v := range vs {
+		if !s.seen[v] {
+			s.seen[v] = true
+			s.list = append(s.list, v)
+		}
+	}
+}
+
+// Assess implements providerapi.Adapter. TLS configuration quality is not reputation, so the
+// verdict is unknown; the worst grade, per-endpoint grades, weaknesses and an expired certificate
+// are kept as tags.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	a := providerapi.Unknown()
+	tags := newSet()
+	for _, e := range providerapi.List(data, "endpoints") {
+		ep, _ := e.(map[string]interface{})
+		if grade := providerapi.String(ep, "grade"); grade != "" {
+			tags.add("grade:" + grade)
+		}
+	}
+	tags.add(stringList(providerapi.Get(data, "weaknesses"))...)
+	if providerapi.Bool(data, "certificate", "expired") {
+		tags.add("certificate_expired")
 	}
-	if resp.StatusCode != http.StatusOK {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", resp.StatusCode), Data: out}, nil
+	if len(tags.list) > 0 {
+		a.Tags = tags.list
 	}
-	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
+	a.LastSeen = providerapi.Time(data, "test_time")
+	return a
 }
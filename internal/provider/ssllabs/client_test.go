@@ -0,0 +1,127 @@
+package ssllabs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hermes/internal/providerapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const readyReport = `{"host":"example.com","status":"READY","testTime":1740042000000,
+"endpoints":[
+ {"ipAddress":"93.184.216.34","grade":"A","gradeTrustIgnored":"A","hasWarnings":false,"details":{
+  "protocols":[{"name":"TLS","version":"1.2"},{"name":"TLS","version":"1.3"}],
+  "suites":[{"protocol":771,"list":[{"name":"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256","q":2}]}]}},
+ {"ipAddress":"2606:2800:220:1::1","grade":"C","gradeTrustIgnored":"C","hasWarnings":true,"details":{
+  "protocols":[{"name":"TLS","version":"1.0"},{"name":"TLS","version":"1.2"}],
+  "suites":[{"protocol":769,"list":[{"name":"TLS_RSA_WITH_3DES_EDE_CBC_SHA","q":1},{"name":"TLS_RSA_WITH_RC4_128_SHA","q":0}]}],
+  "supportsRc4":true,"poodleTls":2,"bleichenbacher":1}}],
+"certs":[{"subject":"CN=example.com","issuerSubject":"CN=Test CA","notBefore":1700000000000,"notAfter":1710000000000}]}`
+
+func newTestClient(url string, maxAgeHours int) *Client {
+	c := NewClient(maxAgeHours)
+	c.baseURL = url
+	c.pollDNS, c.pollInProgress, c.pollBusy = 10*time.Millisecond, 10*time.Millisecond, 10*time.Millisecond
+	return c
+}
+
+func TestLookup_CachedReport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "on", q.Get("fromCache"))
+		assert.Equal(t, "24", q.Get("maxAge"))
+		assert.Empty(t, q.Get("startNew"))
+		_, _ = w.Write([]byte(readyReport))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, 24)
+
+	res, err := c.Lookup(context.Background(), "domain", "example.com")
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.False(t, res.Pending)
+	assert.Equal(t, "C", res.Data["grade"])
+	assert.Equal(t, "2025-02-20T09:00:00Z", res.Data["test_time"])
+	assert.Equal(t, []string{"protocol:TLS 1.0", "weak_cipher:TLS_RSA_WITH_3DES_EDE_CBC_SHA",
+		"insecure_cipher:TLS_RSA_WITH_RC4_128_SHA", "rc4", "poodle_tls"}, res.Data["weaknesses"])
+	cert := res.Data["certificate"].(map[string]interface{})
+	assert.Equal(t, "2024-03-09T16:00:00Z", cert["not_after"])
+	assert.Equal(t, true, cert["expired"])
+
+	a := c.Assess(res.Data)
+	assert.Equal(t, providerapi.VerdictUnknown, a.Verdict)
+	assert.Contains(t, a.Tags, "grade:A")
+	assert.Contains(t, a.Tags, "grade:C")
+	assert.Contains(t, a.Tags, "poodle_tls")
+	assert.Contains(t, a.Tags, "certificate_expired")
+	assert.NotNil(t, a.LastSeen)
+}
+
+func TestLookup_PendingThenPoll(t *testing.T) {
+	var starts, polls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("startNew") == "on" {
+			atomic.AddInt32(&starts, 1)
+			_, _ = w.Write([]byte(`{"host":"example.com","status":"DNS"}`))
+			return
+		}
+		if q.Get("all") == "done" {
+			_, _ = w.Write([]byte(readyReport))
+			return
+		}
+		switch atomic.AddInt32(&polls, 1) {
+		case 1:
+			w.WriteHeader(529)
+		case 2:
+			_, _ = w.Write([]byte(`{"host":"example.com","status":"IN_PROGRESS"}`))
+		default:
+			_, _ = w.Write([]byte(`{"host":"example.com","status":"READY"}`))
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, 0)
+	ctx := context.Background()
+
+	res, err := c.Lookup(ctx, "domain", "example.com")
+	assert.NoError(t, err)
+	assert.True(t, res.Pending)
+	assert.Equal(t, "DNS", res.Data["assessment_status"])
+
+	job := providerapi.Job{ID: "example.com", Data: res.Data}
+	st, err := c.Poll(ctx, job)
+	assert.NoError(t, err)
+	assert.Equal(t, c.pollBusy, st.RetryAfter)
+	st, err = c.Poll(ctx, job)
+	assert.NoError(t, err)
+	assert.False(t, st.Done)
+	assert.Equal(t, "IN_PROGRESS", st.Data["assessment_status"])
+	st, err = c.Poll(ctx, job)
+	assert.NoError(t, err)
+	assert.True(t, st.Done)
+
+	done, err := c.Collect(ctx, job)
+	assert.NoError(t, err)
+	assert.True(t, done.Success)
+	assert.Equal(t, "C", done.Data["grade"])
+	assert.Equal(t, int32(1), atomic.LoadInt32(&starts))
+}
+
+func TestPoll_AssessmentError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"host":"nope.example","status":"ERROR","statusMessage":"Unable to resolve domain name"}`))
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, 24)
+
+	st, err := c.Poll(context.Background(), providerapi.Job{ID: "nope.example"})
+	assert.NoError(t, err)
+	assert.False(t, st.Done)
+	assert.Equal(t, "Unable to resolve domain name", st.Failed)
+}
@@ -0,0 +1,153 @@
+package threatfox
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"hermes/internal/indicator"
+	"hermes/internal/provider/abusech"
+	"hermes/internal/providerapi"
+)
+
+const baseURL = "https://threatfox-api.abuse.ch/api/v1/"
+
+// Client calls ThreatFox (abuse.ch) API IOC search.
+type Client struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a ThreatFox client. apiKey may be empty (Lookup will return not configured).
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  providerapi.NewHTTPClient(),
+	}
+}
+
+// Code implements providerapi.Adapter.
+func (c *Client) Code() string { return "threatfox" }
+
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
+// SupportedTypes implements providerapi.Adapter.
+func (c *Client) SupportedTypes() []string {
+	return []string{"ip", "domain", "url", "hash"}
+}
+
+// HashSubtypes implements providerapi.HashSubtyper.
+func (c *Client) HashSubtypes() []string {
+	return []string{indicator.HashMD5, indicator.HashSHA256}
+}
+
+// Lookup implements providerapi.Adapter. Hashes use search_hash (IOCs whose payload matches);
+// everything else uses search_ioc. IPs are stored as ip:port, so they are searched by substring
+// and the search term is kept in the data for Assess to drop IOCs of other IPs.
+func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	if c.apiKey == "" {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "not configured"}, nil
+	}
+
+	var payload map[string]interface{}
+	switch indicatorType {
+	case "hash":
+		payload = map[string]interface{}{"query": "search_hash", "hash": value}
+	case "ip", "domain", "url":
+		payload = map[string]interface{}{"query": "search_ioc", "search_term": value, "exact_match": indicatorType != "ip"}
+	default:
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
+	}
+
+	req, err := abusech.NewJSONRequest(ctx, c.baseURL, c.apiKey, payload)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	var out map[string]interface{}
+	status, err := abusech.Do(c.client, req, &out)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	if status != http.StatusOK {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", status), Data: out}, nil
+	}
+	if indicatorType == "ip" && out != nil {
+		out["search_term"] = value
+	}
+	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
+}
+
+// matches returns the IOCs in data. For IP searches only IOCs of exactly that IP (bare or
+// ip:port) are kept: a substring search for 1.2.3.4 also returns 1.2.3.45:443.
+func matches(data map[string]interface{}) []map[string]interface{} {
+	term := providerapi.String(data, "search_term")
+	var out []map[string]interface{}
+	for _, i := range providerapi.List(data, "data") {
+		ioc, _ := i.(map[string]interface{})
+		if ioc == nil {
+			continue
+		}
+		if term != "" {
+			v := providerapi.String(ioc, "ioc")
+			if v != term && !strings.HasPrefix(v, term+":") && !strings.HasPrefix(v, "["+term+"]:") {
+				continue
+			}
+		}
+		out = append(out, ioc)
+	}
+	return out
+}
+
+// Assess implements providerapi.Adapter. Every ThreatFox IOC is a reported threat; the highest
+// confidence_level among matches decides between malicious (75+) and suspicious. No match is unknown.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	iocs := matches(data)
+	if providerapi.String(data, "query_status") != "ok" || len(iocs) == 0 {
+		return providerapi.Unknown()
+	}
+
+	confidence := 0
+	var tags []string
+	var firstSeen, lastSeen *time.Time
+	seen := make(map[string]bool)
+	for _, ioc := range iocs {
+		if n, ok := providerapi.Number(ioc, "confidence_level"); ok && int(n) > confidence {
+			confidence = int(n)
+		}
+		for _, t := range append([]interface{}{ioc["threat_type"], ioc["malware_printable"]}, providerapi.List(ioc, "tags")...) {
+			if s, _ := t.(string); s != "" && !seen[s] {
+				seen[s] = true
+				tags = append(tags, s)
+			}
+		}
+		firstSeen = providerapi.Earliest(firstSeen, providerapi.Time(ioc, "first_seen"))
+		lastSeen = providerapi.Latest(lastSeen, providerapi.Time(ioc, "last_seen"))
+	}
+
+	v := providerapi.VerdictSuspicious
+	if confidence >= 75 {
+		v = providerapi.VerdictMalicious
+	}
+	a := providerapi.Assess(v, confidence)
+	a.Tags = tags
+	a.FirstSeen = firstSeen
+	a.LastSeen = lastSeen
+	return a
+}
+
+// MalwareFamilies implements misp.FamilyParser: ThreatFox names malware families by their
+// Malpedia display name.
+func (c *Client) MalwareFamilies(data map[string]interface{}) []string {
+	var out []string
+	for _, ioc := range matches(data) {
+		if name := providerapi.String(ioc, "malware_printable"); name != "" && name != "Unknown malware" {
+			out = append(out, name)
+		}
+	}
+	return out
+}
@@ -0,0 +1,43 @@
+package threatfox
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hermes/internal/providerapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup_IPIgnoresNearMisses(t *testing.T) {
+	var iocs string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, "search_ioc", body["query"])
+		assert.Equal(t, "1.2.3.4", body["search_term"])
+		_, _ = w.Write([]byte(`{"query_status":"ok","data":[` + iocs + `]}`))
+	}))
+	defer srv.Close()
+	c := NewClient("test-key")
+	c.baseURL = srv.URL
+
+	iocs = `{"ioc":"1.2.3.45:443","confidence_level":100,"threat_type":"botnet_cc","malware_printable":"Cobalt Strike"}`
+	res, err := c.Lookup(context.Background(), "ip", "1.2.3.4")
+	assert.NoError(t, err)
+	a := c.Assess(res.Data)
+	assert.Equal(t, providerapi.VerdictUnknown, a.Verdict)
+	assert.Empty(t, c.MalwareFamilies(res.Data))
+
+	iocs += `,{"ioc":"1.2.3.4:8080","confidence_level":50,"threat_type":"payload_delivery","malware_printable":"Mirai"}`
+	res, err = c.Lookup(context.Background(), "ip", "1.2.3.4")
+	assert.NoError(t, err)
+	a = c.Assess(res.Data)
+	assert.Equal(t, providerapi.VerdictSuspicious, a.Verdict)
+	assert.Equal(t, 50, a.Score)
+	assert.Equal(t, []string{"payload_delivery", "Mirai"}, a.Tags)
+	assert.Equal(t, []string{"Mirai"}, c.MalwareFamilies(res.Data))
+}
@@ -20,7 +20,7 @@ type Client struct {
 // NewClient creates a ThreatMiner client (no API key required for public API).
 func NewClient(apiKey string) *Client {
 	return &Client{
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
@@ -101,3 +101,8 @@ func (c *Client) lookupDomain(ctx context.Context, domain string) (providerapi.R
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. ThreatMiner WHOIS results carry no reputation signal.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	return providerapi.Unknown()
+}
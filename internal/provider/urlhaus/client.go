@@ -0,0 +1,155 @@
+package urlhaus
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"hermes/internal/indicator"
+	"hermes/internal/provider/abusech"
+	"hermes/internal/providerapi"
+)
+
+const baseURL = "https://urlhaus-api.abuse.ch/v1"
+
+// Client calls URLhaus (abuse.ch) API: URL, host and payload queries.
+type Client struct {
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewClient creates a URLhaus client. apiKey may be empty (Lookup will return not configured).
+func NewClient(apiKey string) *Client {
+	return &Client{
+		apiKey:  apiKey,
+		baseURL: baseURL,
+		client:  providerapi.NewHTTPClient(),
+	}
+}
+
+// Code implements providerapi.Adapter.
+func (c *Client) Code() string { return "urlhaus" }
+
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
+// SupportedTypes implements providerapi.Adapter.
+func (c *Client) SupportedTypes() []string {
+	return []string{"url", "domain", "ip", "hash"}
+}
+
+// HashSubtypes implements providerapi.HashSubtyper.
+func (c *Client) HashSubtypes() []string {
+	return []string{indicator.HashMD5, indicator.HashSHA256}
+}
+
+// Lookup implements providerapi.Adapter. Domains and IPs are host queries; hashes are payload
+// queries (MD5 or SHA-256).
+func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	if c.apiKey == "" {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "not configured"}, nil
+	}
+
+	form := url.Values{}
+	var endpoint string
+	switch indicatorType {
+	case "url":
+		endpoint = "/url/"
+		form.Set("url", value)
+	case "domain", "ip":
+		endpoint = "/host/"
+		form.Set("host", value)
+	case "hash":
+		endpoint = "/payload/"
+		switch len(value) {
+		case 32:
+			form.Set("md5_hash", value)
+		case 64:
+			form.Set("sha256_hash", value)
+		default:
+			return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported hash: URLhaus accepts MD5 or SHA-256"}, nil
+		}
+	default:
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
+	}
+
+	req, err := abusech.NewFormRequest(ctx, c.baseURL+endpoint, c.apiKey, form)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	var out map[string]interface{}
+	status, err := abusech.Do(c.client, req, &out)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	if status != http.StatusOK {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", status), Data: out}, nil
+	}
+	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
+}
+
+// Assess implements providerapi.Adapter. URLhaus only tracks malware distribution: an online URL
+// (or a host serving one) is malicious, a host or URL with only offline entries is suspicious, a
+// known payload is malicious. Not being listed says nothing.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	if providerapi.String(data, "query_status") != "ok" {
+		return providerapi.Unknown()
+	}
+
+	var a providerapi.Assessment
+	switch {
+	case providerapi.Get(data, "url_status") != nil: // URL query
+		a = statusAssessment(providerapi.String(data, "url_status") == "online")
+		a.Tags = appendTag(a.Tags, providerapi.String(data, "threat"))
+		a.FirstSeen = providerapi.Time(data, "date_added")
+		a.LastSeen = providerapi.Time(data, "last_online")
+	case providerapi.Get(data, "host") != nil: // host query
+		online := false
+		var tags []string
+		for _, u := range providerapi.List(data, "urls") {
+			entry, _ := u.(map[string]interface{})
+			online = online || providerapi.String(entry, "url_status") == "online"
+			tags = appendTag(tags, providerapi.String(entry, "threat"))
+			for _, t := range providerapi.List(entry, "tags") {
+				s, _ := t.(string)
+				tags = appendTag(tags, s)
+			}
+		}
+		a = statusAssessment(online)
+		a.Tags = tags
+		a.FirstSeen = providerapi.Time(data, "firstseen")
+	default: // payload query
+		a = providerapi.Assess(providerapi.VerdictMalicious, 100)
+		a.Tags = appendTag(a.Tags, providerapi.String(data, "signature"))
+		a.FirstSeen = providerapi.Time(data, "firstseen")
+		a.LastSeen = providerapi.Time(data, "lastseen")
+		return a
+	}
+	for _, t := range providerapi.List(data, "tags") {
+		s, _ := t.(string)
+		a.Tags = appendTag(a.Tags, s)
+	}
+	return a
+}
+
+func statusAssessment(online bool) providerapi.Assessment {
+	if online {
+		return providerapi.Assess(providerapi.VerdictMalicious, 100)
+	}
+	return providerapi.Assess(providerapi.VerdictSuspicious, 60)
+}
+
+// appendTag appends s unless it is empty or already present.
+func appendTag(tags []string, s string) []string {
+	if s == "" {
+		return tags
+	}
+	for _, t := range tags {
+		if t == s {
+			return tags
+		}
+	}
+	return append(tags, s)
+}
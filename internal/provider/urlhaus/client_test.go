@@ -0,0 +1,55 @@
+package urlhaus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"hermes/internal/providerapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLookup_Endpoints(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("Auth-Key"))
+		assert.NoError(t, r.ParseForm())
+		switch r.URL.Path {
+		case "/url/":
+			assert.Equal(t, "http://198.51.100.7/bins/x86", r.PostForm.Get("url"))
+			_, _ = w.Write([]byte(`{"query_status":"ok","url_status":"online","threat":"malware_download","date_added":"2024-03-01 12:00:00 UTC","tags":["elf","mirai"]}`))
+		case "/host/":
+			assert.Equal(t, "example.com", r.PostForm.Get("host"))
+			_, _ = w.Write([]byte(`{"query_status":"ok","host":"example.com","firstseen":"2023-01-01 00:00:00 UTC","urls":[{"url_status":"offline","threat":"malware_download","tags":["exe"]}]}`))
+		case "/payload/":
+			assert.Len(t, r.PostForm.Get("sha256_hash"), 64)
+			_, _ = w.Write([]byte(`{"query_status":"no_results"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	c := NewClient("test-key")
+	c.baseURL = srv.URL
+
+	res, err := c.Lookup(context.Background(), "url", "http://198.51.100.7/bins/x86")
+	assert.NoError(t, err)
+	a := c.Assess(res.Data)
+	assert.Equal(t, providerapi.VerdictMalicious, a.Verdict)
+	assert.Equal(t, []string{"malware_download", "elf", "mirai"}, a.Tags)
+	assert.NotNil(t, a.FirstSeen)
+
+	res, err = c.Lookup(context.Background(), "domain", "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, providerapi.VerdictSuspicious, c.Assess(res.Data).Verdict)
+
+	res, err = c.Lookup(context.Background(), "hash", "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f")
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, providerapi.VerdictUnknown, c.Assess(res.Data).Verdict)
+
+	res, err = c.Lookup(context.Background(), "hash", "3395856ce81f2b7382dee72602f798b642f14140")
+	assert.NoError(t, err)
+	assert.False(t, res.Success)
+}
@@ -4,25 +4,49 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
+	"time"
 
 	"hermes/internal/providerapi"
 )
 
-const baseURL = "https://urlscan.io/api/v1"
+const (
+	defaultBaseURL = "https://urlscan.io/api/v1"
+	// recentScanAge is how old a finished scan found by /search may be to be reused instead of
+	// submitting a new one.
+	recentScanAge = 24 * time.Hour
+	// deadlineMargin is kept free before the caller's deadline to return a pending result.
+	deadlineMargin = time.Second
+)
 
-// Client calls urlscan.io API.
+// Client calls urlscan.io API. URL scans run as providerapi.AsyncAdapter jobs.
 type Client struct {
-	apiKey string
-	client *http.Client
+	apiKey  string
+	client  *http.Client
+	baseURL string
+	// wait is how long Submit polls a new scan before returning it as a job.
+	wait time.Duration
+	// firstPoll is the delay between submitting a scan and the first poll (urlscan advises 10s);
+	// pollEvery is the delay between further polls.
+	firstPoll time.Duration
+	pollEvery time.Duration
 }
 
-// NewClient creates a urlscan.io client. apiKey may be empty (lower quota).
-func NewClient(apiKey string) *Client {
+// NewClient creates a urlscan.io client. apiKey may be empty (public scans only, lower quota),
+// so the client needs no credentials. URL lookups wait up to wait for a new scan to finish;
+// longer scans are returned as jobs to poll.
+func NewClient(apiKey string, wait time.Duration) *Client {
 	return &Client{
-		apiKey: apiKey,
-		client: &http.Client{},
+		apiKey:    apiKey,
+		client:    providerapi.NewHTTPClient(),
+		baseURL:   defaultBaseURL,
+		wait:      wait,
+		firstPoll: 10 * time.Second,
+		pollEvery: 2 * time.Second,
 	}
 }
 
@@ -34,63 +58,253 @@ func (c *Client) SupportedTypes() []string {
 	return []string{"url", "domain"}
 }
 
-// Lookup implements providerapi.Adapter. For URL we submit a scan and return result; for domain we search.
+// PassiveTypes implements providerapi.PassiveTyper: domain lookups search existing scans.
+func (c *Client) PassiveTypes() []string {
+	return []string{"domain"}
+}
+
+// Lookup implements providerapi.Adapter. For a URL it submits a job (see Submit) and reports it
+// as pending when the scan is not finished within the wait; for a domain it searches existing scans.
 func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	job, res, err := c.Submit(ctx, indicatorType, value)
+	if job != nil {
+		return providerapi.PendingResult(c.Code(), job), nil
+	}
+	return res, err
+}
+
+// Submit implements providerapi.AsyncAdapter. For a URL it reuses a scan from the last 24 hours
+// or submits a new one and polls its result until the wait passes, returning the scan as a job
+// only when it is still running then. Domain searches never need a job.
+func (c *Client) Submit(ctx context.Context, indicatorType string, value string) (*providerapi.Job, providerapi.Result, error) {
 	if indicatorType != "url" && indicatorType != "domain" {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
+		return nil, providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
+	}
+	if indicatorType == "domain" {
+		res, err := c.search(ctx, "domain:"+value, 100)
+		return nil, res, err
+	}
+
+	recent, err := c.search(ctx, fmt.Sprintf("task.url:%q AND date:>now-%dh", value, int(recentScanAge.Hours())), 1)
+	if err != nil {
+		return nil, recent, err
+	}
+	if uuid := providerapi.String(firstResult(recent.Data), "task", "uuid"); uuid != "" {
+		res, ready, err := c.result(ctx, uuid)
+		if err != nil || ready {
+			return nil, res, err
+		}
+	}
+
+	uuid, res, err := c.submitScan(ctx, value)
+	if uuid == "" {
+		return nil, res, err
+	}
+	submitted := time.Now()
+	until := submitted.Add(c.wait)
+	if dl, ok := ctx.Deadline(); ok && dl.Add(-deadlineMargin).Before(until) {
+		until = dl.Add(-deadlineMargin)
+	}
+	for next := submitted.Add(c.firstPoll); !next.After(until); next = time.Now().Add(c.pollEvery) {
+		t := time.NewTimer(time.Until(next))
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return c.job(uuid, submitted), providerapi.Result{}, nil
+		}
+		res, ready, err := c.result(ctx, uuid)
+		if err != nil || ready {
+			return nil, res, err
+		}
 	}
+	return c.job(uuid, submitted), providerapi.Result{}, nil
+}
+
+// Poll implements providerapi.AsyncAdapter: the scan is done once its result stops answering 404.
+func (c *Client) Poll(ctx context.Context, job providerapi.Job) (providerapi.JobStatus, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/result/"+url.PathEscape(job.ID)+"/", nil)
+	if err != nil {
+		return providerapi.JobStatus{}, err
+	}
+	var out map[string]interface{}
+	status, err := c.do(req, &out)
+	switch {
+	case err != nil:
+		return providerapi.JobStatus{}, err
+	case status == http.StatusNotFound:
+		return providerapi.JobStatus{RetryAfter: c.pollEvery}, nil
+	case status == http.StatusOK:
+		return providerapi.JobStatus{Done: true}, nil
+	case status == http.StatusGone:
+		return providerapi.JobStatus{Failed: "scan was deleted"}, nil
+	default:
+		return providerapi.JobStatus{}, fmt.Errorf("HTTP %d", status)
+	}
+}
 
-	if indicatorType == "url" {
-		return c.submitScan(ctx, value)
+// Collect implements providerapi.AsyncAdapter.
+func (c *Client) Collect(ctx context.Context, job providerapi.Job) (providerapi.Result, error) {
+	res, ready, err := c.result(ctx, job.ID)
+	if err == nil && !ready {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "scan not finished"}, errors.New("scan not finished")
 	}
-	return c.search(ctx, "domain:"+value)
+	return res, err
 }
 
-func (c *Client) submitScan(ctx context.Context, urlStr string) (providerapi.Result, error) {
+func (c *Client) job(uuid string, submitted time.Time) *providerapi.Job {
+	return &providerapi.Job{ID: uuid, Data: map[string]interface{}{
+		"uuid":         uuid,
+		"report_url":   "https://urlscan.io/result/" + uuid + "/",
+		"submitted_at": submitted.UTC().Format(time.RFC3339),
+	}}
+}
+
+// submitScan submits urlStr and returns the scan's uuid, or "" with the failed result.
+func (c *Client) submitScan(ctx context.Context, urlStr string) (string, providerapi.Result, error) {
 	body := map[string]string{"url": urlStr, "visibility": "private"}
 	if c.apiKey == "" {
 		body["visibility"] = "public"
 	}
 	raw, _ := json.Marshal(body)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/scan/", bytes.NewReader(raw))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/scan/", bytes.NewReader(raw))
 	if err != nil {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+		return "", providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		req.Header.Set("API-Key", c.apiKey)
-	}
 
-	resp, err := c.client.Do(req)
+	var out map[string]interface{}
+	status, err := c.do(req, &out)
 	if err != nil {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+		return "", providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
 	}
-	defer resp.Body.Close()
+	uuid := providerapi.String(out, "uuid")
+	if status != http.StatusOK || uuid == "" {
+		msg := providerapi.String(out, "message")
+		if msg == "" {
+			msg = "HTTP " + strconv.Itoa(status)
+		}
+		return "", providerapi.Result{ProviderCode: c.Code(), Success: false, Data: out, Error: msg}, nil
+	}
+	return uuid, providerapi.Result{}, nil
+}
 
+// result fetches a scan result; ready is false while urlscan still answers 404.
+func (c *Client) result(ctx context.Context, uuid string) (providerapi.Result, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/result/"+url.PathEscape(uuid)+"/", nil)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, false, err
+	}
 	var out map[string]interface{}
-	_ = json.NewDecoder(resp.Body).Decode(&out)
-	success := resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusAccepted
-	return providerapi.Result{ProviderCode: c.Code(), Success: success, Data: out, Error: fmt.Sprintf("HTTP %d", resp.StatusCode)}, nil
+	status, err := c.do(req, &out)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, false, err
+	}
+	switch status {
+	case http.StatusNotFound:
+		return providerapi.Result{}, false, nil
+	case http.StatusOK:
+		return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: summarize(out)}, true, nil
+	default:
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: fmt.Sprintf("HTTP %d", status)}, true, nil
+	}
 }
 
-func (c *Client) search(ctx context.Context, q string) (providerapi.Result, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/search/?q="+q, nil)
+func (c *Client) search(ctx context.Context, q string, size int) (providerapi.Result, error) {
+	u := c.baseURL + "/search/?q=" + url.QueryEscape(q) + "&size=" + strconv.Itoa(size)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
 	if err != nil {
 		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
 	}
+	var out map[string]interface{}
+	status, err := c.do(req, &out)
+	if err != nil {
+		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+	}
+	success := status == http.StatusOK
+	return providerapi.Result{ProviderCode: c.Code(), Success: success, Data: out, Error: fmt.Sprintf("HTTP %d", status)}, nil
+}
+
+func (c *Client) do(req *http.Request, out interface{}) (int, error) {
 	req.Header.Set("Accept", "application/json")
 	if c.apiKey != "" {
 		req.Header.Set("API-Key", c.apiKey)
 	}
-
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: err.Error()}, err
+		return 0, err
 	}
 	defer resp.Body.Close()
+	_ = json.NewDecoder(resp.Body).Decode(out)
+	return resp.StatusCode, nil
+}
 
-	var out map[string]interface{}
-	_ = json.NewDecoder(resp.Body).Decode(&out)
-	success := resp.StatusCode == http.StatusOK
-	return providerapi.Result{ProviderCode: c.Code(), Success: success, Data: out, Error: fmt.Sprintf("HTTP %d", resp.StatusCode)}, nil
+// summarize keeps the parts of a scan result worth storing: the full result lists every
+// request the page made and is far too large.
+func summarize(raw map[string]interface{}) map[string]interface{} {
+	uuid := providerapi.String(raw, "task", "uuid")
+	page := providerapi.Map(raw, "page")
+	out := map[string]interface{}{
+		"uuid":              uuid,
+		"url":               providerapi.String(raw, "task", "url"),
+		"scan_time":         providerapi.String(raw, "task", "time"),
+		"report_url":        providerapi.String(raw, "task", "reportURL"),
+		"screenshot_url":    providerapi.String(raw, "task", "screenshotURL"),
+		"verdicts":          providerapi.Map(raw, "verdicts"),
+		"contacted_domains": providerapi.List(raw, "lists", "domains"),
+		"contacted_ips":     providerapi.List(raw, "lists", "ips"),
+	}
+	if page != nil {
+		p := make(map[string]interface{})
+		for _, k := range []string{"url", "domain", "ip", "country", "server", "title", "status", "asn", "asnname"} {
+			if v, ok := page[k]; ok {
+				p[k] = v
+			}
+		}
+		out["page"] = p
+	}
+	if out["report_url"] == "" && uuid != "" {
+		out["report_url"] = "https://urlscan.io/result/" + uuid + "/"
+	}
+	return out
+}
+
+// firstResult returns the first hit of a search response, or nil.
+func firstResult(search map[string]interface{}) map[string]interface{} {
+	results := providerapi.List(search, "results")
+	if len(results) == 0 {
+		return nil
+	}
+	hit, _ := results[0].(map[string]interface{})
+	return hit
+}
+
+// Assess implements providerapi.Adapter. Finished scans are judged by urlscan's overall verdict
+// (score -100..100); domain searches carry no verdict.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	overall := providerapi.Map(data, "verdicts", "overall")
+	if overall == nil {
+		return providerapi.Unknown()
+	}
+	score, _ := providerapi.Number(overall, "score")
+	var a providerapi.Assessment
+	switch {
+	case providerapi.Bool(overall, "malicious"):
+		a = providerapi.Assess(providerapi.VerdictMalicious, int(score))
+	case score > 0:
+		a = providerapi.Assess(providerapi.VerdictSuspicious, int(score))
+	case providerapi.Bool(overall, "hasVerdicts"):
+		a = providerapi.Assess(providerapi.VerdictClean, 0)
+	default:
+		return providerapi.Unknown()
+	}
+	for _, k := range []string{"categories", "brands", "tags"} {
+		for _, v := range providerapi.List(overall, k) {
+			if s, ok := v.(string); ok && s != "" {
+				a.Tags = append(a.Tags, s)
+			}
+		}
+	}
+	a.LastSeen = providerapi.Time(data, "scan_time")
+	return a
 }
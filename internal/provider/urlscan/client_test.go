@@ -0,0 +1,127 @@
+package urlscan
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hermes/internal/providerapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const scanResult = `{"task":{"uuid":"scan-1","url":"https://example.com/login","time":"2025-02-20T09:00:00.000Z","reportURL":"https://urlscan.io/result/scan-1/","screenshotURL":"https://urlscan.io/screenshots/scan-1.png"},
+"page":{"url":"https://example.com/login","domain":"example.com","ip":"93.184.216.34","country":"US","title":"Sign in","status":"200"},
+"lists":{"domains":["example.com","cdn.example.net"],"ips":["93.184.216.34"]},
+"verdicts":{"overall":{"score":100,"malicious":true,"hasVerdicts":true,"categories":["phishing"],"brands":["microsoft"],"tags":[]}},
+"data":{"requests":[{"request":{}}]}}`
+
+func newTestClient(url string, wait time.Duration) *Client {
+	c := NewClient("test-key", wait)
+	c.baseURL = url
+	c.firstPoll, c.pollEvery = 10*time.Millisecond, 10*time.Millisecond
+	return c
+}
+
+func TestLookup_SubmitAndPoll(t *testing.T) {
+	var polls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("API-Key"))
+		switch {
+		case r.URL.Path == "/search/":
+			assert.True(t, strings.HasPrefix(r.URL.Query().Get("q"), `task.url:"https://example.com/login"`))
+			_, _ = w.Write([]byte(`{"results":[],"total":0}`))
+		case r.URL.Path == "/scan/" && r.Method == http.MethodPost:
+			_, _ = w.Write([]byte(`{"uuid":"scan-1","message":"Submission successful"}`))
+		case r.URL.Path == "/result/scan-1/":
+			if atomic.AddInt32(&polls, 1) < 3 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(scanResult))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, time.Second)
+
+	res, err := c.Lookup(context.Background(), "url", "https://example.com/login")
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.False(t, res.Pending)
+	assert.Equal(t, "https://urlscan.io/screenshots/scan-1.png", res.Data["screenshot_url"])
+	assert.Equal(t, []interface{}{"example.com", "cdn.example.net"}, res.Data["contacted_domains"])
+	assert.NotContains(t, res.Data, "data")
+
+	a := c.Assess(res.Data)
+	assert.Equal(t, providerapi.VerdictMalicious, a.Verdict)
+	assert.Equal(t, 100, a.Score)
+	assert.Equal(t, []string{"phishing", "microsoft"}, a.Tags)
+}
+
+func TestLookup_PendingThenPoll(t *testing.T) {
+	var ready atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/":
+			_, _ = w.Write([]byte(`{"results":[]}`))
+		case "/scan/":
+			_, _ = w.Write([]byte(`{"uuid":"scan-1"}`))
+		case "/result/scan-1/":
+			if !ready.Load() {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(scanResult))
+		}
+	}))
+	defer srv.Close()
+	c := newTestClient(srv.URL, 0)
+
+	res, err := c.Lookup(context.Background(), "url", "https://example.com/login")
+	assert.NoError(t, err)
+	assert.True(t, res.Pending)
+	assert.Equal(t, "scan-1", res.Data["job_id"])
+
+	job := providerapi.Job{ID: "scan-1", Data: res.Data}
+	st, err := c.Poll(context.Background(), job)
+	assert.NoError(t, err)
+	assert.False(t, st.Done)
+
+	ready.Store(true)
+	st, err = c.Poll(context.Background(), job)
+	assert.NoError(t, err)
+	assert.True(t, st.Done)
+	res, err = c.Collect(context.Background(), job)
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+	assert.Equal(t, "scan-1", res.Data["uuid"])
+}
+
+func TestLookup_ReusesRecentScan(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/search/":
+			_, _ = w.Write([]byte(`{"results":[{"task":{"uuid":"scan-1"}}],"total":1}`))
+		case "/result/scan-1/":
+			_, _ = w.Write([]byte(scanResult))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	res, err := newTestClient(srv.URL, time.Second).Lookup(context.Background(), "url", "https://example.com/login")
+	assert.NoError(t, err)
+	assert.True(t, res.Success)
+}
+
+func TestConfiguredWithoutKey(t *testing.T) {
+	// Without a key scans are public, but the client still works.
+	assert.True(t, providerapi.IsConfigured(NewClient("", time.Second)))
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 
+	"hermes/internal/indicator"
 	"hermes/internal/providerapi"
 )
 
@@ -23,18 +24,26 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "virustotal" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
 	return []string{"ip", "domain", "url", "hash"}
 }
 
+// HashSubtypes implements providerapi.HashSubtyper.
+func (c *Client) HashSubtypes() []string {
+	return []string{indicator.HashMD5, indicator.HashSHA1, indicator.HashSHA256}
+}
+
 // Lookup implements providerapi.Adapter.
 func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
 	if c.apiKey == "" {
@@ -79,3 +88,66 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
 
+// Assess implements providerapi.Adapter. The verdict follows last_analysis_stats: three or more
+// malicious engines is malicious, any malicious/suspicious engine is suspicious; the score is the
+// weighted detection ratio.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	attrs := providerapi.Map(data, "data", "attributes")
+	stats := providerapi.Map(attrs, "last_analysis_stats")
+	if stats == nil {
+		return providerapi.Unknown()
+	}
+	var engines float64
+	for _, v := range stats {
+		if n, ok := v.(float64); ok {
+			engines += n
+		}
+	}
+	malicious, _ := providerapi.Number(stats, "malicious")
+	suspicious, _ := providerapi.Number(stats, "suspicious")
+	if engines == 0 {
+		return providerapi.Unknown()
+	}
+	score := int((malicious + suspicious/2) / engines * 100)
+	verdict := providerapi.VerdictClean
+	switch {
+	case malicious >= 3:
+		verdict = providerapi.VerdictMalicious
+	case malicious > 0 || suspicious > 0:
+		verdict = providerapi.VerdictSuspicious
+	}
+	a := providerapi.Assess(verdict, score)
+	for _, t := range providerapi.List(attrs, "tags") {
+		if s, ok := t.(string); ok {
+			a.Tags = append(a.Tags, s)
+		}
+	}
+	if label := providerapi.String(attrs, "popular_threat_classification", "suggested_threat_label"); label != "" {
+		a.Tags = append(a.Tags, label)
+	}
+	a.FirstSeen = providerapi.Time(attrs, "first_submission_date")
+	a.LastSeen = providerapi.Latest(providerapi.Time(attrs, "last_analysis_date"), providerapi.Time(attrs, "last_submission_date"))
+	return a
+}
+
+// ReportFields implements report.Fielder.
+func (c *Client) ReportFields() []string {
+	return []string{"detection_ratio"}
+}
+
+// ReportValues implements report.Fielder. The detection ratio is malicious engines over all
+// engines of the last analysis, e.g. "12/94".
+func (c *Client) ReportValues(data map[string]interface{}) []string {
+	stats := providerapi.Map(data, "data", "attributes", "last_analysis_stats")
+	var engines float64
+	for _, v := range stats {
+		if n, ok := v.(float64); ok {
+			engines += n
+		}
+	}
+	if engines == 0 {
+		return []string{""}
+	}
+	malicious, _ := providerapi.Number(stats, "malicious")
+	return []string{fmt.Sprintf("%d/%d", int(malicious), int(engines))}
+}
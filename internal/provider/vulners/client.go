@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"hermes/internal/cve"
 	"hermes/internal/providerapi"
 )
 
@@ -22,27 +24,30 @@ type Client struct {
 func NewClient(apiKey string) *Client {
 	return &Client{
 		apiKey: apiKey,
-		client: &http.Client{},
+		client: providerapi.NewHTTPClient(),
 	}
 }
 
 // Code implements providerapi.Adapter.
 func (c *Client) Code() string { return "vulners" }
 
+// Configured implements providerapi.Configurer.
+func (c *Client) Configured() bool { return c.apiKey != "" }
+
 // SupportedTypes implements providerapi.Adapter.
 func (c *Client) SupportedTypes() []string {
-	return []string{"hash"} // CVE-ID or search query
+	return []string{"cve"}
 }
 
-// Lookup implements providerapi.Adapter. value can be CVE-ID (e.g. CVE-2024-1234) or search query.
+// Lookup implements providerapi.Adapter. value is a CVE-ID (e.g. CVE-2024-1234); bulletins
+// referencing it are returned.
 func (c *Client) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
 	if c.apiKey == "" {
 		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "not configured"}, nil
 	}
-	if indicatorType != "hash" {
+	if indicatorType != "cve" {
 		return providerapi.Result{ProviderCode: c.Code(), Success: false, Error: "unsupported type: " + indicatorType}, nil
 	}
-	// Vulners: use value as CVE ID (cvelist:value) or generic search
 	query := "cvelist:" + value
 	payload, _ := json.Marshal(map[string]string{"query": query})
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL, bytes.NewReader(payload))
@@ -68,3 +73,74 @@ func (c *Client) Lookup(ctx context.Context, indicatorType string, value string)
 	}
 	return providerapi.Result{ProviderCode: c.Code(), Success: true, Data: out}, nil
 }
+
+// Assess implements providerapi.Adapter. Vulnerability bulletins carry no malicious/clean reading;
+// only the publication dates of the first match are normalized.
+func (c *Client) Assess(data map[string]interface{}) providerapi.Assessment {
+	a := providerapi.Unknown()
+	hits := providerapi.List(data, "data", "search")
+	if len(hits) == 0 {
+		return a
+	}
+	first, _ := hits[0].(map[string]interface{})
+	a.FirstSeen = providerapi.Time(first, "_source", "published")
+	a.LastSeen = providerapi.Time(first, "_source", "modified")
+	return a
+}
+
+// ParseCVE implements cve.Parser. The CVE's own bulletin supplies the description, dates and
+// scores; CWEs, references and affected CPEs are gathered from every bulletin in the hits.
+func (c *Client) ParseCVE(data map[string]interface{}) (cve.Record, bool) {
+	hits := providerapi.List(data, "data", "search")
+	if len(hits) == 0 {
+		return cve.Record{}, false
+	}
+	rec := cve.Record{Sources: []string{c.Code()}}
+	for _, h := range hits {
+		hit, _ := h.(map[string]interface{})
+		src := providerapi.Map(hit, "_source")
+		if providerapi.String(src, "type") == "cve" && rec.ID == "" {
+			rec.ID = providerapi.String(src, "id")
+			rec.Description = providerapi.String(src, "description")
+			rec.Published = providerapi.Time(src, "published")
+			rec.Modified = providerapi.Time(src, "modified")
+			for _, key := range []string{"cvss4", "cvss3", "cvss2"} {
+				metric := providerapi.Map(src, key)
+				for _, inner := range metric {
+					if m, ok := inner.(map[string]interface{}); ok && providerapi.String(m, "vectorString") != "" {
+						metric = m
+						break
+					}
+				}
+				vector := providerapi.String(metric, "vectorString")
+				if vector == "" {
+					continue
+				}
+				score, _ := providerapi.Number(metric, "baseScore")
+				rec.CVSS = append(rec.CVSS, cve.CVSS{
+					Version:   cve.VersionFromVector(vector),
+					Vector:    vector,
+					BaseScore: score,
+					Severity:  providerapi.String(metric, "baseSeverity"),
+					Source:    c.Code(),
+				})
+			}
+		}
+		for _, w := range providerapi.List(src, "cwe") {
+			if s, ok := w.(string); ok && strings.HasPrefix(s, "CWE-") {
+				rec.CWEs = append(rec.CWEs, s)
+			}
+		}
+		for _, r := range providerapi.List(src, "references") {
+			if s, ok := r.(string); ok {
+				rec.References = append(rec.References, s)
+			}
+		}
+		for _, cpe := range providerapi.List(src, "cpe23") {
+			if s, ok := cpe.(string); ok {
+				rec.CPEs = append(rec.CPEs, s)
+			}
+		}
+	}
+	return rec, rec.ID != ""
+}
@@ -1,6 +1,13 @@
 package providerapi
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+// ErrRateLimited is returned by Lookup when the call was refused locally to stay within the
+// provider's rate limit; the vendor was not contacted.
+var ErrRateLimited = errors.New("rate limited")
 
 // Result holds raw response from a provider for storage and API response.
 type Result struct {
@@ -8,12 +15,75 @@ type Result struct {
 	Success      bool
 	Data         map[string]interface{}
 	Error        string
+	// Pending means the provider accepted the lookup but has no answer yet (an AsyncAdapter job
+	// still running, see PendingResult); Data identifies the job.
+	Pending bool
 }
 
 // Adapter is the common interface for all security providers.
-// indicatorType is one of: ip, domain, url, hash, email.
+// indicatorType is one of: ip, domain, url, hash, email, cve.
+// Assess normalizes a successful Lookup's Data (also cached Data) into a verdict and score.
 type Adapter interface {
 	Code() string
 	Lookup(ctx context.Context, indicatorType string, value string) (Result, error)
 	SupportedTypes() []string
+	Assess(data map[string]interface{}) Assessment
+}
+
+// Base returns the adapter underneath any wrappers (policy, rate limiting) that expose
+// Unwrap() Adapter, so callers can check for optional interfaces the provider implements.
+func Base(a Adapter) Adapter {
+	for {
+		u, ok := a.(interface{ Unwrap() Adapter })
+		if !ok {
+			return a
+		}
+		a = u.Unwrap()
+	}
+}
+
+// HashSubtyper is implemented by adapters that accept only some hash algorithms for type hash
+// (subtypes as classified by the indicator package, e.g. "md5", "sha256").
+type HashSubtyper interface {
+	HashSubtypes() []string
+}
+
+// AcceptsHash reports whether a accepts hashes of subtype; adapters without HashSubtyper accept all.
+func AcceptsHash(a Adapter, subtype string) bool {
+	h, ok := Base(a).(HashSubtyper)
+	if !ok {
+		return true
+	}
+	for _, s := range h.HashSubtypes() {
+		if s == subtype {
+			return true
+		}
+	}
+	return false
+}
+
+// Configurer is implemented by adapters that need credentials; Configured reports whether they
+// were given. Lookups of unconfigured adapters fail with "not configured".
+type Configurer interface {
+	Configured() bool
+}
+
+// IsConfigured reports whether a has the credentials it needs; adapters without Configurer need none.
+func IsConfigured(a Adapter) bool {
+	c, ok := Base(a).(Configurer)
+	return !ok || c.Configured()
+}
+
+// Contacted reports whether a lookup of indicatorType by a that returned err reached the vendor:
+// a is configured and supports the type, and the local rate limit did not refuse the call.
+func Contacted(a Adapter, indicatorType string, err error) bool {
+	if errors.Is(err, ErrRateLimited) || !IsConfigured(a) {
+		return false
+	}
+	for _, t := range a.SupportedTypes() {
+		if t == indicatorType {
+			return true
+		}
+	}
+	return false
 }
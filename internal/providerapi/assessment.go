@@ -0,0 +1,192 @@
+package providerapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Verdict is the provider-independent classification of an indicator.
+type Verdict string
+
+const (
+	VerdictMalicious  Verdict = "malicious"
+	VerdictSuspicious Verdict = "suspicious"
+	VerdictClean      Verdict = "clean"
+	VerdictUnknown    Verdict = "unknown"
+)
+
+// Assessment is an adapter's normalized reading of its raw response. Score is 0–100 (higher is
+// worse) and always lies in the band of Verdict (see Assess); Data keeps the raw response.
+type Assessment struct {
+	Verdict   Verdict
+	Score     int
+	Tags      []string
+	FirstSeen *time.Time
+	LastSeen  *time.Time
+}
+
+// Score bands: clean 0–24, suspicious 25–74, malicious 75–100; unknown is always 0.
+const (
+	suspiciousFloor = 25
+	maliciousFloor  = 75
+)
+
+// Unknown is the assessment for responses that carry no reputation signal.
+func Unknown() Assessment {
+	return Assessment{Verdict: VerdictUnknown}
+}
+
+// Assess builds an assessment, clamping score into the band of v so verdict and score never disagree.
+func Assess(v Verdict, score int) Assessment {
+	lo, hi := 0, 100
+	switch v {
+	case VerdictMalicious:
+		lo = maliciousFloor
+	case VerdictSuspicious:
+		lo, hi = suspiciousFloor, maliciousFloor-1
+	case VerdictClean:
+		hi = suspiciousFloor - 1
+	default:
+		return Unknown()
+	}
+	if score < lo {
+		score = lo
+	}
+	if score > hi {
+		score = hi
+	}
+	return Assessment{Verdict: v, Score: score}
+}
+
+// VerdictForScore maps a 0–100 score to its verdict band.
+func VerdictForScore(score int) Verdict {
+	switch {
+	case score >= maliciousFloor:
+		return VerdictMalicious
+	case score >= suspiciousFloor:
+		return VerdictSuspicious
+	default:
+		return VerdictClean
+	}
+}
+
+// Helpers for reading decoded JSON (map[string]interface{}) in Assess implementations.
+
+// Get walks nested objects by key; it returns nil when any step is missing.
+func Get(m map[string]interface{}, path ...string) interface{} {
+	var cur interface{} = m
+	for _, k := range path {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		cur = obj[k]
+	}
+	return cur
+}
+
+// Map returns the object at path, or nil.
+func Map(m map[string]interface{}, path ...string) map[string]interface{} {
+	v, _ := Get(m, path...).(map[string]interface{})
+	return v
+}
+
+// List returns the array at path, or nil.
+func List(m map[string]interface{}, path ...string) []interface{} {
+	v, _ := Get(m, path...).([]interface{})
+	return v
+}
+
+// String returns the value at path formatted as a string ("" when missing).
+func String(m map[string]interface{}, path ...string) string {
+	switch v := Get(m, path...).(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Number returns the numeric value at path; numeric strings are accepted.
+func Number(m map[string]interface{}, path ...string) (float64, bool) {
+	switch v := Get(m, path...).(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// Bool returns the boolean at path; "true"/"1" strings are accepted.
+func Bool(m map[string]interface{}, path ...string) bool {
+	switch v := Get(m, path...).(type) {
+	case bool:
+		return v
+	case string:
+		return v == "true" || v == "1"
+	case float64:
+		return v != 0
+	}
+	return false
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04:05 MST",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.000",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+// Time parses the value at path as RFC 3339, a common date layout, or Unix seconds.
+func Time(m map[string]interface{}, path ...string) *time.Time {
+	switch v := Get(m, path...).(type) {
+	case float64:
+		if v <= 0 {
+			return nil
+		}
+		t := time.Unix(int64(v), 0).UTC()
+		return &t
+	case string:
+		return ParseTime(v)
+	}
+	return nil
+}
+
+// ParseTime parses s using the layouts Time accepts; it returns nil when none match.
+func ParseTime(s string) *time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			t = t.UTC()
+			return &t
+		}
+	}
+	return nil
+}
+
+// Earliest returns the earlier of two optional times.
+func Earliest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.Before(*a)) {
+		return b
+	}
+	return a
+}
+
+// Latest returns the later of two optional times.
+func Latest(a, b *time.Time) *time.Time {
+	if a == nil || (b != nil && b.After(*a)) {
+		return b
+	}
+	return a
+}
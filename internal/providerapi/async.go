@@ -0,0 +1,119 @@
+package providerapi
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrNotAsync is returned by Submit, Poll and Collect for adapters that do not implement
+// AsyncAdapter.
+var ErrNotAsync = errors.New("provider does not run asynchronous jobs")
+
+// Job is a provider-side job (a scan, sandbox run or assessment) started by AsyncAdapter.Submit.
+// It is stored between polls, so Data must survive a JSON round trip.
+type Job struct {
+	// ID identifies the job at the provider (e.g. a scan uuid).
+	ID   string
+	Data map[string]interface{}
+}
+
+// JobStatus is the outcome of AsyncAdapter.Poll.
+type JobStatus struct {
+	// Done means the result can be fetched with Collect.
+	Done bool
+	// Failed, when not empty, is why the job will never finish (e.g. the host does not resolve).
+	Failed string
+	// Data, when not nil, replaces the job's Data (e.g. to record the job's phase).
+	Data map[string]interface{}
+	// RetryAfter is the provider's advised delay before the next poll; 0 leaves it to the caller.
+	RetryAfter time.Duration
+}
+
+// AsyncAdapter is implemented by adapters whose lookups run as provider-side jobs that outlive
+// a request. Submit starts a job, or returns a nil job and the final Result when none is needed
+// (e.g. a recent report is reused); Poll checks on the job and Collect fetches its result once
+// Poll reports Done. Errors from Poll and Collect are transient: the caller retries later.
+type AsyncAdapter interface {
+	Adapter
+	Submit(ctx context.Context, indicatorType string, value string) (*Job, Result, error)
+	Poll(ctx context.Context, job Job) (JobStatus, error)
+	Collect(ctx context.Context, job Job) (Result, error)
+}
+
+// PassiveTyper is implemented by async adapters whose lookups of some indicator types only read
+// existing data (e.g. a search) and never start a provider-side job.
+type PassiveTyper interface {
+	PassiveTypes() []string
+}
+
+// PassiveTypes returns the indicator types a can look up without starting a provider-side job:
+// all supported types for synchronous adapters, PassiveTyper's for async ones, none otherwise.
+func PassiveTypes(a Adapter) []string {
+	if !IsAsync(a) {
+		return a.SupportedTypes()
+	}
+	if p, ok := Base(a).(PassiveTyper); ok {
+		return p.PassiveTypes()
+	}
+	return nil
+}
+
+// IsAsync reports whether the adapter underneath a's wrappers implements AsyncAdapter.
+func IsAsync(a Adapter) bool {
+	_, ok := Base(a).(AsyncAdapter)
+	return ok
+}
+
+// asyncOf returns the outermost AsyncAdapter of a's wrapper chain, so wrappers that forward the
+// async calls (policies, rate limits) apply to them as to Lookup.
+func asyncOf(a Adapter) (AsyncAdapter, bool) {
+	if !IsAsync(a) {
+		return nil, false
+	}
+	for {
+		if x, ok := a.(AsyncAdapter); ok {
+			return x, true
+		}
+		a = a.(interface{ Unwrap() Adapter }).Unwrap()
+	}
+}
+
+// Submit starts a job through a's wrappers.
+func Submit(ctx context.Context, a Adapter, indicatorType string, value string) (*Job, Result, error) {
+	x, ok := asyncOf(a)
+	if !ok {
+		return nil, Result{ProviderCode: a.Code(), Success: false, Error: ErrNotAsync.Error()}, ErrNotAsync
+	}
+	return x.Submit(ctx, indicatorType, value)
+}
+
+// Poll checks on a job through a's wrappers.
+func Poll(ctx context.Context, a Adapter, job Job) (JobStatus, error) {
+	x, ok := asyncOf(a)
+	if !ok {
+		return JobStatus{}, ErrNotAsync
+	}
+	return x.Poll(ctx, job)
+}
+
+// Collect fetches a finished job's result through a's wrappers.
+func Collect(ctx context.Context, a Adapter, job Job) (Result, error) {
+	x, ok := asyncOf(a)
+	if !ok {
+		return Result{ProviderCode: a.Code(), Success: false, Error: ErrNotAsync.Error()}, ErrNotAsync
+	}
+	return x.Collect(ctx, job)
+}
+
+// PendingResult is the Lookup result of an async adapter whose job is still running: Data is
+// the job's Data with its job_id and status "pending".
+func PendingResult(code string, job *Job) Result {
+	data := make(map[string]interface{}, len(job.Data)+2)
+	for k, v := range job.Data {
+		data[k] = v
+	}
+	data["job_id"] = job.ID
+	data["status"] = "pending"
+	return Result{ProviderCode: code, Pending: true, Data: data}
+}
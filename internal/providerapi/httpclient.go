@@ -0,0 +1,216 @@
+package providerapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Policy controls timeouts and retries of a provider's outbound HTTP calls.
+type Policy struct {
+	// Timeout bounds each HTTP attempt (0 = no per-attempt timeout).
+	Timeout time.Duration
+	// MaxRetries is the number of extra attempts after a 429, 5xx or transport error.
+	MaxRetries int
+	// Backoff is the delay before the first retry; it doubles on every further retry.
+	// A Retry-After header from the provider takes precedence.
+	Backoff time.Duration
+}
+
+type policyKey struct{}
+
+// ContextWithPolicy returns a context whose HTTP calls made through NewHTTPClient follow p.
+func ContextWithPolicy(ctx context.Context, p Policy) context.Context {
+	return context.WithValue(ctx, policyKey{}, p)
+}
+
+// PolicyFromContext returns the Policy attached by ContextWithPolicy, if any.
+func PolicyFromContext(ctx context.Context) (Policy, bool) {
+	p, ok := ctx.Value(policyKey{}).(Policy)
+	return p, ok
+}
+
+// NewHTTPClient returns the http.Client adapters use for vendor calls. It applies the Policy
+// carried by the request context (see WithPolicy); without one it behaves like a plain client.
+// Each attempt is traced as a child of the span in the request context.
+func NewHTTPClient() *http.Client {
+	return &http.Client{Transport: &retryTransport{base: &tracingTransport{base: http.DefaultTransport}}}
+}
+
+// WithPolicy wraps an adapter so every Lookup runs under p.
+func WithPolicy(a Adapter, p Policy) Adapter {
+	return &policyAdapter{Adapter: a, policy: p}
+}
+
+type policyAdapter struct {
+	Adapter
+	policy Policy
+}
+
+func (a *policyAdapter) Lookup(ctx context.Context, indicatorType string, value string) (Result, error) {
+	return a.Adapter.Lookup(ContextWithPolicy(ctx, a.policy), indicatorType, value)
+}
+
+// Submit, Poll and Collect implement AsyncAdapter for adapters underneath that do.
+func (a *policyAdapter) Submit(ctx context.Context, indicatorType string, value string) (*Job, Result, error) {
+	return Submit(ContextWithPolicy(ctx, a.policy), a.Adapter, indicatorType, value)
+}
+
+func (a *policyAdapter) Poll(ctx context.Context, job Job) (JobStatus, error) {
+	return Poll(ContextWithPolicy(ctx, a.policy), a.Adapter, job)
+}
+
+func (a *policyAdapter) Collect(ctx context.Context, job Job) (Result, error) {
+	return Collect(ContextWithPolicy(ctx, a.policy), a.Adapter, job)
+}
+
+// Unwrap returns the wrapped adapter.
+func (a *policyAdapter) Unwrap() Adapter { return a.Adapter }
+
+// retryTransport retries 429/5xx responses and transport errors with backoff, honoring Retry-After.
+type retryTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	p, ok := PolicyFromContext(req.Context())
+	if !ok {
+		return t.base.RoundTrip(req)
+	}
+	parent := req.Context()
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(parent)
+			r.Body = body
+		}
+		cancel := context.CancelFunc(func() {})
+		if p.Timeout > 0 {
+			var ctx context.Context
+			ctx, cancel = context.WithTimeout(parent, p.Timeout)
+			r = r.Clone(ctx)
+		}
+
+		resp, err := t.base.RoundTrip(r)
+		if err == nil && !retryable(resp.StatusCode) {
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := p.Backoff << attempt
+		if err == nil {
+			if ra, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = ra
+			}
+		}
+		if !canRetry(req, p, attempt, delay) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+		if err == nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		cancel()
+
+		select {
+		case <-time.After(delay):
+		case <-parent.Done():
+			return nil, parent.Err()
+		}
+	}
+}
+
+// canRetry reports whether another attempt is allowed and can finish waiting before the caller's deadline.
+func canRetry(req *http.Request, p Policy, attempt int, delay time.Duration) bool {
+	ctx := req.Context()
+	if attempt >= p.MaxRetries || ctx.Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if dl, ok := ctx.Deadline(); ok && time.Until(dl) < delay {
+		return false
+	}
+	return true
+}
+
+func retryable(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryAfter parses a Retry-After header given either as seconds or as an HTTP date.
+func retryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		d := time.Until(at)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// tracingTransport runs each request in a client span and records its HTTP status on the
+// calling adapter's span too. Only method and host are recorded: paths and queries often carry
+// the indicator.
+type tracingTransport struct {
+	base http.RoundTripper
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	caller := trace.SpanFromContext(req.Context())
+	ctx, span := otel.Tracer("hermes").Start(req.Context(), "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.ServerAddress(req.URL.Hostname()),
+		))
+	defer span.End()
+	resp, err := t.base.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		span.SetStatus(codes.Error, "transport error")
+		return nil, err
+	}
+	status := semconv.HTTPResponseStatusCode(resp.StatusCode)
+	span.SetAttributes(status)
+	caller.SetAttributes(status)
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, resp.Status)
+	}
+	return resp, nil
+}
+
+// cancelBody releases the per-attempt timeout once the adapter has finished reading the body.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
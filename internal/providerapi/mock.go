@@ -4,9 +4,10 @@ import "context"
 
 // MockAdapter is a test double for Adapter.
 type MockAdapter struct {
-	CodeFunc          func() string
-	LookupFunc        func(ctx context.Context, indicatorType string, value string) (Result, error)
+	CodeFunc           func() string
+	LookupFunc         func(ctx context.Context, indicatorType string, value string) (Result, error)
 	SupportedTypesFunc func() []string
+	AssessFunc         func(data map[string]interface{}) Assessment
 }
 
 func (m *MockAdapter) Code() string {
@@ -29,3 +30,10 @@ func (m *MockAdapter) SupportedTypes() []string {
 	}
 	return []string{"ip", "domain", "url"}
 }
+
+func (m *MockAdapter) Assess(data map[string]interface{}) Assessment {
+	if m.AssessFunc != nil {
+		return m.AssessFunc(data)
+	}
+	return Unknown()
+}
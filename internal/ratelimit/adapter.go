@@ -0,0 +1,58 @@
+package ratelimit
+
+import (
+	"context"
+
+	"hermes/internal/providerapi"
+)
+
+// Adapter wraps a providerapi.Adapter so calls over the limiter's budget never reach the vendor;
+// they return providerapi.ErrRateLimited instead.
+type Adapter struct {
+	providerapi.Adapter
+	limiter *Limiter
+}
+
+// Wrap returns a for use with limiter l.
+func Wrap(a providerapi.Adapter, l *Limiter) *Adapter {
+	return &Adapter{Adapter: a, limiter: l}
+}
+
+// Lookup implements providerapi.Adapter.
+func (a *Adapter) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	if !a.limiter.Acquire(ctx) {
+		return providerapi.Result{ProviderCode: a.Code(), Success: false, Error: "rate limited"}, providerapi.ErrRateLimited
+	}
+	return a.Adapter.Lookup(ctx, indicatorType, value)
+}
+
+// Submit implements providerapi.AsyncAdapter for adapters underneath that do. Submit, Poll and
+// Collect each take a token.
+func (a *Adapter) Submit(ctx context.Context, indicatorType string, value string) (*providerapi.Job, providerapi.Result, error) {
+	if !a.limiter.Acquire(ctx) {
+		return nil, providerapi.Result{ProviderCode: a.Code(), Success: false, Error: "rate limited"}, providerapi.ErrRateLimited
+	}
+	return providerapi.Submit(ctx, a.Adapter, indicatorType, value)
+}
+
+// Poll implements providerapi.AsyncAdapter.
+func (a *Adapter) Poll(ctx context.Context, job providerapi.Job) (providerapi.JobStatus, error) {
+	if !a.limiter.Acquire(ctx) {
+		return providerapi.JobStatus{}, providerapi.ErrRateLimited
+	}
+	return providerapi.Poll(ctx, a.Adapter, job)
+}
+
+// Collect implements providerapi.AsyncAdapter.
+func (a *Adapter) Collect(ctx context.Context, job providerapi.Job) (providerapi.Result, error) {
+	if !a.limiter.Acquire(ctx) {
+		return providerapi.Result{ProviderCode: a.Code(), Success: false, Error: "rate limited"}, providerapi.ErrRateLimited
+	}
+	return providerapi.Collect(ctx, a.Adapter, job)
+}
+
+// Limiter returns the limiter guarding the adapter.
+func (a *Adapter) Limiter() *Limiter { return a.limiter }
+
+// Unwrap returns the wrapped adapter.
+func (a *Adapter) Unwrap() providerapi.Adapter { return a.Adapter }
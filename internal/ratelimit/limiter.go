@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket refilled at perMin tokens per minute with a burst of perMin.
+// In queue mode Acquire waits for a token (as long as the caller's deadline allows);
+// otherwise it fails immediately when the bucket is empty.
+type Limiter struct {
+	mu     sync.Mutex
+	perMin int
+	queue  bool
+	tokens float64
+	last   time.Time
+}
+
+// NewLimiter creates a limiter. perMin <= 0 means unlimited.
+func NewLimiter(perMin int, queue bool) *Limiter {
+	l := &Limiter{}
+	l.Configure(perMin, queue)
+	return l
+}
+
+// Configure changes the rate and mode; the bucket starts full when the rate changes.
+func (l *Limiter) Configure(perMin int, queue bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if perMin != l.perMin {
+		l.tokens = float64(perMin)
+		l.last = time.Now()
+	}
+	l.perMin = perMin
+	l.queue = queue
+}
+
+// Rate returns the configured tokens per minute (0 = unlimited).
+func (l *Limiter) Rate() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.perMin
+}
+
+// Acquire takes one token. It returns false when the call must be rejected: the bucket is empty
+// in reject mode, or in queue mode the wait would outlast ctx.
+func (l *Limiter) Acquire(ctx context.Context) bool {
+	l.mu.Lock()
+	if l.perMin <= 0 {
+		l.mu.Unlock()
+		return true
+	}
+	now := time.Now()
+	perSec := float64(l.perMin) / 60
+	l.tokens = math.Min(float64(l.perMin), l.tokens+now.Sub(l.last).Seconds()*perSec)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		l.mu.Unlock()
+		return true
+	}
+	wait := time.Duration((1 - l.tokens) / perSec * float64(time.Second))
+	if !l.queue {
+		l.mu.Unlock()
+		return false
+	}
+	if dl, ok := ctx.Deadline(); ok && time.Until(dl) < wait {
+		l.mu.Unlock()
+		return false
+	}
+	// Reserve the token now so concurrent callers queue behind us.
+	l.tokens--
+	l.mu.Unlock()
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return false
+	}
+}
+
+// TryAcquire takes one token without waiting, whatever the mode. It returns whether a token was
+// taken, the whole tokens left and, when none are left, how long until the next one.
+func (l *Limiter) TryAcquire() (ok bool, remaining int, wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.perMin <= 0 {
+		return true, 0, 0
+	}
+	now := time.Now()
+	perSec := float64(l.perMin) / 60
+	l.tokens = math.Min(float64(l.perMin), l.tokens+now.Sub(l.last).Seconds()*perSec)
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		ok = true
+	}
+	if l.tokens < 1 {
+		wait = time.Duration((1 - l.tokens) / perSec * float64(time.Second))
+	}
+	return ok, int(l.tokens), wait
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_RejectMode(t *testing.T) {
+	l := NewLimiter(2, false)
+	ctx := context.Background()
+	assert.True(t, l.Acquire(ctx))
+	assert.True(t, l.Acquire(ctx))
+	assert.False(t, l.Acquire(ctx))
+}
+
+func TestLimiter_QueueModeRespectsDeadline(t *testing.T) {
+	l := NewLimiter(1, true)
+	assert.True(t, l.Acquire(context.Background()))
+
+	// The next token is ~60s away; a short deadline must be rejected without waiting.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	assert.False(t, l.Acquire(ctx))
+	assert.Less(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestLimiter_QueueModeWaits(t *testing.T) {
+	l := NewLimiter(600, true) // one token every 100ms
+	ctx := context.Background()
+	for i := 0; i < 600; i++ {
+		assert.True(t, l.Acquire(ctx))
+	}
+	start := time.Now()
+	assert.True(t, l.Acquire(ctx))
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	l := NewLimiter(0, false)
+	for i := 0; i < 1000; i++ {
+		assert.True(t, l.Acquire(context.Background()))
+	}
+}
+
+func TestLimiter_TryAcquire(t *testing.T) {
+	l := NewLimiter(2, true)
+	ok, remaining, _ := l.TryAcquire()
+	assert.True(t, ok)
+	assert.Equal(t, 1, remaining)
+	ok, remaining, wait := l.TryAcquire()
+	assert.True(t, ok)
+	assert.Equal(t, 0, remaining)
+	assert.InDelta(t, 30*time.Second, wait, float64(time.Second))
+	ok, _, _ = l.TryAcquire()
+	assert.False(t, ok)
+}
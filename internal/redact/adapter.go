@@ -0,0 +1,51 @@
+package redact
+
+import (
+	"context"
+
+	"hermes/internal/providerapi"
+)
+
+// Adapter wraps a providerapi.Adapter so its errors and error messages never carry credentials,
+// whichever way the adapter built its request URL.
+type Adapter struct {
+	providerapi.Adapter
+}
+
+// Wrap returns a with redacted errors.
+func Wrap(a providerapi.Adapter) *Adapter {
+	return &Adapter{Adapter: a}
+}
+
+// Lookup implements providerapi.Adapter.
+func (a *Adapter) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	res, err := a.Adapter.Lookup(ctx, indicatorType, value)
+	return result(res), Error(err)
+}
+
+// Submit implements providerapi.AsyncAdapter for adapters underneath that do.
+func (a *Adapter) Submit(ctx context.Context, indicatorType string, value string) (*providerapi.Job, providerapi.Result, error) {
+	job, res, err := providerapi.Submit(ctx, a.Adapter, indicatorType, value)
+	return job, result(res), Error(err)
+}
+
+// Poll implements providerapi.AsyncAdapter.
+func (a *Adapter) Poll(ctx context.Context, job providerapi.Job) (providerapi.JobStatus, error) {
+	st, err := providerapi.Poll(ctx, a.Adapter, job)
+	st.Failed = Credentials(st.Failed)
+	return st, Error(err)
+}
+
+// Collect implements providerapi.AsyncAdapter.
+func (a *Adapter) Collect(ctx context.Context, job providerapi.Job) (providerapi.Result, error) {
+	res, err := providerapi.Collect(ctx, a.Adapter, job)
+	return result(res), Error(err)
+}
+
+// Unwrap returns the wrapped adapter.
+func (a *Adapter) Unwrap() providerapi.Adapter { return a.Adapter }
+
+func result(res providerapi.Result) providerapi.Result {
+	res.Error = Credentials(res.Error)
+	return res
+}
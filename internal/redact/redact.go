@@ -0,0 +1,72 @@
+// Package redact keeps indicators and credentials out of logs and API error messages: Value
+// shortens sensitive values, Credentials strips secrets from URLs and error text, and Wrap
+// applies Credentials to everything a provider adapter reports.
+package redact
+
+import (
+	"regexp"
+)
+
+// SensitiveKeys are JSON and log attribute keys whose values are shortened with Value.
+var SensitiveKeys = map[string]bool{
+	"indicator_value": true,
+	"indicators":      true,
+	"value":           true,
+	"url":             true,
+	"email":           true,
+	"ip":              true,
+	"ipAddress":       true,
+}
+
+// Value redacts sensitive parts of a value (e.g. indicator_value): a short prefix + "***" if
+// longer than 8 bytes, else "***".
+func Value(s string) string {
+	if s == "" {
+		return ""
+	}
+	if len(s) <= 8 {
+		return "***"
+	}
+	return s[:4] + "***"
+}
+
+// Placeholder replaces stripped credentials.
+const Placeholder = "REDACTED"
+
+var (
+	// credentialParam matches query parameters carrying secrets, e.g. Malshare's ?api_key=.
+	credentialParam = regexp.MustCompile(`(?i)([?&](?:api[_-]?key|apikey|key|app[_-]?key|token|access[_-]?token|auth|secret|client[_-]?secret|password|passwd|pwd)=)[^&\s"'#]*`)
+	// userInfo matches user:password@ in URLs.
+	userInfo = regexp.MustCompile(`(?i)\b([a-z][a-z0-9+.-]*://)[^/\s:@]+:[^/\s@]*@`)
+	// bearer matches bearer tokens quoted in error text.
+	bearer = regexp.MustCompile(`(?i)\b(bearer\s+)[a-z0-9._~+/-]+=*`)
+)
+
+// Credentials strips API keys, tokens and passwords from URLs and other text, e.g. the request
+// URL quoted by a transport error.
+func Credentials(s string) string {
+	s = credentialParam.ReplaceAllString(s, "${1}"+Placeholder)
+	s = userInfo.ReplaceAllString(s, "${1}"+Placeholder+"@")
+	return bearer.ReplaceAllString(s, "${1}"+Placeholder)
+}
+
+// Error returns err with Credentials applied to its message; errors.Is and errors.As still see
+// the original.
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := Credentials(err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	return &redactedError{err: err, msg: msg}
+}
+
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string { return e.msg }
+func (e *redactedError) Unwrap() error { return e.err }
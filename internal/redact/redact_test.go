@@ -0,0 +1,37 @@
+package redact
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCredentials(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{`Get "https://malshare.com/api.php?api_key=abc123&action=details&hash=x": EOF`,
+			`Get "https://malshare.com/api.php?api_key=REDACTED&action=details&hash=x": EOF`},
+		{"https://example.com/v1?q=1&token=s3cr3t", "https://example.com/v1?q=1&token=REDACTED"},
+		{"postgres://hermes:changeme@db:5432/hermes", "postgres://REDACTED@db:5432/hermes"},
+		{"Authorization: Bearer eyJhbGciOi.x-y", "Authorization: Bearer REDACTED"},
+		{"HTTP 404", "HTTP 404"},
+		{"https://example.com/?monkey=1", "https://example.com/?monkey=1"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Credentials(tt.in))
+	}
+}
+
+func TestError_KeepsChain(t *testing.T) {
+	err := Error(fmt.Errorf("Get \"https://x.test/?apikey=k\": %w", context.DeadlineExceeded))
+	assert.Equal(t, `Get "https://x.test/?apikey=REDACTED": context deadline exceeded`, err.Error())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	plain := errors.New("HTTP 500")
+	assert.Same(t, plain, Error(plain))
+	assert.Nil(t, Error(nil))
+}
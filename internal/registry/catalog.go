@@ -0,0 +1,34 @@
+package registry
+
+// providerInfo is the seed row for the providers table.
+type providerInfo struct {
+	Name            string
+	RateLimitPerMin int
+}
+
+// catalog describes every registered provider code. Rate limits follow the vendors' free/public
+// tiers where they are documented; operators tune them in the providers table afterwards.
+var catalog = map[string]providerInfo{
+	"abuseipdb":          {Name: "AbuseIPDB", RateLimitPerMin: 60},
+	"virustotal":         {Name: "VirusTotal", RateLimitPerMin: 4},
+	"phishtank":          {Name: "PhishTank", RateLimitPerMin: 60},
+	"googlesafebrowsing": {Name: "Google Safe Browsing", RateLimitPerMin: 60},
+	"urlscan":            {Name: "urlscan.io", RateLimitPerMin: 60},
+	"hibp":               {Name: "Have I Been Pwned", RateLimitPerMin: 10},
+	"nvd":                {Name: "National Vulnerability Database", RateLimitPerMin: 10},
+	"circl_cve":          {Name: "CIRCL CVE Search", RateLimitPerMin: 60},
+	"binaryedge":         {Name: "BinaryEdge", RateLimitPerMin: 60},
+	"criminalip":         {Name: "Criminal IP", RateLimitPerMin: 60},
+	"pulsedive":          {Name: "Pulsedive", RateLimitPerMin: 30},
+	"threatminer":        {Name: "ThreatMiner", RateLimitPerMin: 10},
+	"emailrep":           {Name: "EmailRep", RateLimitPerMin: 60},
+	"vulners":            {Name: "Vulners", RateLimitPerMin: 60},
+	"ipasnhistory":       {Name: "IP ASN History (CIRCL D4)", RateLimitPerMin: 60},
+	"hybridanalysis":     {Name: "Hybrid Analysis", RateLimitPerMin: 60},
+	"malshare":           {Name: "Malshare", RateLimitPerMin: 60},
+	"malwarebazaar":      {Name: "MalwareBazaar", RateLimitPerMin: 60},
+	"urlhaus":            {Name: "URLhaus", RateLimitPerMin: 60},
+	"threatfox":          {Name: "ThreatFox", RateLimitPerMin: 60},
+	"feodotracker":       {Name: "Feodo Tracker", RateLimitPerMin: 60},
+	"ssllabs":            {Name: "SSL Labs", RateLimitPerMin: 60},
+}
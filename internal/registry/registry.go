@@ -1,13 +1,20 @@
 package registry
 
 import (
+	"sync"
+	"time"
+
 	"hermes/internal/config"
-	"hermes/internal/providerapi"
+	"hermes/internal/logging"
+	"hermes/internal/metrics"
+	"hermes/internal/model"
 	"hermes/internal/provider/abuseipdb"
 	"hermes/internal/provider/binaryedge"
 	"hermes/internal/provider/circl"
 	"hermes/internal/provider/criminalip"
 	"hermes/internal/provider/emailrep"
+	"hermes/internal/provider/feodotracker"
+	"hermes/internal/provider/googlesafebrowsing"
 	"hermes/internal/provider/hibp"
 	"hermes/internal/provider/hybridanalysis"
 	"hermes/internal/provider/ipasnhistory"
@@ -17,26 +24,50 @@ import (
 	"hermes/internal/provider/phishtank"
 	"hermes/internal/provider/pulsedive"
 	"hermes/internal/provider/ssllabs"
+	"hermes/internal/provider/threatfox"
 	"hermes/internal/provider/threatminer"
+	"hermes/internal/provider/urlhaus"
 	"hermes/internal/provider/urlscan"
 	"hermes/internal/provider/virustotal"
 	"hermes/internal/provider/vulners"
+	"hermes/internal/providerapi"
+	"hermes/internal/ratelimit"
+	"hermes/internal/redact"
+	"hermes/internal/repository"
+	"hermes/internal/tracing"
+
+	"gorm.io/gorm"
 )
 
+var logger = logging.For("registry")
+
 // Registry holds all provider adapters and selects by indicator type.
+// Each adapter is guarded by a token-bucket limiter, instrumented for /metrics and tracing, and
+// has credentials stripped from its errors; enabled flags and rate limits come from the
+// providers table (when attached) and are re-read every refresh interval.
 type Registry struct {
 	adapters []providerapi.Adapter
 	byCode   map[string]providerapi.Adapter
+	limiters map[string]*ratelimit.Limiter
+
+	mu           sync.Mutex
+	providers    *repository.ProviderRepository
+	refreshEvery time.Duration
+	refreshedAt  time.Time
+	queue        bool
+	disabled     map[string]bool
+	names        map[string]string
 }
 
-// NewRegistry builds a registry from config.
-func NewRegistry(cfg *config.Config) *Registry {
-	byCode := make(map[string]providerapi.Adapter)
+// NewRegistry builds a registry from config. Each adapter runs under its configured timeout/retry policy.
+// When db is non-nil the providers table is seeded with every registered code and drives enabled/rate limits.
+func NewRegistry(cfg *config.Config, db *gorm.DB) *Registry {
 	adapters := []providerapi.Adapter{
 		abuseipdb.NewClient(cfg.AbuseIPDBAPIKey),
 		virustotal.NewClient(cfg.VirusTotalAPIKey),
 		phishtank.NewClient(cfg.PhishTankAppKey),
-		urlscan.NewClient(cfg.URLScanAPIKey),
+		googlesafebrowsing.NewClient(cfg.GoogleSafeBrowsingAPIKey),
+		urlscan.NewClient(cfg.URLScanAPIKey, time.Duration(cfg.URLScanWaitSeconds)*time.Second),
 		hibp.NewClient(cfg.HIBPAPIKey),
 		nvd.NewClient(cfg.NVDAPIKey),
 		circl.NewClient(),
@@ -50,18 +81,148 @@ func NewRegistry(cfg *config.Config) *Registry {
 		hybridanalysis.NewClient(cfg.HybridAnalysisAPIKey),
 		malshare.NewClient(cfg.MalshareAPIKey),
 		malwarebazaar.NewClient(cfg.MalwareBazaarAPIKey),
-		ssllabs.NewClient(),
+		urlhaus.NewClient(cfg.URLhausAPIKey),
+		threatfox.NewClient(cfg.ThreatFoxAPIKey),
+		feodotracker.NewClient(cfg.FeodoTrackerAPIKey),
+		ssllabs.NewClient(cfg.SSLLabsMaxAgeHours),
+	}
+	for i, a := range adapters {
+		p := cfg.PolicyFor(a.Code())
+		adapters[i] = providerapi.WithPolicy(a, providerapi.Policy{
+			Timeout:    p.Timeout,
+			MaxRetries: p.MaxRetries,
+			Backoff:    p.Backoff,
+		})
+	}
+	r := New(adapters...)
+	if db != nil {
+		refresh := time.Duration(cfg.ProviderRefreshSeconds) * time.Second
+		if err := r.UseProviderTable(repository.NewProviderRepository(db), refresh, cfg.ProviderRateLimitMode != "reject"); err != nil {
+			logger.Warn("providers table unavailable, running without rate limits", "error", err)
+		}
+	}
+	return r
+}
+
+// New builds a registry from the given adapters (used by NewRegistry and tests). Adapters are
+// unlimited and enabled until UseProviderTable is called.
+func New(adapters ...providerapi.Adapter) *Registry {
+	r := &Registry{
+		byCode:   make(map[string]providerapi.Adapter),
+		limiters: make(map[string]*ratelimit.Limiter),
+		disabled: make(map[string]bool),
 	}
 	for _, a := range adapters {
-		byCode[a.Code()] = a
+		l := ratelimit.NewLimiter(0, false)
+		wrapped := tracing.Wrap(metrics.Wrap(ratelimit.Wrap(redact.Wrap(a), l)))
+		r.adapters = append(r.adapters, wrapped)
+		r.byCode[a.Code()] = wrapped
+		r.limiters[a.Code()] = l
+	}
+	return r
+}
+
+// UseProviderTable seeds missing providers rows for all registered codes and applies their
+// enabled/rate_limit_per_min settings, re-reading them at most every refreshEvery.
+// queue selects whether over-budget calls wait for a token or are rejected immediately.
+func (r *Registry) UseProviderTable(repo *repository.ProviderRepository, refreshEvery time.Duration, queue bool) error {
+	seed := make([]model.Provider, 0, len(r.adapters))
+	for _, a := range r.adapters {
+		info, ok := catalog[a.Code()]
+		if !ok {
+			info = providerInfo{Name: a.Code(), RateLimitPerMin: 60}
+		}
+		seed = append(seed, model.Provider{Code: a.Code(), Name: info.Name, Enabled: true, RateLimitPerMin: info.RateLimitPerMin})
+	}
+	if err := repo.SeedMissing(seed); err != nil {
+		return err
 	}
-	return &Registry{adapters: adapters, byCode: byCode}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.providers = repo
+	r.refreshEvery = refreshEvery
+	r.queue = queue
+	return r.refreshLocked()
 }
 
-// AdaptersForType returns adapters that support the given indicator type (e.g. ip, domain, url).
+// refresh re-reads the providers table when the refresh interval has elapsed.
+func (r *Registry) refresh() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.providers == nil || time.Since(r.refreshedAt) < r.refreshEvery {
+		return
+	}
+	if err := r.refreshLocked(); err != nil {
+		logger.Error("refresh providers", "error", err)
+	}
+}
+
+func (r *Registry) refreshLocked() error {
+	rows, err := r.providers.List()
+	if err != nil {
+		return err
+	}
+	r.refreshedAt = time.Now()
+	disabled := make(map[string]bool)
+	names := make(map[string]string)
+	for _, p := range rows {
+		if !p.Enabled {
+			disabled[p.Code] = true
+		}
+		names[p.Code] = p.Name
+		if l, ok := r.limiters[p.Code]; ok {
+			l.Configure(p.RateLimitPerMin, r.queue)
+		}
+	}
+	r.disabled = disabled
+	r.names = names
+	return nil
+}
+
+// Enabled reports whether the provider is enabled in the providers table.
+func (r *Registry) Enabled(code string) bool {
+	r.refresh()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !r.disabled[code]
+}
+
+// Name returns the provider's display name from the providers table, or the built-in catalog
+// when the table is not attached.
+func (r *Registry) Name(code string) string {
+	r.refresh()
+	r.mu.Lock()
+	name := r.names[code]
+	r.mu.Unlock()
+	if name != "" {
+		return name
+	}
+	if info, ok := catalog[code]; ok {
+		return info.Name
+	}
+	return code
+}
+
+// RateLimit returns the provider's calls per minute (0 = unlimited).
+func (r *Registry) RateLimit(code string) int {
+	r.refresh()
+	if l, ok := r.limiters[code]; ok {
+		return l.Rate()
+	}
+	return 0
+}
+
+// AdaptersForType returns enabled adapters that support the given indicator type (e.g. ip, domain, url).
 func (r *Registry) AdaptersForType(t string) []providerapi.Adapter {
+	r.refresh()
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	var out []providerapi.Adapter
 	for _, a := range r.adapters {
+		if r.disabled[a.Code()] {
+			continue
+		}
 		for _, st := range a.SupportedTypes() {
 			if st == t {
 				out = append(out, a)
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"hermes/internal/model"
+	"hermes/internal/providerapi"
+	"hermes/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func mockAdapter(code string) *providerapi.MockAdapter {
+	return &providerapi.MockAdapter{
+		CodeFunc:           func() string { return code },
+		SupportedTypesFunc: func() []string { return []string{"ip"} },
+	}
+}
+
+func TestRegistry_ProviderTable(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&model.Provider{}))
+
+	r := New(mockAdapter("abuseipdb"), mockAdapter("virustotal"))
+	repo := repository.NewProviderRepository(db)
+	assert.NoError(t, r.UseProviderTable(repo, 0, false))
+
+	rows, err := repo.List()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Len(t, r.AdaptersForType("ip"), 2)
+
+	// Disabling takes effect on the next refresh without rebuilding the registry.
+	assert.NoError(t, db.Model(&model.Provider{}).Where("code = ?", "virustotal").Update("enabled", false).Error)
+	adapters := r.AdaptersForType("ip")
+	assert.Len(t, adapters, 1)
+	assert.Equal(t, "abuseipdb", adapters[0].Code())
+	assert.False(t, r.Enabled("virustotal"))
+
+	// Seeding again keeps operator changes.
+	assert.NoError(t, r.UseProviderTable(repo, 0, false))
+	assert.False(t, r.Enabled("virustotal"))
+
+	// virustotal is seeded with 4 calls/min; the fifth is refused locally.
+	assert.NoError(t, db.Model(&model.Provider{}).Where("code = ?", "virustotal").Update("enabled", true).Error)
+	vt := r.AdapterByCode("virustotal")
+	for i := 0; i < 4; i++ {
+		_, err := vt.Lookup(context.Background(), "ip", "8.8.8.8")
+		assert.NoError(t, err)
+	}
+	res, err := vt.Lookup(context.Background(), "ip", "8.8.8.8")
+	assert.True(t, errors.Is(err, providerapi.ErrRateLimited))
+	assert.False(t, res.Success)
+}
@@ -0,0 +1,40 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+)
+
+// csvSheets writes the lookup sheet as CSV; errors are only summarized in its last column.
+type csvSheets struct {
+	w *csv.Writer
+}
+
+func newCSV(w io.Writer) *csvSheets {
+	return &csvSheets{w: csv.NewWriter(w)}
+}
+
+func (s *csvSheets) lookup(cells []interface{}) error {
+	record := make([]string, len(cells))
+	for i, c := range cells {
+		record[i] = escapeFormula(text(c))
+	}
+	return s.w.Write(record)
+}
+
+func (s *csvSheets) error([]interface{}) error { return nil }
+
+func (s *csvSheets) close() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// escapeFormula keeps spreadsheets from evaluating provider-supplied text as a formula: cells
+// starting with a character that opens one (see the OWASP CSV injection guidance) are quoted.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
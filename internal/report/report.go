@@ -0,0 +1,198 @@
+// Package report writes lookups as spreadsheet rows for analyst reporting: one row per indicator
+// with the aggregate verdict and, per provider, its verdict, score and key response fields. CSV
+// reports list a row's provider errors in its last column; XLSX reports add a sheet of them.
+// Rows are written as they are added, so reports of any size stream.
+package report
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"hermes/internal/vo"
+)
+
+// Report formats.
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// ContentType returns the media type of a report format.
+func ContentType(format string) string {
+	if format == FormatXLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Fielder is implemented by adapters with response fields worth columns of their own, such as
+// AbuseIPDB's confidence score or VirusTotal's detection ratio.
+type Fielder interface {
+	// ReportFields names the fields, in column order.
+	ReportFields() []string
+	// ReportValues returns the fields of a response in ReportFields order, "" when absent.
+	ReportValues(data map[string]interface{}) []string
+}
+
+// Provider is a provider with columns in a report.
+type Provider struct {
+	Code string
+	// Fielder adds columns after the provider's verdict and score; nil for none.
+	Fielder Fielder
+}
+
+var (
+	lookupHeader = []string{"request_id", "indicator_type", "indicator_value", "created_at", "verdict", "score"}
+	errorHeader  = []string{"request_id", "indicator_type", "indicator_value", "provider", "status", "error"}
+)
+
+// sheets writes the rows of the lookup and error sheets.
+type sheets interface {
+	lookup(cells []interface{}) error
+	error(cells []interface{}) error
+	close() error
+}
+
+// Report writes lookups to a CSV or XLSX document.
+type Report struct {
+	w         sheets
+	providers []Provider
+}
+
+// New starts a report in format on w with columns for providers, in code order.
+func New(w io.Writer, format string, providers []Provider) (*Report, error) {
+	providers = append([]Provider(nil), providers...)
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Code < providers[j].Code })
+
+	header := append([]string(nil), lookupHeader...)
+	for _, p := range providers {
+		header = append(header, p.Code+"_verdict", p.Code+"_score")
+		if p.Fielder != nil {
+			for _, f := range p.Fielder.ReportFields() {
+				header = append(header, p.Code+"_"+f)
+			}
+		}
+	}
+	header = append(header, "errors")
+
+	var s sheets
+	var err error
+	switch format {
+	case FormatCSV:
+		s = newCSV(w)
+	case FormatXLSX:
+		s, err = newXLSX(w)
+	default:
+		return nil, fmt.Errorf("unknown report format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.lookup(cells(header)); err != nil {
+		return nil, err
+	}
+	if err := s.error(cells(errorHeader)); err != nil {
+		return nil, err
+	}
+	return &Report{w: s, providers: providers}, nil
+}
+
+// Add writes a lookup's row, and a row in the error sheet per provider that failed.
+func (r *Report) Add(l *vo.LookupResponseVO) error {
+	row := []interface{}{l.RequestID, l.IndicatorType, l.IndicatorValue, l.CreatedAt.UTC(), nil, nil}
+	if l.Verdict != nil {
+		row[4], row[5] = l.Verdict.Verdict, l.Verdict.Score
+	}
+	for _, p := range r.providers {
+		res, ok := l.Results[p.Code]
+		var verdict, score interface{}
+		switch {
+		case ok && res.Success && res.Assessment != nil:
+			verdict, score = res.Assessment.Verdict, res.Assessment.Score
+		case ok:
+			verdict = res.Status
+		}
+		row = append(row, verdict, score)
+		if p.Fielder == nil {
+			continue
+		}
+		fields := p.Fielder.ReportFields()
+		var values []string
+		if data, _ := res.Data.(map[string]interface{}); ok && res.Success && data != nil {
+			values = p.Fielder.ReportValues(data)
+		}
+		for i := range fields {
+			if i < len(values) && values[i] != "" {
+				row = append(row, values[i])
+			} else {
+				row = append(row, nil)
+			}
+		}
+	}
+
+	codes := make([]string, 0, len(l.Results))
+	for code, res := range l.Results {
+		if res.Error != "" {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	summary := make([]string, 0, len(codes))
+	for _, code := range codes {
+		res := l.Results[code]
+		summary = append(summary, code+": "+res.Error)
+		if err := r.w.error([]interface{}{l.RequestID, l.IndicatorType, l.IndicatorValue, code, res.Status, res.Error}); err != nil {
+			return err
+		}
+	}
+	return r.w.lookup(append(row, strings.Join(summary, "; ")))
+}
+
+// AddFailed writes the row of an indicator that has no lookup, such as an invalid bulk job item,
+// with its status as verdict and msg, if any, in the error sheet.
+func (r *Report) AddFailed(indicatorType, value, status, msg string) error {
+	row := make([]interface{}, len(lookupHeader), len(lookupHeader)+2*len(r.providers)+1)
+	row[1], row[2], row[4] = indicatorType, value, status
+	for _, p := range r.providers {
+		row = append(row, nil, nil)
+		if p.Fielder != nil {
+			row = append(row, make([]interface{}, len(p.Fielder.ReportFields()))...)
+		}
+	}
+	if msg != "" {
+		if err := r.w.error([]interface{}{nil, indicatorType, value, nil, status, msg}); err != nil {
+			return err
+		}
+	}
+	return r.w.lookup(append(row, msg))
+}
+
+// Close completes the document; nothing of an XLSX report is written before.
+func (r *Report) Close() error {
+	return r.w.close()
+}
+
+func cells(s []string) []interface{} {
+	out := make([]interface{}, len(s))
+	for i, v := range s {
+		out[i] = v
+	}
+	return out
+}
+
+// text renders a cell for CSV.
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	default:
+		return fmt.Sprint(v)
+	}
+}
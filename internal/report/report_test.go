@@ -0,0 +1,79 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"hermes/internal/vo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+type ratioFielder struct{}
+
+func (ratioFielder) ReportFields() []string { return []string{"detection_ratio"} }
+
+func (ratioFielder) ReportValues(data map[string]interface{}) []string {
+	s, _ := data["ratio"].(string)
+	return []string{s}
+}
+
+func providers() []Provider {
+	return []Provider{{Code: "virustotal", Fielder: ratioFielder{}}, {Code: "abuseipdb"}}
+}
+
+func lookup() *vo.LookupResponseVO {
+	return &vo.LookupResponseVO{
+		RequestID:      "550e8400-e29b-41d4-a716-446655440000",
+		IndicatorType:  "ip",
+		IndicatorValue: "192.0.2.1",
+		CreatedAt:      time.Date(2025, 2, 20, 9, 0, 0, 0, time.UTC),
+		Verdict:        &vo.AggregateVerdictVO{Verdict: "malicious", Score: 82},
+		Results: map[string]vo.ProviderResultVO{
+			"virustotal": {Success: true, Status: vo.StatusOK, Assessment: &vo.AssessmentVO{Verdict: "malicious", Score: 90}, Data: map[string]interface{}{"ratio": "12/94"}},
+			"abuseipdb":  {Status: vo.StatusError, Error: "HTTP 429"},
+		},
+	}
+}
+
+func TestReport_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := New(&buf, FormatCSV, providers())
+	assert.NoError(t, err)
+	assert.NoError(t, r.Add(lookup()))
+	assert.NoError(t, r.AddFailed("domain", "=cmd|' /C calc'!A0", "invalid", "not a domain"))
+	assert.NoError(t, r.AddFailed("domain", "-2+3+cmd|' /C calc'!A0", "invalid", "not a domain"))
+	assert.NoError(t, r.Close())
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{
+		{"request_id", "indicator_type", "indicator_value", "created_at", "verdict", "score", "abuseipdb_verdict", "abuseipdb_score", "virustotal_verdict", "virustotal_score", "virustotal_detection_ratio", "errors"},
+		{"550e8400-e29b-41d4-a716-446655440000", "ip", "192.0.2.1", "2025-02-20T09:00:00Z", "malicious", "82", "error", "", "malicious", "90", "12/94", "abuseipdb: HTTP 429"},
+		{"", "domain", "'=cmd|' /C calc'!A0", "", "invalid", "", "", "", "", "", "", "not a domain"},
+		{"", "domain", "'-2+3+cmd|' /C calc'!A0", "", "invalid", "", "", "", "", "", "", "not a domain"},
+	}, rows)
+}
+
+func TestReport_XLSX(t *testing.T) {
+	var buf bytes.Buffer
+	r, err := New(&buf, FormatXLSX, providers())
+	assert.NoError(t, err)
+	assert.NoError(t, r.Add(lookup()))
+	assert.NoError(t, r.Close())
+
+	f, err := excelize.OpenReader(&buf)
+	assert.NoError(t, err)
+	defer f.Close()
+	assert.Equal(t, []string{lookupSheet, errorSheet}, f.GetSheetList())
+	score, _ := f.GetCellValue(lookupSheet, "F2")
+	assert.Equal(t, "82", score)
+	ratio, _ := f.GetCellValue(lookupSheet, "K2")
+	assert.Equal(t, "12/94", ratio)
+	errs, err := f.GetRows(errorSheet)
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{errorHeader, {"550e8400-e29b-41d4-a716-446655440000", "ip", "192.0.2.1", "abuseipdb", "error", "HTTP 429"}}, errs)
+}
@@ -0,0 +1,83 @@
+package report
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	lookupSheet = "Lookups"
+	errorSheet  = "Errors"
+)
+
+// xlsxSheets streams both sheets into a workbook written out on close. The stream writers keep
+// rows in temporary files once they outgrow memory.
+type xlsxSheets struct {
+	out        io.Writer
+	f          *excelize.File
+	lookups    *excelize.StreamWriter
+	errors     *excelize.StreamWriter
+	lookupRows int
+	errorRows  int
+	bold       int
+}
+
+func newXLSX(w io.Writer) (*xlsxSheets, error) {
+	f := excelize.NewFile()
+	s := &xlsxSheets{out: w, f: f}
+	err := f.SetSheetName("Sheet1", lookupSheet)
+	if err == nil {
+		_, err = f.NewSheet(errorSheet)
+	}
+	if err == nil {
+		s.bold, err = f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	}
+	if err == nil {
+		s.lookups, err = f.NewStreamWriter(lookupSheet)
+	}
+	if err == nil {
+		s.errors, err = f.NewStreamWriter(errorSheet)
+	}
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *xlsxSheets) lookup(cells []interface{}) error {
+	s.lookupRows++
+	return setRow(s.lookups, s.lookupRows, cells, s.bold)
+}
+
+func (s *xlsxSheets) error(cells []interface{}) error {
+	s.errorRows++
+	return setRow(s.errors, s.errorRows, cells, s.bold)
+}
+
+// setRow writes row n; the first row is the header.
+func setRow(sw *excelize.StreamWriter, n int, cells []interface{}, bold int) error {
+	cell, err := excelize.CoordinatesToCellName(1, n)
+	if err != nil {
+		return err
+	}
+	if n == 1 {
+		if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return err
+		}
+		return sw.SetRow(cell, cells, excelize.RowOpts{StyleID: bold})
+	}
+	return sw.SetRow(cell, cells)
+}
+
+func (s *xlsxSheets) close() error {
+	defer s.f.Close()
+	if err := s.lookups.Flush(); err != nil {
+		return err
+	}
+	if err := s.errors.Flush(); err != nil {
+		return err
+	}
+	return s.f.Write(s.out)
+}
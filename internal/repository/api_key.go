@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"time"
+
+	"hermes/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository handles clients and api_keys.
+type APIKeyRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeyRepository creates a new repository.
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+// CreateClient creates a client. It returns gorm.ErrDuplicatedKey when the name is taken.
+func (r *APIKeyRepository) CreateClient(c *model.Client) error {
+	err := r.db.Create(c).Error
+	if t, ok := r.db.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		err = t.Translate(err)
+	}
+	return err
+}
+
+// GetClient loads a client by id.
+func (r *APIKeyRepository) GetClient(id int64) (*model.Client, error) {
+	var c model.Client
+	if err := r.db.First(&c, id).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetClientByName loads a client by name.
+func (r *APIKeyRepository) GetClientByName(name string) (*model.Client, error) {
+	var c model.Client
+	if err := r.db.Where("name = ?", name).First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// UpdateClientLimits replaces a client's limit overrides (nil = default).
+func (r *APIKeyRepository) UpdateClientLimits(c *model.Client) error {
+	return r.db.Model(c).Select("rate_limit_per_min", "daily_quota", "monthly_quota", "updated_at").Updates(c).Error
+}
+
+// ListClients returns all clients with their keys.
+func (r *APIKeyRepository) ListClients() ([]model.Client, error) {
+	var list []model.Client
+	err := r.db.Preload("APIKeys", func(db *gorm.DB) *gorm.DB { return db.Order("id") }).Order("name").Find(&list).Error
+	return list, err
+}
+
+// CreateKey stores a key.
+func (r *APIKeyRepository) CreateKey(k *model.APIKey) error {
+	return r.db.Create(k).Error
+}
+
+// GetByHash loads a key and its client by key hash.
+func (r *APIKeyRepository) GetByHash(hash string) (*model.APIKey, error) {
+	var k model.APIKey
+	if err := r.db.Preload("Client").Where("key_hash = ?", hash).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// GetByKeyID loads a key by its public key_id.
+func (r *APIKeyRepository) GetByKeyID(keyID string) (*model.APIKey, error) {
+	var k model.APIKey
+	if err := r.db.Where("key_id = ?", keyID).First(&k).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Rotate stores next and makes prev stop working at prevExpiresAt, in one transaction.
+func (r *APIKeyRepository) Rotate(prev *model.APIKey, next *model.APIKey, prevExpiresAt time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.APIKey{}).Where("id = ?", prev.ID).Update("expires_at", prevExpiresAt).Error
+	})
+}
+
+// Revoke marks a key revoked.
+func (r *APIKeyRepository) Revoke(id int64, at time.Time) error {
+	return r.db.Model(&model.APIKey{}).Where("id = ?", id).Update("revoked_at", at).Error
+}
+
+// TouchLastUsed records when a key was last used.
+func (r *APIKeyRepository) TouchLastUsed(id int64, at time.Time) error {
+	return r.db.Model(&model.APIKey{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+
 	"hermes/internal/model"
 
 	"gorm.io/gorm"
@@ -16,6 +18,11 @@ func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
 	return &AuditLogRepository{db: db}
 }
 
+// WithContext returns a copy whose queries run with ctx (and join its trace).
+func (r *AuditLogRepository) WithContext(ctx context.Context) *AuditLogRepository {
+	return &AuditLogRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create appends an audit log entry.
 func (r *AuditLogRepository) Create(log *model.AuditLog) error {
 	return r.db.Create(log).Error
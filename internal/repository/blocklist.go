@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"time"
+
+	"hermes/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// BlocklistRepository handles blocklists and allowlist_entries, and selects blocklist entries
+// from lookup_requests.
+type BlocklistRepository struct {
+	db *gorm.DB
+}
+
+// NewBlocklistRepository creates a new repository.
+func NewBlocklistRepository(db *gorm.DB) *BlocklistRepository {
+	return &BlocklistRepository{db: db}
+}
+
+// Create stores a blocklist.
+func (r *BlocklistRepository) Create(b *model.Blocklist) error {
+	return r.db.Create(b).Error
+}
+
+// Save updates a blocklist's definition.
+func (r *BlocklistRepository) Save(b *model.Blocklist) error {
+	return r.db.Save(b).Error
+}
+
+// List returns the blocklists by name.
+func (r *BlocklistRepository) List() ([]model.Blocklist, error) {
+	var list []model.Blocklist
+	err := r.db.Order("name").Find(&list).Error
+	return list, err
+}
+
+// GetByName loads a blocklist.
+func (r *BlocklistRepository) GetByName(name string) (*model.Blocklist, error) {
+	var b model.Blocklist
+	if err := r.db.Where("name = ?", name).First(&b).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Delete deletes a blocklist; it returns gorm.ErrRecordNotFound for unknown names.
+func (r *BlocklistRepository) Delete(name string) error {
+	res := r.db.Where("name = ?", name).Delete(&model.Blocklist{})
+	if res.Error == nil && res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return res.Error
+}
+
+// SetContent records that a blocklist's entries, hashed to hash, changed at modifiedAt. The
+// definition's updated_at is left alone.
+func (r *BlocklistRepository) SetContent(id int64, hash string, modifiedAt time.Time) error {
+	return r.db.Model(&model.Blocklist{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"content_hash": hash, "modified_at": modifiedAt}).Error
+}
+
+// BlocklistVote is a provider's latest result for an indicator.
+type BlocklistVote struct {
+	IndicatorHash  string
+	IndicatorValue string
+	ProviderCode   string
+	Verdict        string
+	Score          int
+}
+
+// Votes returns the latest stored result of each provider for each indicator of indicatorType,
+// ordered by indicator. since, when set, excludes results cached before it. Lookups that reached
+// only some providers, or none, leave the other providers' latest results in place.
+func (r *BlocklistRepository) Votes(indicatorType string, since *time.Time) ([]BlocklistVote, error) {
+	q := r.db.Table("lookup_results").
+		Joins("JOIN lookup_requests ON lookup_requests.id = lookup_results.lookup_request_id").
+		Where("lookup_requests.indicator_type = ?", indicatorType).
+		Where(`lookup_results.id = (SELECT latest.id FROM lookup_results latest
+			JOIN lookup_requests latest_req ON latest_req.id = latest.lookup_request_id
+			WHERE latest_req.indicator_type = lookup_requests.indicator_type AND latest_req.indicator_hash = lookup_requests.indicator_hash
+				AND latest.provider_code = lookup_results.provider_code
+			ORDER BY latest.cached_at DESC, latest.id DESC LIMIT 1)`)
+	if since != nil {
+		q = q.Where("lookup_results.cached_at >= ?", *since)
+	}
+	var list []BlocklistVote
+	err := q.Select("lookup_requests.indicator_hash, lookup_requests.indicator_value, lookup_results.provider_code, lookup_results.verdict, COALESCE(lookup_results.score, 0) AS score").
+		Order("lookup_requests.indicator_hash").Order("lookup_results.provider_code").
+		Scan(&list).Error
+	return list, err
+}
+
+// AddAllowlistEntry stores an allowlist entry.
+func (r *BlocklistRepository) AddAllowlistEntry(e *model.AllowlistEntry) error {
+	return r.db.Create(e).Error
+}
+
+// AllowlistEntries returns the allowlist entries, optionally of one indicator type, oldest first.
+func (r *BlocklistRepository) AllowlistEntries(indicatorType string) ([]model.AllowlistEntry, error) {
+	q := r.db.Order("id")
+	if indicatorType != "" {
+		q = q.Where("indicator_type = ?", indicatorType)
+	}
+	var list []model.AllowlistEntry
+	err := q.Find(&list).Error
+	return list, err
+}
+
+// DeleteAllowlistEntry deletes an allowlist entry; it returns gorm.ErrRecordNotFound for unknown
+// IDs.
+func (r *BlocklistRepository) DeleteAllowlistEntry(id int64) error {
+	res := r.db.Delete(&model.AllowlistEntry{}, id)
+	if res.Error == nil && res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return res.Error
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"time"
+
+	"hermes/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BulkJobRepository handles bulk_jobs and bulk_job_items.
+type BulkJobRepository struct {
+	db *gorm.DB
+}
+
+// NewBulkJobRepository creates a new repository.
+func NewBulkJobRepository(db *gorm.DB) *BulkJobRepository {
+	return &BulkJobRepository{db: db}
+}
+
+// Create stores a job and its items in one transaction.
+func (r *BulkJobRepository) Create(job *model.BulkJob, items []model.BulkJobItem) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].BulkJobID = job.ID
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&items, 200).Error
+	})
+}
+
+// GetByJobID loads a job by job_id.
+func (r *BulkJobRepository) GetByJobID(jobID uuid.UUID) (*model.BulkJob, error) {
+	var m model.BulkJob
+	if err := r.db.Where("job_id = ?", jobID).First(&m).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListItems returns a page of a job's items in input order.
+func (r *BulkJobRepository) ListItems(bulkJobID int64, offset, limit int) ([]model.BulkJobItem, error) {
+	var list []model.BulkJobItem
+	err := r.db.Where("bulk_job_id = ?", bulkJobID).Order("position").Offset(offset).Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// PendingItems returns the items of a job that have not been looked up yet.
+func (r *BulkJobRepository) PendingItems(bulkJobID int64) ([]model.BulkJobItem, error) {
+	var list []model.BulkJobItem
+	err := r.db.Where("bulk_job_id = ? AND status = ?", bulkJobID, model.BulkItemPending).Order("position").Find(&list).Error
+	return list, err
+}
+
+// Unfinished returns jobs that are queued or were running when the process stopped.
+func (r *BulkJobRepository) Unfinished() ([]model.BulkJob, error) {
+	var list []model.BulkJob
+	err := r.db.Where("status IN ?", []string{model.BulkJobQueued, model.BulkJobRunning}).Order("id").Find(&list).Error
+	return list, err
+}
+
+// FinishItem stores an item's outcome and bumps the job's completed or failed counter.
+func (r *BulkJobRepository) FinishItem(item *model.BulkJobItem) error {
+	counter := "completed"
+	if item.Status != model.BulkItemDone {
+		counter = "failed"
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.BulkJobItem{}).Where("id = ?", item.ID).Updates(map[string]interface{}{
+			"status":            item.Status,
+			"lookup_request_id": item.LookupRequestID,
+			"verdict":           item.Verdict,
+			"score":             item.Score,
+			"error":             item.Error,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&model.BulkJob{}).Where("id = ?", item.BulkJobID).
+			Update(counter, gorm.Expr(counter+" + 1")).Error
+	})
+}
+
+// SetStatus updates a job's status; finishing statuses also set finished_at.
+func (r *BulkJobRepository) SetStatus(id int64, status string) error {
+	updates := map[string]interface{}{"status": status}
+	if status == model.BulkJobCompleted {
+		updates["finished_at"] = time.Now()
+	}
+	return r.db.Model(&model.BulkJob{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// Fail marks a job failed with msg and sets finished_at.
+func (r *BulkJobRepository) Fail(id int64, msg string) error {
+	return r.db.Model(&model.BulkJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      model.BulkJobFailed,
+		"error":       msg,
+		"finished_at": time.Now(),
+	}).Error
+}
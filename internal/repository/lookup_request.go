@@ -1,13 +1,17 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"hermes/internal/model"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-// LookupRequestRepository handles lookup_requests and lookup_results.
+// LookupRequestRepository handles lookup_requests, lookup_results and lookup_objects.
 type LookupRequestRepository struct {
 	db *gorm.DB
 }
@@ -17,6 +21,11 @@ func NewLookupRequestRepository(db *gorm.DB) *LookupRequestRepository {
 	return &LookupRequestRepository{db: db}
 }
 
+// WithContext returns a copy whose queries run with ctx (and join its trace).
+func (r *LookupRequestRepository) WithContext(ctx context.Context) *LookupRequestRepository {
+	return &LookupRequestRepository{db: r.db.WithContext(ctx)}
+}
+
 // Create creates a lookup request and returns the request_id.
 func (r *LookupRequestRepository) Create(req *model.LookupRequest) error {
 	if req.RequestID == uuid.Nil {
@@ -40,9 +49,170 @@ func (r *LookupRequestRepository) CreateResult(res *model.LookupResult) error {
 	return r.db.Create(res).Error
 }
 
+// GetByRequestIDs loads the lookup requests with the given request_ids, in no particular order;
+// unknown IDs are skipped.
+func (r *LookupRequestRepository) GetByRequestIDs(requestIDs []uuid.UUID) ([]model.LookupRequest, error) {
+	var list []model.LookupRequest
+	if len(requestIDs) == 0 {
+		return list, nil
+	}
+	err := r.db.Where("request_id IN ?", requestIDs).Find(&list).Error
+	return list, err
+}
+
+// GetResultsByRequestIDs returns the results of the given lookup requests, by lookup request id.
+func (r *LookupRequestRepository) GetResultsByRequestIDs(lookupRequestIDs []int64) (map[int64][]model.LookupResult, error) {
+	out := make(map[int64][]model.LookupResult, len(lookupRequestIDs))
+	if len(lookupRequestIDs) == 0 {
+		return out, nil
+	}
+	var list []model.LookupResult
+	if err := r.db.Where("lookup_request_id IN ?", lookupRequestIDs).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range list {
+		out[row.LookupRequestID] = append(out[row.LookupRequestID], row)
+	}
+	return out, nil
+}
+
 // GetResultsByRequestID returns all results for a lookup request.
 func (r *LookupRequestRepository) GetResultsByRequestID(lookupRequestID int64) ([]model.LookupResult, error) {
 	var list []model.LookupResult
 	err := r.db.Where("lookup_request_id = ?", lookupRequestID).Find(&list).Error
 	return list, err
 }
+
+// FindLatestResult returns the most recently cached result for (indicatorType, indicatorHash, providerCode),
+// or nil if the provider has never answered for that indicator. Expiry is left to the caller.
+func (r *LookupRequestRepository) FindLatestResult(indicatorType, indicatorHash, providerCode string) (*model.LookupResult, error) {
+	var list []model.LookupResult
+	err := r.db.
+		Joins("JOIN lookup_requests ON lookup_requests.id = lookup_results.lookup_request_id").
+		Where("lookup_requests.indicator_type = ? AND lookup_requests.indicator_hash = ? AND lookup_results.provider_code = ?",
+			indicatorType, indicatorHash, providerCode).
+		Order("lookup_results.cached_at DESC").
+		Limit(1).
+		Find(&list).Error
+	if err != nil || len(list) == 0 {
+		return nil, err
+	}
+	return &list[0], nil
+}
+
+// UpdateVerdict stores the aggregated verdict of a lookup request and bumps its updated_at.
+func (r *LookupRequestRepository) UpdateVerdict(id int64, verdict string, score int) error {
+	return r.db.Model(&model.LookupRequest{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"verdict": verdict, "score": score, "updated_at": time.Now()}).Error
+}
+
+// AddObjects records the STIX object IDs a lookup request exports; recorded IDs are kept.
+func (r *LookupRequestRepository) AddObjects(lookupRequestID int64, objectIDs []string) error {
+	if len(objectIDs) == 0 {
+		return nil
+	}
+	rows := make([]model.LookupObject, 0, len(objectIDs))
+	for _, id := range objectIDs {
+		rows = append(rows, model.LookupObject{ObjectID: id, LookupRequestID: lookupRequestID})
+	}
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+// ListUnindexed returns up to limit lookup requests after afterID, in id order, with no STIX
+// object IDs recorded.
+func (r *LookupRequestRepository) ListUnindexed(afterID int64, limit int) ([]model.LookupRequest, error) {
+	var list []model.LookupRequest
+	err := r.db.Where("id > ?", afterID).
+		Where("NOT EXISTS (SELECT 1 FROM lookup_objects WHERE lookup_objects.lookup_request_id = lookup_requests.id)").
+		Order("id").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// LookupFilter selects lookup requests for history listings. Empty fields do not filter.
+type LookupFilter struct {
+	// UserID restricts the listing to one client's lookups; nil lists all.
+	UserID        *string
+	IndicatorType string
+	IndicatorHash string
+	// ProviderCode keeps requests with a stored result from that provider.
+	ProviderCode string
+	Verdict      string
+	Since        *time.Time
+	Until        *time.Time
+	// SortByScore orders by aggregate score instead of created_at; ties break on id.
+	SortByScore bool
+	Desc        bool
+	// After continues a listing after the given row (keyset pagination).
+	After *LookupCursor
+	Limit int
+}
+
+// LookupCursor is the position of the last row of a history page.
+type LookupCursor struct {
+	CreatedAt time.Time
+	Score     int
+	ID        int64
+}
+
+// List returns lookup requests matching f in the requested order.
+func (r *LookupRequestRepository) List(f LookupFilter) ([]model.LookupRequest, error) {
+	q := r.db.Model(&model.LookupRequest{})
+	if f.UserID != nil {
+		q = q.Where("user_id = ?", *f.UserID)
+	}
+	if f.IndicatorType != "" {
+		q = q.Where("indicator_type = ?", f.IndicatorType)
+	}
+	if f.IndicatorHash != "" {
+		q = q.Where("indicator_hash = ?", f.IndicatorHash)
+	}
+	if f.ProviderCode != "" {
+		q = q.Where("EXISTS (SELECT 1 FROM lookup_results WHERE lookup_results.lookup_request_id = lookup_requests.id AND lookup_results.provider_code = ?)", f.ProviderCode)
+	}
+	if f.Verdict != "" {
+		q = q.Where("verdict = ?", f.Verdict)
+	}
+	if f.Since != nil {
+		q = q.Where("created_at >= ?", *f.Since)
+	}
+	if f.Until != nil {
+		q = q.Where("created_at < ?", *f.Until)
+	}
+
+	col, cmp, dir := "created_at", ">", "ASC"
+	if f.SortByScore {
+		col = "COALESCE(score, 0)"
+	}
+	if f.Desc {
+		cmp, dir = "<", "DESC"
+	}
+	if f.After != nil {
+		var v interface{} = f.After.CreatedAt
+		if f.SortByScore {
+			v = f.After.Score
+		}
+		q = q.Where("("+col+" "+cmp+" ?) OR ("+col+" = ? AND id "+cmp+" ?)", v, v, f.After.ID)
+	}
+
+	var list []model.LookupRequest
+	err := q.Order(col + " " + dir).Order("id " + dir).Limit(f.Limit).Find(&list).Error
+	return list, err
+}
+
+// ProviderCodes returns the provider codes with stored results for each of the given requests.
+func (r *LookupRequestRepository) ProviderCodes(lookupRequestIDs []int64) (map[int64][]string, error) {
+	out := make(map[int64][]string, len(lookupRequestIDs))
+	if len(lookupRequestIDs) == 0 {
+		return out, nil
+	}
+	var rows []struct {
+		LookupRequestID int64
+		ProviderCode    string
+	}
+	err := r.db.Model(&model.LookupResult{}).Select("lookup_request_id, provider_code").
+		Where("lookup_request_id IN ?", lookupRequestIDs).Order("provider_code").Scan(&rows).Error
+	for _, row := range rows {
+		out[row.LookupRequestID] = append(out[row.LookupRequestID], row.ProviderCode)
+	}
+	return out, err
+}
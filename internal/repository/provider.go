@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"hermes/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ProviderRepository handles the providers table.
+type ProviderRepository struct {
+	db *gorm.DB
+}
+
+// NewProviderRepository creates a new repository.
+func NewProviderRepository(db *gorm.DB) *ProviderRepository {
+	return &ProviderRepository{db: db}
+}
+
+// List returns all providers.
+func (r *ProviderRepository) List() ([]model.Provider, error) {
+	var list []model.Provider
+	err := r.db.Order("code").Find(&list).Error
+	return list, err
+}
+
+// SeedMissing inserts providers whose code is not in the table yet; existing rows are left untouched
+// so operators' changes to enabled/rate_limit_per_min survive restarts.
+func (r *ProviderRepository) SeedMissing(providers []model.Provider) error {
+	if len(providers) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).Create(&providers).Error
+}
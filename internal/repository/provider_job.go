@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"time"
+
+	"hermes/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// ProviderJobRepository handles provider_jobs.
+type ProviderJobRepository struct {
+	db *gorm.DB
+}
+
+// NewProviderJobRepository creates a new repository.
+func NewProviderJobRepository(db *gorm.DB) *ProviderJobRepository {
+	return &ProviderJobRepository{db: db}
+}
+
+// Create stores a new job.
+func (r *ProviderJobRepository) Create(job *model.ProviderJob) error {
+	return r.db.Create(job).Error
+}
+
+// Due returns up to limit pending jobs whose next poll is at or before now, oldest first.
+func (r *ProviderJobRepository) Due(now time.Time, limit int) ([]model.ProviderJob, error) {
+	var list []model.ProviderJob
+	err := r.db.Where("status = ? AND next_poll_at <= ?", model.ProviderJobPending, now).
+		Order("next_poll_at").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// Claim leases a due job until until by moving its next poll; it reports false when another
+// worker claimed or finished the job first.
+func (r *ProviderJobRepository) Claim(job *model.ProviderJob, until time.Time) (bool, error) {
+	res := r.db.Model(&model.ProviderJob{}).
+		Where("id = ? AND status = ? AND next_poll_at = ?", job.ID, model.ProviderJobPending, job.NextPollAt).
+		Update("next_poll_at", until)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	job.NextPollAt = until
+	return true, nil
+}
+
+// Reschedule stores a job's state after a poll and sets its next poll.
+func (r *ProviderJobRepository) Reschedule(job *model.ProviderJob) error {
+	return r.db.Model(&model.ProviderJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"data":         job.Data,
+		"polls":        job.Polls,
+		"failures":     job.Failures,
+		"next_poll_at": job.NextPollAt,
+		"error":        job.Error,
+	}).Error
+}
+
+// Finish sets a job's final status (done, failed or expired).
+func (r *ProviderJobRepository) Finish(job *model.ProviderJob) error {
+	now := time.Now()
+	job.FinishedAt = &now
+	return r.db.Model(&model.ProviderJob{}).Where("id = ?", job.ID).Updates(map[string]interface{}{
+		"status":      job.Status,
+		"polls":       job.Polls,
+		"error":       job.Error,
+		"finished_at": now,
+	}).Error
+}
+
+// ByLookupRequestID returns the jobs started by a lookup request.
+func (r *ProviderJobRepository) ByLookupRequestID(lookupRequestID int64) ([]model.ProviderJob, error) {
+	var list []model.ProviderJob
+	err := r.db.Where("lookup_request_id = ?", lookupRequestID).Find(&list).Error
+	return list, err
+}
+
+// ByLookupRequestIDs returns the jobs started by the given lookup requests, by lookup request id.
+func (r *ProviderJobRepository) ByLookupRequestIDs(lookupRequestIDs []int64) (map[int64][]model.ProviderJob, error) {
+	out := make(map[int64][]model.ProviderJob, len(lookupRequestIDs))
+	if len(lookupRequestIDs) == 0 {
+		return out, nil
+	}
+	var list []model.ProviderJob
+	if err := r.db.Where("lookup_request_id IN ?", lookupRequestIDs).Order("id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	for _, job := range list {
+		out[job.LookupRequestID] = append(out[job.LookupRequestID], job)
+	}
+	return out, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"time"
+
+	"hermes/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TAXIIRepository handles taxii_collections and taxii_collection_lookups, and lists the lookups
+// a TAXII collection serves.
+type TAXIIRepository struct {
+	db *gorm.DB
+}
+
+// NewTAXIIRepository creates a new repository.
+func NewTAXIIRepository(db *gorm.DB) *TAXIIRepository {
+	return &TAXIIRepository{db: db}
+}
+
+// CreateCollection stores a curated collection.
+func (r *TAXIIRepository) CreateCollection(c *model.TAXIICollection) error {
+	if c.CollectionID == uuid.Nil {
+		c.CollectionID = uuid.New()
+	}
+	return r.db.Create(c).Error
+}
+
+// ListCollections returns the curated collections, oldest first.
+func (r *TAXIIRepository) ListCollections() ([]model.TAXIICollection, error) {
+	var list []model.TAXIICollection
+	err := r.db.Order("id").Find(&list).Error
+	return list, err
+}
+
+// GetCollection loads a curated collection by collection_id or alias.
+func (r *TAXIIRepository) GetCollection(idOrAlias string) (*model.TAXIICollection, error) {
+	var c model.TAXIICollection
+	q := r.db.Where("alias = ?", idOrAlias)
+	if id, err := uuid.Parse(idOrAlias); err == nil {
+		q = r.db.Where("collection_id = ?", id)
+	}
+	if err := q.First(&c).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// DeleteCollection deletes a curated collection and its lookup links.
+func (r *TAXIIRepository) DeleteCollection(id int64) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&model.TAXIICollectionLookup{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.TAXIICollection{}, id).Error
+	})
+}
+
+// AddLookup adds a lookup request to a collection; adding it again keeps its first date_added.
+func (r *TAXIIRepository) AddLookup(collectionID, lookupRequestID int64) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "collection_id"}, {Name: "lookup_request_id"}},
+		DoNothing: true,
+	}).Create(&model.TAXIICollectionLookup{CollectionID: collectionID, LookupRequestID: lookupRequestID}).Error
+}
+
+// RemoveLookup removes a lookup request from a collection; it returns gorm.ErrRecordNotFound
+// when the lookup was not in it.
+func (r *TAXIIRepository) RemoveLookup(collectionID, lookupRequestID int64) error {
+	res := r.db.Where("collection_id = ? AND lookup_request_id = ?", collectionID, lookupRequestID).
+		Delete(&model.TAXIICollectionLookup{})
+	if res.Error == nil && res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return res.Error
+}
+
+// TAXIIEntry is a lookup served by a collection. Entries are ordered by (DateAdded, Seq).
+type TAXIIEntry struct {
+	// Seq breaks ties between entries added at the same time.
+	Seq       int64
+	RequestID uuid.UUID
+	DateAdded time.Time
+}
+
+// TAXIIFilter selects the entries of a collection. Empty fields do not filter.
+type TAXIIFilter struct {
+	// CollectionID lists a curated collection; 0 lists lookup requests directly, added when
+	// their verdict was last stored.
+	CollectionID int64
+	// Verdict and Since restrict lookup requests to an aggregate verdict stored since a time.
+	Verdict string
+	Since   *time.Time
+	// IndicatorTypes restricts lookup requests to indicator types, ObjectIDs to those exporting
+	// one of the STIX objects (see model.LookupObject).
+	IndicatorTypes []string
+	ObjectIDs      []string
+	// AddedAfter lists entries added strictly after it (TAXII added_after).
+	AddedAfter *time.Time
+	// From resumes a listing at an entry, inclusive.
+	From  *TAXIIEntry
+	Limit int
+}
+
+// Entries lists a collection's entries in (date_added, seq) order.
+func (r *TAXIIRepository) Entries(f TAXIIFilter) ([]TAXIIEntry, error) {
+	seq, added := "lookup_requests.id", "lookup_requests.updated_at"
+	q := r.db.Table("lookup_requests")
+	if f.CollectionID != 0 {
+		seq, added = "taxii_collection_lookups.id", "taxii_collection_lookups.date_added"
+		q = r.db.Table("taxii_collection_lookups").
+			Joins("JOIN lookup_requests ON lookup_requests.id = taxii_collection_lookups.lookup_request_id").
+			Where("taxii_collection_lookups.collection_id = ?", f.CollectionID)
+	}
+	if f.Verdict != "" {
+		q = q.Where("lookup_requests.verdict = ?", f.Verdict)
+	}
+	if f.Since != nil {
+		q = q.Where("lookup_requests.updated_at >= ?", *f.Since)
+	}
+	if len(f.IndicatorTypes) > 0 {
+		q = q.Where("lookup_requests.indicator_type IN ?", f.IndicatorTypes)
+	}
+	if len(f.ObjectIDs) > 0 {
+		q = q.Where("EXISTS (SELECT 1 FROM lookup_objects WHERE lookup_objects.lookup_request_id = lookup_requests.id AND lookup_objects.object_id IN ?)", f.ObjectIDs)
+	}
+	if f.AddedAfter != nil {
+		q = q.Where(added+" > ?", *f.AddedAfter)
+	}
+	if f.From != nil {
+		q = q.Where("("+added+" > ?) OR ("+added+" = ? AND "+seq+" >= ?)", f.From.DateAdded, f.From.DateAdded, f.From.Seq)
+	}
+	var list []TAXIIEntry
+	err := q.Select(seq + " AS seq, lookup_requests.request_id AS request_id, " + added + " AS date_added").
+		Order(added).Order(seq).Limit(f.Limit).Scan(&list).Error
+	return list, err
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"time"
+
+	"hermes/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsageRepository handles client_usage.
+type UsageRepository struct {
+	db *gorm.DB
+}
+
+// NewUsageRepository creates a new repository.
+func NewUsageRepository(db *gorm.DB) *UsageRepository {
+	return &UsageRepository{db: db}
+}
+
+// UsageTotals are a client's counters for the current day and month.
+type UsageTotals struct {
+	DayRequests        int64
+	DayProviderCalls   int64
+	MonthRequests      int64
+	MonthProviderCalls int64
+}
+
+// Add increments a client's counters for day (a UTC midnight).
+func (r *UsageRepository) Add(clientName string, day time.Time, requests, providerCalls int64) error {
+	row := model.ClientUsage{ClientName: clientName, Day: day, Requests: requests, ProviderCalls: providerCalls}
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_name"}, {Name: "day"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":       gorm.Expr("client_usage.requests + ?", requests),
+			"provider_calls": gorm.Expr("client_usage.provider_calls + ?", providerCalls),
+			"updated_at":     time.Now(),
+		}),
+	}).Create(&row).Error
+}
+
+// Totals sums a client's counters from monthStart, and separately for day.
+func (r *UsageRepository) Totals(clientName string, day, monthStart time.Time) (UsageTotals, error) {
+	var t UsageTotals
+	err := r.db.Model(&model.ClientUsage{}).
+		Select("COALESCE(SUM(CASE WHEN day = ? THEN requests ELSE 0 END), 0) AS day_requests, "+
+			"COALESCE(SUM(CASE WHEN day = ? THEN provider_calls ELSE 0 END), 0) AS day_provider_calls, "+
+			"COALESCE(SUM(requests), 0) AS month_requests, COALESCE(SUM(provider_calls), 0) AS month_provider_calls", day, day).
+		Where("client_name = ? AND day >= ?", clientName, monthStart).
+		Scan(&t).Error
+	return t, err
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hermes/internal/auth"
+	"hermes/internal/config"
+	"hermes/internal/dto"
+	"hermes/internal/model"
+	"hermes/internal/registry"
+	"hermes/internal/repository"
+	"hermes/internal/vo"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnauthenticated is returned by Authenticate for missing, unknown, expired or revoked keys.
+	ErrUnauthenticated = errors.New("invalid or missing API key")
+	// ErrInvalidScope is returned when a key is created with an unknown scope.
+	ErrInvalidScope = errors.New("invalid scope")
+	// ErrClientExists is returned by CreateClient for a name that is taken or reserved.
+	ErrClientExists = errors.New("client name is taken")
+)
+
+// bootstrapClient is the client name of the ADMIN_API_KEY principal. It is reserved so no client
+// owns the bootstrap principal's records.
+const bootstrapClient = "bootstrap"
+
+// lastUsedResolution limits last_used_at writes to one per key and interval.
+const lastUsedResolution = time.Minute
+
+// AuthService authenticates API keys and manages clients and keys.
+type AuthService struct {
+	cfg           *config.Config
+	repo          *repository.APIKeyRepository
+	registry      *registry.Registry
+	bootstrapHash string
+}
+
+// NewAuthService creates an auth service. cfg.AdminAPIKey, when set, authenticates as an admin
+// principal without a database row so the first clients and keys can be created.
+func NewAuthService(cfg *config.Config, reg *registry.Registry, db *gorm.DB) *AuthService {
+	s := &AuthService{cfg: cfg, repo: repository.NewAPIKeyRepository(db), registry: reg}
+	if cfg.AdminAPIKey != "" {
+		s.bootstrapHash = auth.HashKey(cfg.AdminAPIKey)
+	}
+	return s
+}
+
+// Authenticate resolves an API key to its principal.
+func (s *AuthService) Authenticate(key string) (*auth.Principal, error) {
+	if key == "" {
+		return nil, ErrUnauthenticated
+	}
+	hash := auth.HashKey(key)
+	if s.bootstrapHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(s.bootstrapHash)) == 1 {
+		return &auth.Principal{ClientName: bootstrapClient, KeyID: bootstrapClient, Scopes: []string{auth.ScopeAdmin}}, nil
+	}
+	k, err := s.repo.GetByHash(hash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUnauthenticated
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !k.Active(now) || k.Client == nil {
+		return nil, ErrUnauthenticated
+	}
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) > lastUsedResolution {
+		_ = s.repo.TouchLastUsed(k.ID, now)
+	}
+	return &auth.Principal{ClientID: k.ClientID, ClientName: k.Client.Name, KeyID: k.KeyID, Scopes: k.Scopes, Limits: clientLimits(s.cfg, k.Client)}, nil
+}
+
+// CreateClient creates a client with optional limit overrides. It returns ErrClientExists for a
+// name that is taken or reserved.
+func (s *AuthService) CreateClient(d *dto.CreateClientDTO) (*vo.ClientVO, error) {
+	c := &model.Client{
+		Name:            strings.TrimSpace(d.Name),
+		RateLimitPerMin: d.RateLimitPerMin,
+		DailyQuota:      d.DailyQuota,
+		MonthlyQuota:    d.MonthlyQuota,
+	}
+	if strings.EqualFold(c.Name, bootstrapClient) {
+		return nil, fmt.Errorf("%w: %q is reserved", ErrClientExists, c.Name)
+	}
+	if err := s.repo.CreateClient(c); errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, fmt.Errorf("%w: %q", ErrClientExists, c.Name)
+	} else if err != nil {
+		return nil, err
+	}
+	return s.clientVO(c), nil
+}
+
+// SetClientLimits replaces a client's limit overrides. It returns gorm.ErrRecordNotFound for
+// unknown clients.
+func (s *AuthService) SetClientLimits(clientID int64, d *dto.ClientLimitsDTO) (*vo.ClientVO, error) {
+	c, err := s.repo.GetClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+	c.RateLimitPerMin, c.DailyQuota, c.MonthlyQuota = d.RateLimitPerMin, d.DailyQuota, d.MonthlyQuota
+	if err := s.repo.UpdateClientLimits(c); err != nil {
+		return nil, err
+	}
+	return s.clientVO(c), nil
+}
+
+// ListClients lists clients with their keys (never the keys themselves).
+func (s *AuthService) ListClients() ([]vo.ClientVO, error) {
+	list, err := s.repo.ListClients()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]vo.ClientVO, 0, len(list))
+	for i := range list {
+		out = append(out, *s.clientVO(&list[i]))
+	}
+	return out, nil
+}
+
+// CreateKey issues a key for a client. The plaintext key is only returned here. It returns
+// gorm.ErrRecordNotFound for unknown clients and ErrInvalidScope for unknown scopes.
+func (s *AuthService) CreateKey(clientID int64, scopes []string, expiresAt *time.Time) (*vo.CreatedAPIKeyVO, error) {
+	if err := s.validateScopes(scopes); err != nil {
+		return nil, err
+	}
+	if _, err := s.repo.GetClient(clientID); err != nil {
+		return nil, err
+	}
+	key, k, err := newAPIKey(clientID, scopes, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.CreateKey(k); err != nil {
+		return nil, err
+	}
+	return &vo.CreatedAPIKeyVO{APIKeyVO: apiKeyVO(k), Key: key}, nil
+}
+
+// RotateKey issues a replacement with the same client, scopes and expiry; the old key keeps
+// working for grace (0 = stops immediately). It returns gorm.ErrRecordNotFound for unknown keys.
+func (s *AuthService) RotateKey(keyID string, grace time.Duration) (*vo.CreatedAPIKeyVO, error) {
+	prev, err := s.repo.GetByKeyID(keyID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !prev.Active(now) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	key, next, err := newAPIKey(prev.ClientID, prev.Scopes, prev.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	until := now.Add(grace)
+	if prev.ExpiresAt != nil && prev.ExpiresAt.Before(until) {
+		until = *prev.ExpiresAt
+	}
+	if err := s.repo.Rotate(prev, next, until); err != nil {
+		return nil, err
+	}
+	return &vo.CreatedAPIKeyVO{APIKeyVO: apiKeyVO(next), Key: key}, nil
+}
+
+// RevokeKey revokes a key immediately. It returns gorm.ErrRecordNotFound for unknown keys.
+func (s *AuthService) RevokeKey(keyID string) error {
+	k, err := s.repo.GetByKeyID(keyID)
+	if err != nil {
+		return err
+	}
+	if k.RevokedAt != nil {
+		return nil
+	}
+	return s.repo.Revoke(k.ID, time.Now())
+}
+
+// validateScopes accepts lookup:read, taxii:read, misp:push, blocklist:read, admin, provider:* and
+// provider:<registered code>.
+func (s *AuthService) validateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("%w: at least one scope is required", ErrInvalidScope)
+	}
+	for _, sc := range scopes {
+		switch {
+		case sc == auth.ScopeLookupRead, sc == auth.ScopeTAXIIRead, sc == auth.ScopeMISPPush, sc == auth.ScopeBlocklistRead,
+			sc == auth.ScopeAdmin, sc == auth.ScopeAllProviders:
+		case strings.HasPrefix(sc, "provider:") && s.registry.AdapterByCode(strings.TrimPrefix(sc, "provider:")) != nil:
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidScope, sc)
+		}
+	}
+	return nil
+}
+
+func newAPIKey(clientID int64, scopes []string, expiresAt *time.Time) (string, *model.APIKey, error) {
+	key, keyID, hash, err := auth.GenerateKey()
+	if err != nil {
+		return "", nil, err
+	}
+	return key, &model.APIKey{ClientID: clientID, KeyID: keyID, KeyHash: hash, Scopes: scopes, ExpiresAt: expiresAt}, nil
+}
+
+func (s *AuthService) clientVO(c *model.Client) *vo.ClientVO {
+	l := clientLimits(s.cfg, c)
+	out := &vo.ClientVO{
+		ID:        c.ID,
+		Name:      c.Name,
+		CreatedAt: c.CreatedAt,
+		Limits:    vo.ClientLimitsVO{RateLimitPerMin: l.RatePerMin, DailyQuota: l.DailyCalls, MonthlyQuota: l.MonthlyCalls},
+		Keys:      make([]vo.APIKeyVO, 0, len(c.APIKeys)),
+	}
+	for i := range c.APIKeys {
+		out.Keys = append(out.Keys, apiKeyVO(&c.APIKeys[i]))
+	}
+	return out
+}
+
+func apiKeyVO(k *model.APIKey) vo.APIKeyVO {
+	return vo.APIKeyVO{
+		KeyID:      k.KeyID,
+		ClientID:   k.ClientID,
+		Scopes:     k.Scopes,
+		Active:     k.Active(time.Now()),
+		CreatedAt:  k.CreatedAt,
+		ExpiresAt:  k.ExpiresAt,
+		RevokedAt:  k.RevokedAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
@@ -0,0 +1,486 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"hermes/internal/blocklist"
+	"hermes/internal/config"
+	"hermes/internal/dto"
+	"hermes/internal/indicator"
+	"hermes/internal/logging"
+	"hermes/internal/model"
+	"hermes/internal/providerapi"
+	"hermes/internal/repository"
+	"hermes/internal/verdict"
+	"hermes/internal/vo"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidBlocklist is returned for blocklist definitions or renderings that cannot be made
+// as asked.
+var ErrInvalidBlocklist = errors.New("invalid blocklist")
+
+var blocklistName = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// blocklistRefresh is how often Run looks for blocklist entries that changed.
+const blocklistRefresh = time.Minute
+
+var blocklistLog = logging.For("blocklist")
+
+// BlocklistService defines blocklists over stored verdicts, keeps the allowlist, and renders
+// blocklists for firewalls, DNS resolvers, proxies and IDS.
+type BlocklistService struct {
+	repo    *repository.BlocklistRepository
+	weights map[string]float64
+
+	mu sync.Mutex
+	// rendered holds the last rendering of each list and hash type, by name and hash type.
+	rendered map[string]*renderedList
+}
+
+// NewBlocklistService creates a blocklist service aggregating verdicts with cfg's provider
+// weights.
+func NewBlocklistService(cfg *config.Config, db *gorm.DB) *BlocklistService {
+	return &BlocklistService{
+		repo:     repository.NewBlocklistRepository(db),
+		weights:  cfg.ProviderWeights,
+		rendered: make(map[string]*renderedList),
+	}
+}
+
+// Version holds the validators of a blocklist rendering: ETag changes with the list's entries,
+// and ModifiedAt is when they last changed.
+type Version struct {
+	ETag       string
+	ModifiedAt time.Time
+}
+
+// Rendered is a rendered blocklist with its validators.
+type Rendered struct {
+	Body        []byte
+	ContentType string
+	Version
+}
+
+// renderedList is a rendering of the entries hashed to hash.
+type renderedList struct {
+	Rendered
+	hash string
+}
+
+// List returns the blocklists by name.
+func (s *BlocklistService) List() ([]vo.BlocklistVO, error) {
+	list, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	out := make([]vo.BlocklistVO, 0, len(list))
+	for i := range list {
+		out = append(out, blocklistVO(&list[i]))
+	}
+	return out, nil
+}
+
+// Create defines a blocklist. It returns ErrInvalidBlocklist for bad or taken names and formats
+// the indicator type cannot be rendered in.
+func (s *BlocklistService) Create(d *dto.BlocklistDTO) (*vo.BlocklistVO, error) {
+	if !blocklistName.MatchString(d.Name) {
+		return nil, fmt.Errorf("%w: name must be 1-64 lowercase letters, digits, '-', '_' or '.'", ErrInvalidBlocklist)
+	}
+	if _, err := s.repo.GetByName(d.Name); err == nil {
+		return nil, fmt.Errorf("%w: name %q is taken", ErrInvalidBlocklist, d.Name)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	b := &model.Blocklist{Name: d.Name}
+	if err := applyBlocklistDTO(b, d); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(b); err != nil {
+		return nil, err
+	}
+	if err := s.refresh(b); err != nil {
+		return nil, err
+	}
+	v := blocklistVO(b)
+	return &v, nil
+}
+
+// Update replaces the definition of a blocklist; see Create. It returns gorm.ErrRecordNotFound
+// for unknown names.
+func (s *BlocklistService) Update(name string, d *dto.BlocklistDTO) (*vo.BlocklistVO, error) {
+	b, err := s.repo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyBlocklistDTO(b, d); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(b); err != nil {
+		return nil, err
+	}
+	if err := s.refresh(b); err != nil {
+		return nil, err
+	}
+	v := blocklistVO(b)
+	return &v, nil
+}
+
+// Delete deletes a blocklist; it returns gorm.ErrRecordNotFound for unknown names.
+func (s *BlocklistService) Delete(name string) error {
+	if err := s.repo.Delete(name); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	for key := range s.rendered {
+		if strings.HasPrefix(key, name+"\x00") {
+			delete(s.rendered, key)
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+func applyBlocklistDTO(b *model.Blocklist, d *dto.BlocklistDTO) error {
+	if !blocklist.Supports(d.IndicatorType, d.Format) {
+		return fmt.Errorf("%w: %s lists cannot be rendered as %s", ErrInvalidBlocklist, d.IndicatorType, d.Format)
+	}
+	verdicts := d.Verdicts
+	if len(verdicts) == 0 {
+		verdicts = []string{"malicious"}
+	}
+	b.Description = d.Description
+	b.IndicatorType = d.IndicatorType
+	b.Format = d.Format
+	b.Verdicts = verdicts
+	b.MinScore = d.MinScore
+	b.MaxAgeHours = d.MaxAgeHours
+	b.MinProviders = d.MinProviders
+	return nil
+}
+
+// Version returns the validators of a blocklist's rendering without rendering it: those of its
+// entries as of the last refresh (see Run). hashType and the errors are those of Render.
+func (s *BlocklistService) Version(name, hashType string) (*Version, error) {
+	b, err := s.get(name, hashType)
+	if err != nil {
+		return nil, err
+	}
+	if b.ModifiedAt == nil {
+		// Never refreshed: the entries are as of now.
+		_, hash, err := s.contents(b)
+		if err != nil {
+			return nil, err
+		}
+		return &Version{ETag: blocklistETag(hash, hashType), ModifiedAt: time.Now().UTC().Truncate(time.Second)}, nil
+	}
+	return &Version{ETag: blocklistETag(b.ContentHash, hashType), ModifiedAt: *b.ModifiedAt}, nil
+}
+
+// Render renders a blocklist without changing it. The rendering of the entries as of the last
+// refresh is kept and served until the next refresh records a change. hashType, for hash lists
+// only, renders the plain list of the hashes of that algorithm that the list's Suricata rules
+// load. It returns gorm.ErrRecordNotFound for unknown names and ErrInvalidBlocklist for a bad
+// hashType.
+func (s *BlocklistService) Render(name, hashType string) (*Rendered, error) {
+	b, err := s.get(name, hashType)
+	if err != nil {
+		return nil, err
+	}
+	key := name + "\x00" + hashType
+	s.mu.Lock()
+	cached := s.rendered[key]
+	s.mu.Unlock()
+	if cached != nil && b.ModifiedAt != nil && cached.hash == b.ContentHash {
+		return &cached.Rendered, nil
+	}
+
+	l, hash, err := s.contents(b)
+	if err != nil {
+		return nil, err
+	}
+	// Entries that changed since the last refresh are served as modified now; the refresh
+	// records it.
+	stored := b.ModifiedAt != nil && hash == b.ContentHash
+	l.Modified = time.Now().UTC().Truncate(time.Second)
+	if stored {
+		l.Modified = *b.ModifiedAt
+	}
+	kept := l.Values
+	if hashType != "" {
+		l.Format = blocklist.FormatPlain
+		l.Values = nil
+		for _, v := range kept {
+			if l.HashTypes[v] == hashType {
+				l.Values = append(l.Values, v)
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := blocklist.Render(&buf, l); err != nil {
+		return nil, err
+	}
+	res := &renderedList{
+		Rendered: Rendered{
+			Body:        buf.Bytes(),
+			ContentType: blocklist.ContentType(l.Format),
+			Version:     Version{ETag: blocklistETag(hash, hashType), ModifiedAt: l.Modified},
+		},
+		hash: hash,
+	}
+	if stored {
+		s.mu.Lock()
+		s.rendered[key] = res
+		s.mu.Unlock()
+	}
+	return &res.Rendered, nil
+}
+
+// get loads a blocklist to render with hashType.
+func (s *BlocklistService) get(name, hashType string) (*model.Blocklist, error) {
+	b, err := s.repo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	switch hashType {
+	case "":
+	case indicator.HashMD5, indicator.HashSHA1, indicator.HashSHA256:
+		if b.IndicatorType != indicator.TypeHash {
+			return nil, fmt.Errorf("%w: hash_type applies to hash lists only", ErrInvalidBlocklist)
+		}
+	default:
+		return nil, fmt.Errorf("%w: hash_type must be md5, sha1 or sha256", ErrInvalidBlocklist)
+	}
+	return b, nil
+}
+
+// blocklistETag is the ETag of a rendering with hashType of entries hashed to hash.
+func blocklistETag(hash, hashType string) string {
+	sum := sha256.Sum256([]byte(hash + "\n" + hashType))
+	return strconv.Quote(hex.EncodeToString(sum[:16]))
+}
+
+// contents returns the entries of b, allowlist applied, and their hash. The hash covers the
+// definition, so changing it counts as a change too.
+func (s *BlocklistService) contents(b *model.Blocklist) (*blocklist.List, string, error) {
+	values, err := s.values(b)
+	if err != nil {
+		return nil, "", err
+	}
+	entries, err := s.repo.AllowlistEntries("")
+	if err != nil {
+		return nil, "", err
+	}
+	allow := blocklist.NewAllowlist()
+	for _, e := range entries {
+		allow.Add(e.IndicatorType, e.Value)
+	}
+	kept := values[:0]
+	for _, v := range values {
+		if !allow.Allows(b.IndicatorType, v) {
+			kept = append(kept, v)
+		}
+	}
+	var exceptions []string
+	if b.IndicatorType == indicator.TypeDomain {
+		exceptions = allow.Under(kept)
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n", b.UpdatedAt.UnixNano())
+	for _, v := range kept {
+		fmt.Fprintln(h, v)
+	}
+	for _, v := range exceptions {
+		fmt.Fprintln(h, "!"+v)
+	}
+
+	l := &blocklist.List{Name: b.Name, IndicatorType: b.IndicatorType, Format: b.Format, Values: kept, Exceptions: exceptions}
+	if b.IndicatorType == indicator.TypeHash {
+		l.HashTypes = make(map[string]string, len(kept))
+		for _, v := range kept {
+			if ind, err := indicator.Canonicalize(indicator.TypeHash, v, indicator.Options{}); err == nil {
+				l.HashTypes[v] = ind.Subtype
+			}
+		}
+	}
+	return l, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// values returns the indicators b selects, sorted: those whose providers' latest results, within
+// MaxAgeHours, aggregate with the current weights to one of b's verdicts and at least MinScore,
+// with MinProviders of them having one of the verdicts.
+func (s *BlocklistService) values(b *model.Blocklist) ([]string, error) {
+	var since *time.Time
+	if b.MaxAgeHours > 0 {
+		t := time.Now().Add(-time.Duration(b.MaxAgeHours) * time.Hour)
+		since = &t
+	}
+	votes, err := s.repo.Votes(b.IndicatorType, since)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(b.Verdicts))
+	for _, v := range b.Verdicts {
+		wanted[v] = true
+	}
+	var values []string
+	for i := 0; i < len(votes); {
+		j, agreeing := i, 0
+		var vs []verdict.Vote
+		for ; j < len(votes) && votes[j].IndicatorHash == votes[i].IndicatorHash; j++ {
+			if wanted[votes[j].Verdict] {
+				agreeing++
+			}
+			vs = append(vs, verdict.Vote{ProviderCode: votes[j].ProviderCode, Assessment: providerapi.Assessment{
+				Verdict: providerapi.Verdict(votes[j].Verdict),
+				Score:   votes[j].Score,
+			}})
+		}
+		sum := verdict.Aggregate(vs, s.weights)
+		if wanted[string(sum.Verdict)] && sum.Score >= b.MinScore && agreeing >= b.MinProviders {
+			values = append(values, votes[j-1].IndicatorValue)
+		}
+		i = j
+	}
+	sort.Strings(values)
+	return values, nil
+}
+
+// refresh records when the entries of b last changed, if they have since the last refresh.
+func (s *BlocklistService) refresh(b *model.Blocklist) error {
+	_, hash, err := s.contents(b)
+	if err != nil || (b.ModifiedAt != nil && hash == b.ContentHash) {
+		return err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	if err := s.repo.SetContent(b.ID, hash, now); err != nil {
+		return err
+	}
+	b.ContentHash, b.ModifiedAt = hash, &now
+	return nil
+}
+
+// refreshAll refreshes every blocklist, logging failures.
+func (s *BlocklistService) refreshAll(ctx context.Context) {
+	list, err := s.repo.List()
+	if err != nil {
+		blocklistLog.ErrorContext(ctx, "list blocklists", "error", err)
+		return
+	}
+	for i := range list {
+		if err := s.refresh(&list[i]); err != nil {
+			blocklistLog.ErrorContext(ctx, "refresh blocklist", "blocklist", list[i].Name, "error", err)
+		}
+	}
+}
+
+// Run refreshes the blocklists every blocklistRefresh, catching entries that new verdicts add
+// and MaxAgeHours expires, until ctx is cancelled. Changes made through the service are
+// recorded as they are made.
+func (s *BlocklistService) Run(ctx context.Context) {
+	t := time.NewTicker(blocklistRefresh)
+	defer t.Stop()
+	for {
+		s.refreshAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// AddAllowlistEntry allowlists an indicator, canonicalized; IPs may be CIDR blocks. It returns an
+// *indicator.Error for invalid values and ErrInvalidBlocklist for duplicates.
+func (s *BlocklistService) AddAllowlistEntry(d *dto.AllowlistEntryDTO) (*vo.AllowlistEntryVO, error) {
+	value := strings.TrimSpace(d.Value)
+	if p, err := netip.ParsePrefix(value); err == nil && d.IndicatorType == indicator.TypeIP {
+		value = p.Masked().String()
+	} else {
+		ind, err := indicator.Canonicalize(d.IndicatorType, value, indicator.Options{AllowPrivate: true})
+		if err != nil {
+			return nil, err
+		}
+		value = ind.Value
+	}
+	existing, err := s.repo.AllowlistEntries(d.IndicatorType)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range existing {
+		if e.Value == value {
+			return nil, fmt.Errorf("%w: %s is already allowlisted", ErrInvalidBlocklist, value)
+		}
+	}
+	e := &model.AllowlistEntry{IndicatorType: d.IndicatorType, Value: value, Comment: d.Comment}
+	if err := s.repo.AddAllowlistEntry(e); err != nil {
+		return nil, err
+	}
+	s.refreshAll(context.Background())
+	v := allowlistEntryVO(e)
+	return &v, nil
+}
+
+// AllowlistEntries returns the allowlist, oldest first.
+func (s *BlocklistService) AllowlistEntries() ([]vo.AllowlistEntryVO, error) {
+	list, err := s.repo.AllowlistEntries("")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]vo.AllowlistEntryVO, 0, len(list))
+	for i := range list {
+		out = append(out, allowlistEntryVO(&list[i]))
+	}
+	return out, nil
+}
+
+// DeleteAllowlistEntry removes an allowlist entry; it returns gorm.ErrRecordNotFound for unknown
+// IDs.
+func (s *BlocklistService) DeleteAllowlistEntry(id int64) error {
+	if err := s.repo.DeleteAllowlistEntry(id); err != nil {
+		return err
+	}
+	s.refreshAll(context.Background())
+	return nil
+}
+
+func blocklistVO(b *model.Blocklist) vo.BlocklistVO {
+	return vo.BlocklistVO{
+		Name:          b.Name,
+		Description:   b.Description,
+		IndicatorType: b.IndicatorType,
+		Format:        b.Format,
+		Verdicts:      b.Verdicts,
+		MinScore:      b.MinScore,
+		MaxAgeHours:   b.MaxAgeHours,
+		MinProviders:  b.MinProviders,
+		Path:          "/api/v1/blocklists/" + b.Name,
+		ModifiedAt:    b.ModifiedAt,
+		CreatedAt:     b.CreatedAt,
+		UpdatedAt:     b.UpdatedAt,
+	}
+}
+
+func allowlistEntryVO(e *model.AllowlistEntry) vo.AllowlistEntryVO {
+	return vo.AllowlistEntryVO{
+		ID:            e.ID,
+		IndicatorType: e.IndicatorType,
+		Value:         e.Value,
+		Comment:       e.Comment,
+		CreatedAt:     e.CreatedAt,
+	}
+}
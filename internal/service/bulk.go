@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"hermes/internal/auth"
+	"hermes/internal/config"
+	"hermes/internal/dto"
+	"hermes/internal/indicator"
+	"hermes/internal/logging"
+	"hermes/internal/model"
+	"hermes/internal/repository"
+	"hermes/internal/usage"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrBulkInput is returned by Submit for an empty or oversized batch.
+	ErrBulkInput = errors.New("invalid bulk input")
+	// ErrQuotaExceeded marks bulk items skipped because the client's provider-call quota is used up.
+	ErrQuotaExceeded = errors.New("provider-call quota exceeded")
+)
+
+var bulkLog = logging.For("bulk")
+
+// BulkService runs bulk lookups as background jobs on top of LookupService. Lookups of all jobs
+// share one pool of BulkConcurrency slots; provider rate limits apply through the registry as
+// for single lookups, and provider calls count against the submitting client's quotas.
+type BulkService struct {
+	cfg      *config.Config
+	lookup   *LookupService
+	usageSvc *UsageService
+	repo     *repository.BulkJobRepository
+	slots    chan struct{}
+
+	// mu guards ctx, the context of Run (nil while it is not running), and running, the jobs
+	// being processed by this process.
+	mu      sync.Mutex
+	ctx     context.Context
+	running map[int64]bool
+	wg      sync.WaitGroup
+}
+
+// NewBulkService creates a bulk service using lookup for the individual lookups. usageSvc may
+// be nil to run jobs unmetered.
+func NewBulkService(cfg *config.Config, lookup *LookupService, usageSvc *UsageService, db *gorm.DB) *BulkService {
+	n := cfg.BulkConcurrency
+	if n <= 0 {
+		n = 1
+	}
+	return &BulkService{
+		cfg:      cfg,
+		lookup:   lookup,
+		usageSvc: usageSvc,
+		repo:     repository.NewBulkJobRepository(db),
+		slots:    make(chan struct{}, n),
+		running:  make(map[int64]bool),
+	}
+}
+
+// Submit validates and de-duplicates the indicators, stores the job and starts processing it in
+// the background if Run is active; otherwise the job stays queued for the next Run. Invalid indicators are recorded as failed items rather than rejecting the batch.
+func (s *BulkService) Submit(ctx context.Context, indicators []dto.BulkIndicatorDTO, providers []string, cacheMode string) (*vo.BulkJobVO, error) {
+	if len(indicators) == 0 {
+		return nil, fmt.Errorf("%w: no indicators", ErrBulkInput)
+	}
+	if max := s.cfg.BulkMaxIndicators; max > 0 && len(indicators) > max {
+		return nil, fmt.Errorf("%w: %d indicators exceed the limit of %d", ErrBulkInput, len(indicators), max)
+	}
+
+	job := &model.BulkJob{JobID: uuid.New(), Status: model.BulkJobQueued, Providers: providers, CacheMode: cacheMode, UserID: auth.UserID(ctx)}
+	items := make([]model.BulkJobItem, 0, len(indicators))
+	seen := make(map[string]bool, len(indicators))
+	for _, in := range indicators {
+		typ := in.IndicatorType
+		if typ == "" {
+			typ = indicator.Detect(in.IndicatorValue)
+		}
+		item := model.BulkJobItem{IndicatorType: typ, IndicatorValue: in.IndicatorValue, Status: model.BulkItemPending}
+		if ind, err := indicator.Canonicalize(typ, in.IndicatorValue, indicator.Options{AllowPrivate: s.cfg.AllowPrivateIndicators}); err != nil {
+			item.Status = model.BulkItemInvalid
+			item.Error = err.Error()
+		} else {
+			item.IndicatorValue = ind.Value
+		}
+		key := item.IndicatorType + "\x00" + item.IndicatorValue
+		if seen[key] {
+			job.Duplicates++
+			continue
+		}
+		seen[key] = true
+		item.Position = len(items)
+		if item.Status == model.BulkItemInvalid {
+			job.Failed++
+		}
+		items = append(items, item)
+	}
+	job.Total = len(items)
+
+	if err := s.repo.Create(job, items); err != nil {
+		return nil, err
+	}
+	s.start(job)
+	return s.Get(ctx, job.JobID, 1, 0)
+}
+
+// Run restarts jobs left queued or running by a previous process and processes submitted jobs
+// until ctx is cancelled. It then waits for the lookups in flight; items not started yet stay
+// pending and their jobs running, to be resumed by the next Run.
+func (s *BulkService) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	jobs, err := s.repo.Unfinished()
+	if err != nil {
+		bulkLog.Error("list unfinished jobs", "error", err)
+	}
+	for i := range jobs {
+		s.start(&jobs[i])
+	}
+
+	<-ctx.Done()
+	s.mu.Lock()
+	s.ctx = nil
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// start processes job in the background unless Run is inactive or the job is already running.
+func (s *BulkService) start(job *model.BulkJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx == nil || s.running[job.ID] {
+		return
+	}
+	s.running[job.ID] = true
+	s.wg.Add(1)
+	go func(ctx context.Context) {
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, job.ID)
+			s.mu.Unlock()
+			s.wg.Done()
+		}()
+		s.run(ctx, job)
+	}(s.ctx)
+}
+
+// run looks up the job's pending items, at most BulkConcurrency at a time across all jobs. A job
+// that cannot be started is marked failed.
+func (s *BulkService) run(ctx context.Context, job *model.BulkJob) {
+	if err := s.repo.SetStatus(job.ID, model.BulkJobRunning); err != nil {
+		s.fail(job, "start job", err)
+		return
+	}
+	items, err := s.repo.PendingItems(job.ID)
+	if err != nil {
+		s.fail(job, "list pending items", err)
+		return
+	}
+
+	var wg sync.WaitGroup
+	for i := range items {
+		select {
+		case s.slots <- struct{}{}:
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		}
+		wg.Add(1)
+		go func(item *model.BulkJobItem) {
+			defer func() { <-s.slots; wg.Done() }()
+			// Lookups in flight finish on shutdown rather than being recorded as errors.
+			s.lookupItem(context.WithoutCancel(ctx), job, item)
+		}(&items[i])
+	}
+	wg.Wait()
+
+	if err := s.repo.SetStatus(job.ID, model.BulkJobCompleted); err != nil {
+		bulkLog.Error("complete job", "job_id", job.JobID, "error", err)
+	}
+}
+
+// fail marks job failed because step returned err.
+func (s *BulkService) fail(job *model.BulkJob, step string, err error) {
+	bulkLog.Error(step, "job_id", job.JobID, "error", err)
+	if err := s.repo.Fail(job.ID, step+": "+err.Error()); err != nil {
+		bulkLog.Error("fail job", "job_id", job.JobID, "error", err)
+	}
+}
+
+func (s *BulkService) lookupItem(ctx context.Context, job *model.BulkJob, item *model.BulkJobItem) {
+	// Lookups are recorded under, and charged to, the submitting client.
+	metered := s.usageSvc != nil && job.UserID != nil
+	if job.UserID != nil {
+		ctx = auth.WithPrincipal(ctx, &auth.Principal{ClientName: *job.UserID})
+	}
+	if metered {
+		exhausted, err := s.usageSvc.QuotaExhausted(*job.UserID)
+		if err == nil && exhausted {
+			err = ErrQuotaExceeded
+		}
+		if err != nil {
+			item.Status = model.BulkItemError
+			item.Error = err.Error()
+			if err := s.repo.FinishItem(item); err != nil {
+				bulkLog.Error("finish item", "job_id", job.JobID, "position", item.Position, "error", err)
+			}
+			return
+		}
+	}
+	meter := &usage.Meter{}
+	ctx = usage.WithMeter(ctx, meter)
+	res, err := s.lookup.Lookup(ctx, &dto.LookupRequestDTO{
+		IndicatorType:  item.IndicatorType,
+		IndicatorValue: item.IndicatorValue,
+		Providers:      job.Providers,
+		Cache:          job.CacheMode,
+	})
+	if err != nil {
+		item.Status = model.BulkItemError
+		item.Error = err.Error()
+	} else {
+		id, _ := uuid.Parse(res.RequestID)
+		item.Status = model.BulkItemDone
+		item.LookupRequestID = &id
+		if res.Verdict != nil {
+			item.Verdict = res.Verdict.Verdict
+			item.Score = res.Verdict.Score
+		}
+	}
+	if metered {
+		if err := s.usageSvc.Record(*job.UserID, 0, meter.ProviderCalls()); err != nil {
+			bulkLog.Error("record usage", "job_id", job.JobID, "error", err)
+		}
+	}
+	if err := s.repo.FinishItem(item); err != nil {
+		bulkLog.Error("finish item", "job_id", job.JobID, "position", item.Position, "error", err)
+	}
+}
+
+// Get returns a job's progress and one page of its items (page from 1; pageSize 0 = no items).
+// It returns gorm.ErrRecordNotFound for unknown jobs and for other clients' jobs.
+func (s *BulkService) Get(ctx context.Context, jobID uuid.UUID, page, pageSize int) (*vo.BulkJobVO, error) {
+	job, err := s.repo.GetByJobID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !visibleTo(ctx, job.UserID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	out := &vo.BulkJobVO{
+		JobID:      job.JobID.String(),
+		Status:     job.Status,
+		Error:      job.Error,
+		Total:      job.Total,
+		Completed:  job.Completed,
+		Failed:     job.Failed,
+		Duplicates: job.Duplicates,
+		CreatedAt:  job.CreatedAt,
+		FinishedAt: job.FinishedAt,
+		Page:       page,
+		PageSize:   pageSize,
+		Items:      []vo.BulkJobItemVO{},
+	}
+	if pageSize <= 0 {
+		return out, nil
+	}
+	items, err := s.repo.ListItems(job.ID, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range items {
+		v := vo.BulkJobItemVO{
+			Position:       it.Position,
+			IndicatorType:  it.IndicatorType,
+			IndicatorValue: it.IndicatorValue,
+			Status:         it.Status,
+			Verdict:        it.Verdict,
+			Score:          it.Score,
+			Error:          it.Error,
+		}
+		if it.LookupRequestID != nil {
+			v.RequestID = it.LookupRequestID.String()
+		}
+		out.Items = append(out.Items, v)
+	}
+	return out, nil
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hermes/internal/config"
+	"hermes/internal/dto"
+	"hermes/internal/model"
+	"hermes/internal/registry"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkService_DedupesAndCompletes(t *testing.T) {
+	db := setupTestDB(t)
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // one in-memory database shared by the workers
+	assert.NoError(t, db.AutoMigrate(&model.BulkJob{}, &model.BulkJobItem{}))
+
+	var calls int32
+	cfg := &config.Config{CacheTTLSeconds: 3600, BulkConcurrency: 2, BulkMaxIndicators: 10}
+	lookup := NewLookupService(cfg, registry.New(countingAdapter(&calls)), db)
+	svc := NewBulkService(cfg, lookup, nil, db)
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() { svc.Run(ctx); close(stopped) }()
+	defer func() { cancel(); <-stopped }()
+
+	job, err := svc.Submit(context.Background(), []dto.BulkIndicatorDTO{
+		{IndicatorType: "domain", IndicatorValue: "example.com"},
+		{IndicatorValue: "Example.COM."}, // duplicate after detection and canonicalization
+		{IndicatorValue: "evil[.]example"},
+		{IndicatorType: "ip", IndicatorValue: "example.org"}, // invalid
+	}, nil, dto.CacheBypass)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, job.Total)
+	assert.Equal(t, 1, job.Duplicates)
+
+	id := uuid.MustParse(job.JobID)
+	assert.Eventually(t, func() bool {
+		j, err := svc.Get(context.Background(), id, 1, 0)
+		return err == nil && j.Status == model.BulkJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+
+	done, err := svc.Get(context.Background(), id, 1, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, done.Completed)
+	assert.Equal(t, 1, done.Failed)
+	assert.NotNil(t, done.FinishedAt)
+	assert.Len(t, done.Items, 3)
+	assert.Equal(t, "example.com", done.Items[0].IndicatorValue)
+	assert.NotEmpty(t, done.Items[0].RequestID)
+	assert.Equal(t, "evil.example", done.Items[1].IndicatorValue)
+	assert.Equal(t, model.BulkItemInvalid, done.Items[2].Status)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	page, err := svc.Get(context.Background(), id, 2, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+
+	lookups, err := svc.Lookups(context.Background(), id)
+	assert.NoError(t, err)
+	if assert.Len(t, lookups, 2) {
+		assert.Equal(t, done.Items[0].RequestID, lookups[0].RequestID)
+		assert.Equal(t, "evil.example", lookups[1].IndicatorValue)
+		assert.NotEmpty(t, lookups[1].Results)
+	}
+
+	_, err = svc.Submit(context.Background(), make([]dto.BulkIndicatorDTO, 11), nil, dto.CachePrefer)
+	assert.ErrorIs(t, err, ErrBulkInput)
+}
+
+func TestBulkService_FailsJobThatCannotStart(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&model.BulkJob{}, &model.BulkJobItem{}))
+	cfg := &config.Config{BulkConcurrency: 1}
+	svc := NewBulkService(cfg, NewLookupService(cfg, registry.New(), db), nil, db)
+
+	job := &model.BulkJob{JobID: uuid.New(), Status: model.BulkJobQueued, Total: 1}
+	assert.NoError(t, svc.repo.Create(job, []model.BulkJobItem{{IndicatorType: "domain", IndicatorValue: "example.com"}}))
+	assert.NoError(t, db.Migrator().DropTable(&model.BulkJobItem{}))
+
+	svc.run(context.Background(), job)
+	got, err := svc.Get(context.Background(), job.JobID, 1, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, model.BulkJobFailed, got.Status)
+	assert.Contains(t, got.Error, "list pending items")
+	assert.NotNil(t, got.FinishedAt)
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"hermes/internal/dto"
+	"hermes/internal/model"
+	"hermes/internal/providerapi"
+	"hermes/internal/report"
+	"hermes/internal/repository"
+	"hermes/internal/stix"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// exportPageSize is how many bulk job items are read at a time when exporting a job.
+	exportPageSize = 500
+	// maxExportLookups caps the lookups of a bulk job exported as one STIX bundle or MISP
+	// event, which are built in memory; CSV and XLSX reports are streamed and not capped.
+	maxExportLookups = 10000
+)
+
+// ErrExportTooLarge is returned when a bulk job has too many lookups to export as one document.
+var ErrExportTooLarge = errors.New("export too large")
+
+// STIX returns a past lookup as a STIX 2.1 bundle. It returns gorm.ErrRecordNotFound like Get.
+func (s *LookupService) STIX(ctx context.Context, requestID uuid.UUID) (*stix.Bundle, error) {
+	l, err := s.Get(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	b := stix.NewBuilder(s.registry.Name)
+	if err := b.Add(l); err != nil {
+		return nil, err
+	}
+	return b.Bundle("lookup:" + l.RequestID), nil
+}
+
+// Lookups returns the lookups of a job's finished items in input order; items without a
+// lookup (invalid, failed or pending) are left out. It returns gorm.ErrRecordNotFound like Get,
+// and ErrExportTooLarge for jobs with more than maxExportLookups lookups, which are only
+// exported as reports.
+func (s *BulkService) Lookups(ctx context.Context, jobID uuid.UUID) ([]*vo.LookupResponseVO, error) {
+	job, err := s.visibleJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Completed > maxExportLookups {
+		return nil, fmt.Errorf("%w: %d lookups exceed the limit of %d", ErrExportTooLarge, job.Completed, maxExportLookups)
+	}
+	out := make([]*vo.LookupResponseVO, 0, job.Completed)
+	err = s.eachItem(ctx, job, func(_ *model.BulkJobItem, l *vo.LookupResponseVO) error {
+		if l != nil {
+			out = append(out, l)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// visibleJob loads a bulk job the caller may see; see Get.
+func (s *BulkService) visibleJob(ctx context.Context, jobID uuid.UUID) (*model.BulkJob, error) {
+	job, err := s.repo.GetByJobID(jobID)
+	if err != nil {
+		return nil, err
+	}
+	if !visibleTo(ctx, job.UserID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return job, nil
+}
+
+// eachItem calls fn with each item of a job in input order and its lookup, nil for items
+// without one, reading exportPageSize items and their lookups at a time.
+func (s *BulkService) eachItem(ctx context.Context, job *model.BulkJob, fn func(*model.BulkJobItem, *vo.LookupResponseVO) error) error {
+	for offset := 0; ; offset += exportPageSize {
+		items, err := s.repo.ListItems(job.ID, offset, exportPageSize)
+		if err != nil {
+			return err
+		}
+		lookups, err := s.itemLookups(items)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			var l *vo.LookupResponseVO
+			if id := items[i].LookupRequestID; id != nil {
+				l = lookups[*id]
+			}
+			if err := fn(&items[i], l); err != nil {
+				return err
+			}
+		}
+		if len(items) < exportPageSize {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// itemLookups returns the stored lookups of items by request ID. Lookups that no longer exist
+// are missing from the map.
+func (s *BulkService) itemLookups(items []model.BulkJobItem) (map[uuid.UUID]*vo.LookupResponseVO, error) {
+	ids := make([]uuid.UUID, 0, len(items))
+	for i := range items {
+		if id := items[i].LookupRequestID; id != nil {
+			ids = append(ids, *id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	reqs, err := s.lookup.reqRepo.GetByRequestIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.lookup.responses(reqs)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[uuid.UUID]*vo.LookupResponseVO, len(list))
+	for i := range reqs {
+		out[reqs[i].RequestID] = list[i]
+	}
+	return out, nil
+}
+
+// STIX returns the lookups of a bulk job as one STIX 2.1 bundle; see Lookups.
+func (s *BulkService) STIX(ctx context.Context, jobID uuid.UUID) (*stix.Bundle, error) {
+	lookups, err := s.Lookups(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	b := stix.NewBuilder(s.lookup.registry.Name)
+	for _, l := range lookups {
+		if err := b.Add(l); err != nil {
+			return nil, err
+		}
+	}
+	return b.Bundle("bulk:" + jobID.String()), nil
+}
+
+// Report writes the lookups matching q's filters, in q.Sort order, as a report in format; q's
+// cursor and limit are ignored. Lookups are read exportPageSize at a time, with their results and
+// jobs, and nothing is written to w before the query is validated, so errors are those of History
+// until w is written to.
+func (s *LookupService) Report(ctx context.Context, q *dto.LookupHistoryQueryDTO, w io.Writer, format string) error {
+	f, _, err := historyFilter(ctx, q)
+	if err != nil {
+		return err
+	}
+	f.Limit = exportPageSize
+	var codes []string
+	if q.Provider != "" {
+		codes = []string{q.Provider}
+	}
+	r, err := report.New(w, format, s.reportProviders(codes))
+	if err != nil {
+		return err
+	}
+	for {
+		list, err := s.reqRepo.List(f)
+		if err != nil {
+			return err
+		}
+		lookups, err := s.responses(list)
+		if err != nil {
+			return err
+		}
+		for _, l := range lookups {
+			if err := r.Add(l); err != nil {
+				return err
+			}
+		}
+		if len(list) < exportPageSize {
+			return r.Close()
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		last := list[len(list)-1]
+		f.After = &repository.LookupCursor{CreatedAt: last.CreatedAt, Score: last.Score, ID: last.ID}
+	}
+}
+
+// reportProviders returns the report columns of the providers codes, or of every configured
+// provider when codes is empty.
+func (s *LookupService) reportProviders(codes []string) []report.Provider {
+	if len(codes) == 0 {
+		for _, code := range s.registry.AllCodes() {
+			if providerapi.IsConfigured(s.registry.AdapterByCode(code)) {
+				codes = append(codes, code)
+			}
+		}
+	}
+	out := make([]report.Provider, 0, len(codes))
+	for _, code := range codes {
+		p := report.Provider{Code: code}
+		if a := s.registry.AdapterByCode(code); a != nil {
+			p.Fielder, _ = providerapi.Base(a).(report.Fielder)
+		}
+		out = append(out, p)
+	}
+	return out
+}
+
+// Report writes a job's items as a report in format, in input order; items without a lookup get
+// a row with their status and error. Columns are the job's providers, or all configured ones.
+func (s *BulkService) Report(ctx context.Context, jobID uuid.UUID, w io.Writer, format string) error {
+	job, err := s.visibleJob(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	r, err := report.New(w, format, s.lookup.reportProviders(job.Providers))
+	if err != nil {
+		return err
+	}
+	err = s.eachItem(ctx, job, func(it *model.BulkJobItem, l *vo.LookupResponseVO) error {
+		if l != nil {
+			return r.Add(l)
+		}
+		return r.AddFailed(it.IndicatorType, it.IndicatorValue, it.Status, it.Error)
+	})
+	if err != nil {
+		return err
+	}
+	return r.Close()
+}
@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"hermes/internal/logging"
+	"hermes/internal/model"
+	"hermes/internal/providerapi"
+	"hermes/internal/tracing"
+	"hermes/internal/usage"
+	"hermes/internal/verdict"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// jobDispatchInterval is how often provider_jobs is checked for due jobs.
+	jobDispatchInterval = 2 * time.Second
+	// jobLease hides a claimed job from other workers, also in other processes, while it is polled.
+	jobLease = 2 * time.Minute
+	// jobPollInterval is the delay between polls when the provider advises none.
+	jobPollInterval = 5 * time.Second
+	// jobBackoffMax caps the backoff after failed polls.
+	jobBackoffMax = 5 * time.Minute
+	// jobCallTimeout bounds one poll and collect.
+	jobCallTimeout = time.Minute
+)
+
+var jobLog = logging.For("jobs")
+
+// jobEvents hands results of finished provider jobs to the subscribers of their request.
+type jobEvents struct {
+	mu   sync.Mutex
+	subs map[uuid.UUID]map[chan vo.ProviderResultVO]struct{}
+}
+
+func newJobEvents() *jobEvents {
+	return &jobEvents{subs: make(map[uuid.UUID]map[chan vo.ProviderResultVO]struct{})}
+}
+
+func (e *jobEvents) subscribe(requestID uuid.UUID) (chan vo.ProviderResultVO, func()) {
+	ch := make(chan vo.ProviderResultVO, 16)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.subs[requestID] == nil {
+		e.subs[requestID] = make(map[chan vo.ProviderResultVO]struct{})
+	}
+	e.subs[requestID][ch] = struct{}{}
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		delete(e.subs[requestID], ch)
+		if len(e.subs[requestID]) == 0 {
+			delete(e.subs, requestID)
+		}
+	}
+}
+
+// publish never blocks: a subscriber that does not keep up misses the event and sees the
+// result on its next read of the lookup.
+func (e *jobEvents) publish(requestID uuid.UUID, r vo.ProviderResultVO) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for ch := range e.subs[requestID] {
+		select {
+		case ch <- r:
+		default:
+		}
+	}
+}
+
+// Subscribe returns a channel receiving the results of the lookup's provider jobs as they
+// finish, and a function to unsubscribe. It returns gorm.ErrRecordNotFound like Get.
+func (s *LookupService) Subscribe(ctx context.Context, requestID uuid.UUID) (<-chan vo.ProviderResultVO, func(), error) {
+	if _, err := s.visibleRequest(ctx, requestID); err != nil {
+		return nil, nil, err
+	}
+	ch, cancel := s.events.subscribe(requestID)
+	return ch, cancel, nil
+}
+
+// queueJob stores a job started by an async adapter for the workers to poll and returns the
+// pending result reported for it.
+func (s *LookupService) queueJob(req *model.LookupRequest, adapter providerapi.Adapter, job *providerapi.Job) vo.ProviderResultVO {
+	now := time.Now()
+	row := &model.ProviderJob{
+		LookupRequestID: req.ID,
+		RequestID:       req.RequestID,
+		ProviderCode:    adapter.Code(),
+		ExternalID:      job.ID,
+		Data:            model.JSONB(job.Data),
+		Status:          model.ProviderJobPending,
+		NextPollAt:      now.Add(jobPollInterval),
+	}
+	if s.cfg.PendingTimeoutSeconds > 0 {
+		expires := now.Add(time.Duration(s.cfg.PendingTimeoutSeconds) * time.Second)
+		row.ExpiresAt = &expires
+	}
+	if err := s.jobs.Create(row); err != nil {
+		return vo.ProviderResultVO{ProviderCode: adapter.Code(), Status: vo.StatusError, Error: "store job: " + err.Error()}
+	}
+	return resultVO(adapter.Code(), providerapi.PendingResult(adapter.Code(), job), nil)
+}
+
+// RunJobs polls pending provider jobs, including jobs left by a previous process, with
+// JobWorkers workers until ctx is cancelled; polls are charged to the lookup's client through
+// usageSvc (nil = not metered). Only as many jobs are claimed as there are idle workers. It
+// returns once the workers have stopped; jobs interrupted by the stop are polled again when
+// their lease expires.
+func (s *LookupService) RunJobs(ctx context.Context, usageSvc *UsageService) {
+	n := s.cfg.JobWorkers
+	if n <= 0 {
+		n = 1
+	}
+	busy := make(chan struct{}, n)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	t := time.NewTicker(jobDispatchInterval)
+	defer t.Stop()
+	for {
+		if idle := n - len(busy); idle > 0 {
+			for _, job := range s.claimJobs(idle) {
+				// Never blocks: only this loop fills busy, with at most idle jobs.
+				busy <- struct{}{}
+				wg.Add(1)
+				go func(job *model.ProviderJob) {
+					defer func() { <-busy; wg.Done() }()
+					s.runJob(ctx, job, usageSvc)
+				}(job)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// claimJobs claims up to limit due jobs.
+func (s *LookupService) claimJobs(limit int) []*model.ProviderJob {
+	due, err := s.jobs.Due(time.Now(), limit)
+	if err != nil {
+		jobLog.Error("list due jobs", "error", err)
+		return nil
+	}
+	var out []*model.ProviderJob
+	for i := range due {
+		ok, err := s.jobs.Claim(&due[i], time.Now().Add(jobLease))
+		if err != nil {
+			jobLog.Error("claim job", "job", due[i].ID, "error", err)
+		}
+		if ok {
+			out = append(out, &due[i])
+		}
+	}
+	return out
+}
+
+// runJob polls a job once. A finished job's result is stored with its lookup request, whose
+// aggregate verdict is refreshed; failed polls, including a failed refresh, are retried with
+// exponential backoff. Polls are charged to the lookup's client like bulk lookups, and a client
+// out of quota fails its jobs. A poll cut short by ctx is left to the lease.
+func (s *LookupService) runJob(ctx context.Context, row *model.ProviderJob, usageSvc *UsageService) {
+	adapter := s.registry.AdapterByCode(row.ProviderCode)
+	switch {
+	case adapter == nil || !providerapi.IsAsync(adapter):
+		s.finishJob(row, model.ProviderJobFailed, "provider no longer runs jobs", nil)
+		return
+	case row.ExpiresAt != nil && time.Now().After(*row.ExpiresAt):
+		s.finishJob(row, model.ProviderJobExpired, "timed out", nil)
+		return
+	}
+
+	req, err := s.reqRepo.GetByRequestID(row.RequestID)
+	if err != nil {
+		s.retryJob(ctx, row, fmt.Errorf("load lookup request: %w", err))
+		return
+	}
+	if usageSvc != nil && req.UserID != nil {
+		exhausted, err := usageSvc.QuotaExhausted(*req.UserID)
+		if err != nil {
+			s.retryJob(ctx, row, err)
+			return
+		}
+		if exhausted {
+			s.finishJob(row, model.ProviderJobFailed, ErrQuotaExceeded.Error(), nil)
+			return
+		}
+		meter := &usage.Meter{}
+		ctx = usage.WithMeter(ctx, meter)
+		defer func() {
+			if err := usageSvc.Record(*req.UserID, 0, meter.ProviderCalls()); err != nil {
+				jobLog.Error("record usage", "job", row.ID, "error", err)
+			}
+		}()
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, jobCallTimeout)
+	defer cancel()
+	callCtx, span := tracing.Tracer().Start(callCtx, "provider_job.run", trace.WithAttributes(
+		tracing.ProviderKey.String(row.ProviderCode), tracing.RequestIDKey.String(row.RequestID.String())))
+	defer span.End()
+	job := providerapi.Job{ID: row.ExternalID, Data: row.Data}
+	row.Polls++
+	st, err := providerapi.Poll(callCtx, adapter, job)
+	countProviderCall(callCtx, err)
+	if err == nil && st.Done {
+		var res providerapi.Result
+		res, err = providerapi.Collect(callCtx, adapter, job)
+		countProviderCall(callCtx, err)
+		if err == nil {
+			if !res.Success || res.Data == nil {
+				s.finishJob(row, model.ProviderJobFailed, res.Error, nil)
+				return
+			}
+			out := resultVO(adapter.Code(), res, nil)
+			out.Assessment = ToAssessmentVO(s.storeResult(callCtx, req, adapter, res))
+			if err = s.refreshVerdict(req); err == nil {
+				s.finishJob(row, model.ProviderJobDone, "", &out)
+				return
+			}
+			err = fmt.Errorf("refresh verdict: %w", err)
+		}
+	}
+
+	switch {
+	case err != nil:
+		s.retryJob(ctx, row, err)
+		return
+	case st.Failed != "":
+		s.finishJob(row, model.ProviderJobFailed, st.Failed, nil)
+		return
+	}
+	row.Failures = 0
+	row.Error = ""
+	if st.Data != nil {
+		row.Data = model.JSONB(st.Data)
+	}
+	wait := st.RetryAfter
+	if wait <= 0 {
+		wait = jobPollInterval
+	}
+	row.NextPollAt = time.Now().Add(wait)
+	if err := s.jobs.Reschedule(row); err != nil {
+		jobLog.ErrorContext(callCtx, "reschedule job", "job", row.ID, "error", err)
+	}
+}
+
+// retryJob schedules the job's next poll after a failure, with backoff. When ctx is done the
+// failure is the stop's doing and the job is left to its lease.
+func (s *LookupService) retryJob(ctx context.Context, row *model.ProviderJob, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	row.Failures++
+	row.Error = err.Error()
+	row.NextPollAt = time.Now().Add(jobBackoff(row.Failures))
+	jobLog.WarnContext(ctx, "job poll failed", "job", row.ID, "provider", row.ProviderCode, "failures", row.Failures, "error", err)
+	if err := s.jobs.Reschedule(row); err != nil {
+		jobLog.ErrorContext(ctx, "reschedule job", "job", row.ID, "error", err)
+	}
+}
+
+// countProviderCall counts a call that reached the provider, i.e. was not rate limited locally.
+func countProviderCall(ctx context.Context, err error) {
+	if !errors.Is(err, providerapi.ErrRateLimited) {
+		usage.CountProviderCall(ctx)
+	}
+}
+
+// jobBackoff is the delay after the nth consecutive failed poll.
+func jobBackoff(failures int) time.Duration {
+	d := jobPollInterval
+	for i := 1; i < failures && d < jobBackoffMax; i++ {
+		d *= 2
+	}
+	if d > jobBackoffMax {
+		d = jobBackoffMax
+	}
+	return d
+}
+
+// finishJob records a job's final status and tells the request's subscribers; out is the
+// stored result of a done job.
+func (s *LookupService) finishJob(row *model.ProviderJob, status, msg string, out *vo.ProviderResultVO) {
+	row.Status = status
+	row.Error = msg
+	if err := s.jobs.Finish(row); err != nil {
+		jobLog.Error("finish job", "job", row.ID, "error", err)
+	}
+	if status != model.ProviderJobDone {
+		jobLog.Warn("job "+status, "job", row.ID, "lookup_request_id", row.RequestID.String(), "provider", row.ProviderCode, "error", msg)
+		r := jobResultVO(row)
+		out = &r
+	}
+	s.events.publish(row.RequestID, *out)
+}
+
+// jobResultVO reports a job that has not stored a result: pending, or failed with its error.
+func jobResultVO(row *model.ProviderJob) vo.ProviderResultVO {
+	switch row.Status {
+	case model.ProviderJobPending:
+		return resultVO(row.ProviderCode, providerapi.PendingResult(row.ProviderCode, &providerapi.Job{ID: row.ExternalID, Data: row.Data}), nil)
+	case model.ProviderJobExpired:
+		return vo.ProviderResultVO{ProviderCode: row.ProviderCode, Status: vo.StatusTimedOut, Error: row.Error}
+	default:
+		return vo.ProviderResultVO{ProviderCode: row.ProviderCode, Status: vo.StatusError, Error: row.Error}
+	}
+}
+
+// refreshVerdict re-aggregates the request's stored results into its verdict columns and records
+// the STIX objects the new results add.
+func (s *LookupService) refreshVerdict(req *model.LookupRequest) error {
+	rows, err := s.reqRepo.GetResultsByRequestID(req.ID)
+	if err != nil {
+		return err
+	}
+	votes := make([]verdict.Vote, 0, len(rows))
+	for _, row := range rows {
+		votes = append(votes, verdict.Vote{ProviderCode: row.ProviderCode, Assessment: storedAssessment(row)})
+	}
+	summary := verdict.Aggregate(votes, s.cfg.ProviderWeights)
+	if err := s.reqRepo.UpdateVerdict(req.ID, string(summary.Verdict), summary.Score); err != nil {
+		return err
+	}
+	return s.indexObjects(req)
+}
@@ -2,27 +2,44 @@ package service
 
 import (
 	"context"
-	"sync"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
 
+	"hermes/internal/auth"
 	"hermes/internal/config"
+	"hermes/internal/cve"
 	"hermes/internal/dto"
+	"hermes/internal/indicator"
+	"hermes/internal/metrics"
+	"hermes/internal/misp"
 	"hermes/internal/model"
 	"hermes/internal/providerapi"
 	"hermes/internal/registry"
 	"hermes/internal/repository"
+	"hermes/internal/tracing"
+	"hermes/internal/usage"
+	"hermes/internal/verdict"
 	"hermes/internal/vo"
 
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	"gorm.io/gorm"
 )
 
 // LookupService performs unified lookups and persists results.
 type LookupService struct {
-	cfg      *config.Config
-	registry *registry.Registry
-	reqRepo  *repository.LookupRequestRepository
+	cfg       *config.Config
+	registry  *registry.Registry
+	reqRepo   *repository.LookupRequestRepository
 	auditRepo *repository.AuditLogRepository
-	db       *gorm.DB
+	db        *gorm.DB
+	jobs      *repository.ProviderJobRepository
+	events    *jobEvents
+	misp      *misp.Client
 }
 
 // NewLookupService creates a new lookup service.
@@ -33,12 +50,63 @@ func NewLookupService(cfg *config.Config, reg *registry.Registry, db *gorm.DB) *
 		reqRepo:   repository.NewLookupRequestRepository(db),
 		auditRepo: repository.NewAuditLogRepository(db),
 		db:        db,
+		jobs:      repository.NewProviderJobRepository(db),
+		events:    newJobEvents(),
+		misp:      misp.NewClient(cfg.MISPURL, cfg.MISPAPIKey),
 	}
 }
 
-// Lookup runs a unified lookup: creates request, calls adapters in parallel, stores results, returns VO.
+// LookupHooks receive a lookup's progress as it happens (see LookupStream); nil hooks are skipped.
+// They are called from one goroutine at a time.
+type LookupHooks struct {
+	// Started is called once the request is recorded, before any provider result.
+	Started func(*vo.LookupStartVO)
+	// Result is called with each provider's result as soon as it is known: served from cache,
+	// answered, or timed out at the deadline.
+	Result func(vo.ProviderResultVO)
+}
+
+func (h LookupHooks) started(v *vo.LookupStartVO) {
+	if h.Started != nil {
+		h.Started(v)
+	}
+}
+
+func (h LookupHooks) result(r vo.ProviderResultVO) {
+	if h.Result != nil {
+		h.Result(r)
+	}
+}
+
+// Lookup runs a unified lookup. It canonicalizes the indicator, returning an *indicator.Error
+// when it is invalid, records the request and serves unexpired cached results. The remaining
+// adapters are called in parallel until the lookup deadline, and their results are stored.
 func (s *LookupService) Lookup(ctx context.Context, d *dto.LookupRequestDTO) (*vo.LookupResponseVO, error) {
+	return s.LookupStream(ctx, d, LookupHooks{})
+}
+
+// LookupStream is Lookup reporting each provider result to hooks as it arrives. When ctx is
+// cancelled (the client went away) the remaining providers are cancelled and reported timed_out.
+func (s *LookupService) LookupStream(ctx context.Context, d *dto.LookupRequestDTO, hooks LookupHooks) (*vo.LookupResponseVO, error) {
+	ctx, span := tracing.Tracer().Start(ctx, "LookupService.Lookup", trace.WithAttributes(tracing.IndicatorTypeKey.String(d.IndicatorType)))
+	defer span.End()
+	ind, err := indicator.Canonicalize(d.IndicatorType, d.IndicatorValue, indicator.Options{AllowPrivate: s.cfg.AllowPrivateIndicators})
+	if err != nil {
+		span.SetStatus(codes.Error, "invalid indicator")
+		return nil, err
+	}
+	value := ind.Value
+
 	adapters := s.registry.AdaptersForType(d.IndicatorType)
+	if ind.Subtype != "" {
+		accepting := make([]providerapi.Adapter, 0, len(adapters))
+		for _, a := range adapters {
+			if providerapi.AcceptsHash(a, ind.Subtype) {
+				accepting = append(accepting, a)
+			}
+		}
+		adapters = accepting
+	}
 	if len(d.Providers) > 0 {
 		filtered := make([]providerapi.Adapter, 0)
 		allowed := make(map[string]bool)
@@ -56,52 +124,317 @@ func (s *LookupService) Lookup(ctx context.Context, d *dto.LookupRequestDTO) (*v
 	req := &model.LookupRequest{
 		RequestID:      uuid.New(),
 		IndicatorType:  d.IndicatorType,
-		IndicatorValue: d.IndicatorValue,
+		IndicatorValue: value,
+		IndicatorHash:  indicatorHash(value),
+		UserID:         auth.UserID(ctx),
 	}
-	if err := s.reqRepo.Create(req); err != nil {
+	if err := s.requests(ctx).Create(req); err != nil {
+		span.SetStatus(codes.Error, "store request")
 		return nil, err
 	}
+	span.SetAttributes(tracing.RequestIDKey.String(req.RequestID.String()), attribute.Int("hermes.providers", len(adapters)))
+
+	cacheMode := d.Cache
+	if cacheMode == "" {
+		cacheMode = dto.CachePrefer
+	}
+
+	start := &vo.LookupStartVO{
+		RequestID:      req.RequestID.String(),
+		IndicatorType:  d.IndicatorType,
+		IndicatorValue: value,
+		HashType:       ind.Subtype,
+		Providers:      make([]string, 0, len(adapters)),
+	}
+	for _, a := range adapters {
+		start.Providers = append(start.Providers, a.Code())
+	}
+	hooks.started(start)
 
 	results := make(map[string]vo.ProviderResultVO)
-	var mu sync.Mutex
-	var wg sync.WaitGroup
+	live := make([]providerapi.Adapter, 0, len(adapters))
 	for _, a := range adapters {
-		wg.Add(1)
-		go func(adapter providerapi.Adapter) {
-			defer wg.Done()
-			res, err := adapter.Lookup(ctx, d.IndicatorType, d.IndicatorValue)
-			mu.Lock()
-			results[adapter.Code()] = vo.ProviderResultVO{
-				ProviderCode: res.ProviderCode,
-				Success:      res.Success,
-				Data:         res.Data,
-				Error:        res.Error,
-			}
-			mu.Unlock()
-			if err == nil && res.Success && res.Data != nil {
-				_ = s.reqRepo.CreateResult(&model.LookupResult{
-					LookupRequestID: req.ID,
-					ProviderCode:    res.ProviderCode,
-					RawResponse:     model.JSONB(res.Data),
-					TTLSeconds:      s.cfg.CacheTTLSeconds,
-				})
+		if cacheMode != dto.CacheBypass {
+			if cached := s.cachedResult(ctx, req, a); cached != nil {
+				results[a.Code()] = *cached
+				hooks.result(*cached)
+				continue
 			}
-		}(a)
+		}
+		if cacheMode == dto.CacheOnly {
+			results[a.Code()] = vo.ProviderResultVO{ProviderCode: a.Code(), Success: false, Status: vo.StatusError, Error: "not cached"}
+			hooks.result(results[a.Code()])
+			continue
+		}
+		live = append(live, a)
+	}
+
+	s.fanOut(ctx, req, live, results, hooks.result)
+	partial := false
+	votes := make([]verdict.Vote, 0, len(results))
+	for code, r := range results {
+		partial = partial || r.Status == vo.StatusTimedOut || r.Status == vo.StatusPending
+		if r.Assessment != nil {
+			votes = append(votes, verdict.Vote{ProviderCode: code, Assessment: providerapi.Assessment{
+				Verdict: providerapi.Verdict(r.Assessment.Verdict),
+				Score:   r.Assessment.Score,
+			}})
+		}
+	}
+	summary := verdict.Aggregate(votes, s.cfg.ProviderWeights)
+	span.SetAttributes(attribute.Bool("hermes.partial", partial))
+	_ = s.requests(ctx).UpdateVerdict(req.ID, string(summary.Verdict), summary.Score)
+	if err := s.indexObjects(req); err != nil {
+		taxiiLog.WarnContext(ctx, "index lookup objects", "request_id", req.RequestID, "error", err)
 	}
-	wg.Wait()
 
 	// Optional: audit log (no PII)
-	_ = s.auditRepo.Create(&model.AuditLog{
+	_ = s.auditRepo.WithContext(context.WithoutCancel(ctx)).Create(&model.AuditLog{
 		RequestID:    &req.RequestID,
 		Action:       "lookup",
 		ResourceType: "lookup_request",
 		ResourceID:   req.RequestID.String(),
 	})
 
-	return &vo.LookupResponseVO{
+	resp := &vo.LookupResponseVO{
 		RequestID:      req.RequestID.String(),
 		IndicatorType:  d.IndicatorType,
-		IndicatorValue: d.IndicatorValue,
+		IndicatorValue: value,
+		HashType:       ind.Subtype,
 		Results:        results,
-	}, nil
+		Partial:        partial,
+		Verdict:        aggregateVO(summary),
+		CreatedAt:      req.CreatedAt,
+		UpdatedAt:      time.Now(),
+	}
+	if d.IndicatorType == "cve" {
+		resp.CVE = mergedCVE(adapters, results)
+	}
+	return resp, nil
+}
+
+// mergedCVE merges the CVE records parsed from successful results of adapters implementing
+// cve.Parser; it returns nil when none describes the CVE.
+func mergedCVE(adapters []providerapi.Adapter, results map[string]vo.ProviderResultVO) *vo.CVEVO {
+	var records []cve.Record
+	for _, a := range adapters {
+		p, ok := providerapi.Base(a).(cve.Parser)
+		r, found := results[a.Code()]
+		if !ok || !found || !r.Success {
+			continue
+		}
+		data, _ := r.Data.(map[string]interface{})
+		if rec, ok := p.ParseCVE(data); ok {
+			records = append(records, rec)
+		}
+	}
+	if len(records) == 0 {
+		return nil
+	}
+	m := cve.Merge(records...)
+	out := &vo.CVEVO{
+		ID:          m.ID,
+		Description: m.Description,
+		Published:   m.Published,
+		Modified:    m.Modified,
+		CVSS:        make([]vo.CVSSVO, 0, len(m.CVSS)),
+		CWEs:        m.CWEs,
+		References:  m.References,
+		CPEs:        m.CPEs,
+		Sources:     m.Sources,
+	}
+	for _, c := range m.CVSS {
+		out.CVSS = append(out.CVSS, vo.CVSSVO{Version: c.Version, Vector: c.Vector, BaseScore: c.BaseScore, Severity: c.Severity, Source: c.Source})
+	}
+	return out
+}
+
+// fanOut calls adapters in parallel and collects their results, passing each to emit as it
+// arrives, until all have answered or the lookup deadline passes (or ctx ends); adapters still
+// running then are reported as timed_out and cancelled.
+// Async adapters are submitted instead of looked up; their jobs are queued for the job workers
+// and reported as pending (see RunJobs).
+func (s *LookupService) fanOut(ctx context.Context, req *model.LookupRequest, adapters []providerapi.Adapter, results map[string]vo.ProviderResultVO, emit func(vo.ProviderResultVO)) {
+	if len(adapters) == 0 {
+		return
+	}
+	cancel := context.CancelFunc(func() {})
+	if s.cfg.LookupDeadlineSeconds > 0 {
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.cfg.LookupDeadlineSeconds)*time.Second)
+	}
+	defer cancel()
+
+	done := make(chan vo.ProviderResultVO, len(adapters))
+	for _, a := range adapters {
+		go func(adapter providerapi.Adapter) {
+			var (
+				job *providerapi.Job
+				res providerapi.Result
+				err error
+			)
+			if providerapi.IsAsync(adapter) {
+				job, res, err = providerapi.Submit(ctx, adapter, req.IndicatorType, req.IndicatorValue)
+			} else {
+				res, err = adapter.Lookup(ctx, req.IndicatorType, req.IndicatorValue)
+			}
+			if providerapi.Contacted(adapter, req.IndicatorType, err) {
+				usage.CountProviderCall(ctx)
+			}
+			out := resultVO(adapter.Code(), res, err)
+			switch {
+			case err == nil && job != nil:
+				out = s.queueJob(req, adapter, job)
+			case err == nil && res.Success && res.Data != nil:
+				out.Assessment = ToAssessmentVO(s.storeResult(ctx, req, adapter, res))
+			}
+			done <- out
+		}(a)
+	}
+
+	for pending := len(adapters); pending > 0; pending-- {
+		select {
+		case r := <-done:
+			results[r.ProviderCode] = r
+			emit(r)
+		case <-ctx.Done():
+			// Keep answers that arrived together with the deadline.
+			for drained := false; !drained; {
+				select {
+				case r := <-done:
+					results[r.ProviderCode] = r
+					emit(r)
+				default:
+					drained = true
+				}
+			}
+			for _, a := range adapters {
+				if _, ok := results[a.Code()]; !ok {
+					results[a.Code()] = vo.ProviderResultVO{ProviderCode: a.Code(), Status: vo.StatusTimedOut, Error: "timed out"}
+					emit(results[a.Code()])
+				}
+			}
+			return
+		}
+	}
+}
+
+// requests returns the request repository bound to ctx's trace but not its cancellation, so
+// results arriving as the lookup deadline passes are still stored.
+func (s *LookupService) requests(ctx context.Context) *repository.LookupRequestRepository {
+	return s.reqRepo.WithContext(context.WithoutCancel(ctx))
+}
+
+// storeResult assesses a successful result and stores it with the request.
+func (s *LookupService) storeResult(ctx context.Context, req *model.LookupRequest, adapter providerapi.Adapter, res providerapi.Result) providerapi.Assessment {
+	assessment := adapter.Assess(res.Data)
+	_ = s.requests(ctx).CreateResult(&model.LookupResult{
+		LookupRequestID: req.ID,
+		ProviderCode:    adapter.Code(),
+		RawResponse:     model.JSONB(res.Data),
+		TTLSeconds:      s.cfg.CacheTTLSeconds,
+		Verdict:         string(assessment.Verdict),
+		Score:           assessment.Score,
+		Tags:            assessment.Tags,
+		FirstSeen:       assessment.FirstSeen,
+		LastSeen:        assessment.LastSeen,
+	})
+	return assessment
+}
+
+// resultVO maps an adapter result to its VO, classifying the status.
+func resultVO(code string, res providerapi.Result, err error) vo.ProviderResultVO {
+	out := vo.ProviderResultVO{
+		ProviderCode: code,
+		Success:      res.Success,
+		Status:       vo.StatusOK,
+		Data:         res.Data,
+		Error:        res.Error,
+	}
+	switch {
+	case err == nil && res.Pending:
+		out.Status = vo.StatusPending
+	case errors.Is(err, providerapi.ErrRateLimited):
+		out.Status = vo.StatusRateLimited
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+		out.Status = vo.StatusTimedOut
+	case err != nil || !res.Success:
+		out.Status = vo.StatusError
+	}
+	return out
+}
+
+// ToAssessmentVO maps an adapter assessment to its VO.
+func ToAssessmentVO(a providerapi.Assessment) *vo.AssessmentVO {
+	return &vo.AssessmentVO{
+		Verdict:   string(a.Verdict),
+		Score:     a.Score,
+		Tags:      a.Tags,
+		FirstSeen: a.FirstSeen,
+		LastSeen:  a.LastSeen,
+	}
+}
+
+func aggregateVO(s verdict.Summary) *vo.AggregateVerdictVO {
+	out := &vo.AggregateVerdictVO{
+		Verdict:      string(s.Verdict),
+		Score:        s.Score,
+		Explanation:  s.Explanation,
+		Contributors: make([]vo.VerdictContributorVO, 0, len(s.Contributors)),
+	}
+	for _, c := range s.Contributors {
+		out.Contributors = append(out.Contributors, vo.VerdictContributorVO{
+			ProviderCode: c.ProviderCode,
+			Verdict:      string(c.Verdict),
+			Score:        c.Score,
+			Weight:       c.Weight,
+			Drove:        c.Drove,
+		})
+	}
+	return out
+}
+
+// cachedResult returns the newest unexpired result for the request's indicator and provider, or nil.
+// A hit is also recorded against req (keeping the original cached_at) so the request's history is complete.
+// The raw response is re-assessed so cached rows pick up the adapter's current normalization.
+func (s *LookupService) cachedResult(ctx context.Context, req *model.LookupRequest, adapter providerapi.Adapter) *vo.ProviderResultVO {
+	repo := s.requests(ctx)
+	row, err := repo.FindLatestResult(req.IndicatorType, req.IndicatorHash, adapter.Code())
+	if err != nil || row == nil {
+		metrics.ObserveCache(adapter.Code(), false)
+		return nil
+	}
+	age := time.Since(row.CachedAt)
+	if age >= time.Duration(row.TTLSeconds)*time.Second {
+		metrics.ObserveCache(adapter.Code(), false)
+		return nil
+	}
+	metrics.ObserveCache(adapter.Code(), true)
+	assessment := adapter.Assess(row.RawResponse)
+	_ = repo.CreateResult(&model.LookupResult{
+		LookupRequestID: req.ID,
+		ProviderCode:    row.ProviderCode,
+		RawResponse:     row.RawResponse,
+		CachedAt:        row.CachedAt,
+		TTLSeconds:      row.TTLSeconds,
+		Verdict:         string(assessment.Verdict),
+		Score:           assessment.Score,
+		Tags:            assessment.Tags,
+		FirstSeen:       assessment.FirstSeen,
+		LastSeen:        assessment.LastSeen,
+	})
+	return &vo.ProviderResultVO{
+		ProviderCode:    row.ProviderCode,
+		Success:         true,
+		Status:          vo.StatusOK,
+		Assessment:      ToAssessmentVO(assessment),
+		Data:            map[string]interface{}(row.RawResponse),
+		Cached:          true,
+		CacheAgeSeconds: int64(age.Seconds()),
+	}
+}
+
+// indicatorHash is the cache key stored in lookup_requests.indicator_hash.
+func indicatorHash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hermes/internal/auth"
+	"hermes/internal/dto"
+	"hermes/internal/indicator"
+	"hermes/internal/model"
+	"hermes/internal/providerapi"
+	"hermes/internal/repository"
+	"hermes/internal/verdict"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned by History for a cursor it did not issue for the same sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+const defaultHistoryLimit = 50
+
+// Get reconstructs the response of a past lookup from lookup_requests, lookup_results and
+// provider_jobs. Only successful provider results are stored, so failed or timed-out providers
+// are absent unless they ran as jobs; jobs still running are reported as pending. The verdict
+// and score are the stored ones, as listed by History. It returns gorm.ErrRecordNotFound for
+// unknown request IDs and for other clients' lookups.
+func (s *LookupService) Get(ctx context.Context, requestID uuid.UUID) (*vo.LookupResponseVO, error) {
+	req, err := s.visibleRequest(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return s.response(req)
+}
+
+// response reconstructs the response of a stored lookup request; see Get.
+func (s *LookupService) response(req *model.LookupRequest) (*vo.LookupResponseVO, error) {
+	out, err := s.responses([]model.LookupRequest{*req})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// responses reconstructs the responses of stored lookup requests, in their order, reading the
+// results and jobs of all of them with one query each.
+func (s *LookupService) responses(reqs []model.LookupRequest) ([]*vo.LookupResponseVO, error) {
+	ids := make([]int64, 0, len(reqs))
+	for i := range reqs {
+		ids = append(ids, reqs[i].ID)
+	}
+	rows, err := s.reqRepo.GetResultsByRequestIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := s.jobs.ByLookupRequestIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]*vo.LookupResponseVO, 0, len(reqs))
+	for i := range reqs {
+		out = append(out, s.buildResponse(&reqs[i], rows[reqs[i].ID], jobs[reqs[i].ID]))
+	}
+	return out, nil
+}
+
+// buildResponse reconstructs the response of req from its stored results and jobs.
+func (s *LookupService) buildResponse(req *model.LookupRequest, rows []model.LookupResult, jobs []model.ProviderJob) *vo.LookupResponseVO {
+	results := make(map[string]vo.ProviderResultVO, len(rows))
+	adapters := make([]providerapi.Adapter, 0, len(rows))
+	votes := make([]verdict.Vote, 0, len(rows))
+	for _, row := range rows {
+		assessment := storedAssessment(row)
+		// Rows cached before the request were served from cache.
+		cached := row.CachedAt.Before(req.CreatedAt.Add(-time.Second))
+		r := vo.ProviderResultVO{
+			ProviderCode: row.ProviderCode,
+			Success:      true,
+			Status:       vo.StatusOK,
+			Assessment:   ToAssessmentVO(assessment),
+			Data:         map[string]interface{}(row.RawResponse),
+			Cached:       cached,
+		}
+		if cached {
+			r.CacheAgeSeconds = int64(req.CreatedAt.Sub(row.CachedAt).Seconds())
+		}
+		results[row.ProviderCode] = r
+		votes = append(votes, verdict.Vote{ProviderCode: row.ProviderCode, Assessment: assessment})
+		if a := s.registry.AdapterByCode(row.ProviderCode); a != nil {
+			adapters = append(adapters, a)
+		}
+	}
+
+	partial := false
+	for i := range jobs {
+		if _, done := results[jobs[i].ProviderCode]; !done {
+			results[jobs[i].ProviderCode] = jobResultVO(&jobs[i])
+			partial = partial || jobs[i].Status == model.ProviderJobPending
+		}
+	}
+
+	out := &vo.LookupResponseVO{
+		RequestID:      req.RequestID.String(),
+		IndicatorType:  req.IndicatorType,
+		IndicatorValue: req.IndicatorValue,
+		Results:        results,
+		Partial:        partial,
+		Verdict:        aggregateVO(storedSummary(req, verdict.Aggregate(votes, s.cfg.ProviderWeights))),
+		CreatedAt:      req.CreatedAt,
+		UpdatedAt:      req.UpdatedAt,
+	}
+	if out.UpdatedAt.Before(out.CreatedAt) {
+		out.UpdatedAt = out.CreatedAt
+	}
+	if req.IndicatorType == indicator.TypeHash {
+		if ind, err := indicator.Canonicalize(req.IndicatorType, req.IndicatorValue, indicator.Options{AllowPrivate: true}); err == nil {
+			out.HashType = ind.Subtype
+		}
+	}
+	if req.IndicatorType == indicator.TypeCVE {
+		out.CVE = mergedCVE(adapters, results)
+	}
+	return out
+}
+
+// visibleRequest loads a lookup request the caller may see; see Get.
+func (s *LookupService) visibleRequest(ctx context.Context, requestID uuid.UUID) (*model.LookupRequest, error) {
+	req, err := s.reqRepo.GetByRequestID(requestID)
+	if err != nil {
+		return nil, err
+	}
+	if !visibleTo(ctx, req.UserID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return req, nil
+}
+
+// storedSummary returns sum, aggregated from the stored results with the current provider
+// weights, with req's stored verdict and score, so a lookup reads the same everywhere after the
+// weights change. Requests stored without a verdict keep sum.
+func storedSummary(req *model.LookupRequest, sum verdict.Summary) verdict.Summary {
+	if req.Verdict == "" || (string(sum.Verdict) == req.Verdict && sum.Score == req.Score) {
+		return sum
+	}
+	sum.Explanation = fmt.Sprintf("%s (score %d) as recorded; with the current provider weights: %s",
+		req.Verdict, req.Score, sum.Explanation)
+	sum.Verdict = providerapi.Verdict(req.Verdict)
+	sum.Score = req.Score
+	return sum
+}
+
+// storedAssessment reads the assessment columns of a stored result.
+func storedAssessment(row model.LookupResult) providerapi.Assessment {
+	if row.Verdict == "" {
+		return providerapi.Unknown()
+	}
+	return providerapi.Assessment{
+		Verdict:   providerapi.Verdict(row.Verdict),
+		Score:     row.Score,
+		Tags:      row.Tags,
+		FirstSeen: row.FirstSeen,
+		LastSeen:  row.LastSeen,
+	}
+}
+
+// historyCursor is the opaque cursor handed out by History.
+type historyCursor struct {
+	Sort      string    `json:"s"`
+	CreatedAt time.Time `json:"c"`
+	Score     int       `json:"v"`
+	ID        int64     `json:"i"`
+}
+
+// History lists past lookups matching q, newest first unless q.Sort says otherwise. Non-admin
+// callers only see their own lookups.
+func (s *LookupService) History(ctx context.Context, q *dto.LookupHistoryQueryDTO) (*vo.LookupHistoryVO, error) {
+	f, sort, err := historyFilter(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultHistoryLimit
+	}
+	f.Limit = limit + 1
+	if q.Cursor != "" {
+		c, err := decodeCursor(q.Cursor)
+		if err != nil || c.Sort != sort {
+			return nil, ErrInvalidCursor
+		}
+		f.After = &repository.LookupCursor{CreatedAt: c.CreatedAt, Score: c.Score, ID: c.ID}
+	}
+
+	list, err := s.reqRepo.List(f)
+	if err != nil {
+		return nil, err
+	}
+	out := &vo.LookupHistoryVO{Items: make([]vo.LookupSummaryVO, 0, len(list))}
+	if len(list) > limit {
+		list = list[:limit]
+		last := list[limit-1]
+		out.NextCursor = encodeCursor(historyCursor{Sort: sort, CreatedAt: last.CreatedAt, Score: last.Score, ID: last.ID})
+	}
+
+	ids := make([]int64, 0, len(list))
+	for _, r := range list {
+		ids = append(ids, r.ID)
+	}
+	providers, err := s.reqRepo.ProviderCodes(ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range list {
+		codes := providers[r.ID]
+		if codes == nil {
+			codes = []string{}
+		}
+		out.Items = append(out.Items, vo.LookupSummaryVO{
+			RequestID:      r.RequestID.String(),
+			IndicatorType:  r.IndicatorType,
+			IndicatorValue: r.IndicatorValue,
+			Verdict:        r.Verdict,
+			Score:          r.Score,
+			Providers:      codes,
+			CreatedAt:      r.CreatedAt,
+		})
+	}
+	return out, nil
+}
+
+// historyFilter selects the lookups matching q's filters and the caller may see, in the order
+// of q.Sort (also returned, defaulted). Limit and After are left to the caller.
+func historyFilter(ctx context.Context, q *dto.LookupHistoryQueryDTO) (repository.LookupFilter, string, error) {
+	sort := q.Sort
+	if sort == "" {
+		sort = "-created_at"
+	}
+	f := repository.LookupFilter{
+		IndicatorType: q.IndicatorType,
+		ProviderCode:  q.Provider,
+		Verdict:       q.Verdict,
+		Since:         q.Since,
+		Until:         q.Until,
+		SortByScore:   strings.TrimPrefix(sort, "-") == "score",
+		Desc:          strings.HasPrefix(sort, "-"),
+	}
+	if p := auth.FromContext(ctx); p != nil && !p.IsAdmin() {
+		name := p.ClientName
+		f.UserID = &name
+	}
+	if q.IndicatorValue != "" {
+		value := q.IndicatorValue
+		if q.IndicatorType != "" {
+			ind, err := indicator.Canonicalize(q.IndicatorType, value, indicator.Options{AllowPrivate: true})
+			if err != nil {
+				return f, sort, err
+			}
+			value = ind.Value
+		}
+		f.IndicatorHash = indicatorHash(value)
+	}
+	return f, sort, nil
+}
+
+func encodeCursor(c historyCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (historyCursor, error) {
+	var c historyCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("decode cursor: %w", err)
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
+
+// visibleTo reports whether a record owned by userID may be read by ctx's principal: admins read
+// everything, other clients only their own records. Without a principal (AUTH_REQUIRED=false)
+// everything is visible.
+func visibleTo(ctx context.Context, userID *string) bool {
+	p := auth.FromContext(ctx)
+	if p == nil || p.IsAdmin() {
+		return true
+	}
+	return userID != nil && *userID == p.ClientName
+}
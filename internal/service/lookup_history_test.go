@@ -0,0 +1,213 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"hermes/internal/auth"
+	"hermes/internal/config"
+	"hermes/internal/dto"
+	"hermes/internal/model"
+	"hermes/internal/providerapi"
+	"hermes/internal/registry"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestLookupService_GetAndHistory(t *testing.T) {
+	judge := &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "judge" },
+		SupportedTypesFunc: func() []string { return []string{"domain"} },
+		LookupFunc: func(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+			return providerapi.Result{ProviderCode: "judge", Success: true, Data: map[string]interface{}{"value": value}}, nil
+		},
+		AssessFunc: func(data map[string]interface{}) providerapi.Assessment {
+			if data["value"] == "bad.example" {
+				return providerapi.Assess(providerapi.VerdictMalicious, 90)
+			}
+			return providerapi.Assess(providerapi.VerdictClean, 0)
+		},
+	}
+	svc := NewLookupService(&config.Config{CacheTTLSeconds: 3600}, registry.New(judge), setupTestDB(t))
+	ctx := context.Background()
+
+	var ids []string
+	for _, d := range []string{"a.example", "bad.example", "c.example", "d.example"} {
+		res, err := svc.Lookup(ctx, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: d})
+		assert.NoError(t, err)
+		ids = append(ids, res.RequestID)
+	}
+
+	got, err := svc.Get(ctx, uuid.MustParse(ids[1]))
+	assert.NoError(t, err)
+	assert.Equal(t, "bad.example", got.IndicatorValue)
+	assert.Equal(t, "malicious", got.Results["judge"].Assessment.Verdict)
+	assert.False(t, got.Results["judge"].Cached)
+	assert.Equal(t, "malicious", got.Verdict.Verdict)
+
+	// The stored verdict stands after the weights change, as in History.
+	svc.cfg.ProviderWeights = map[string]float64{"judge": 0}
+	got, err = svc.Get(ctx, uuid.MustParse(ids[1]))
+	assert.NoError(t, err)
+	assert.Equal(t, "malicious", got.Verdict.Verdict)
+	assert.Equal(t, 90, got.Verdict.Score)
+	svc.cfg.ProviderWeights = nil
+
+	_, err = svc.Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+	// Newest first, two per page.
+	page, err := svc.History(ctx, &dto.LookupHistoryQueryDTO{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ids[3], ids[2]}, requestIDs(page.Items))
+	assert.NotEmpty(t, page.NextCursor)
+	assert.Equal(t, []string{"judge"}, page.Items[0].Providers)
+
+	page, err = svc.History(ctx, &dto.LookupHistoryQueryDTO{Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ids[1], ids[0]}, requestIDs(page.Items))
+	assert.Empty(t, page.NextCursor)
+
+	page, err = svc.History(ctx, &dto.LookupHistoryQueryDTO{Sort: "-score", Limit: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ids[1]}, requestIDs(page.Items))
+	_, err = svc.History(ctx, &dto.LookupHistoryQueryDTO{Sort: "created_at", Cursor: page.NextCursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	page, err = svc.History(ctx, &dto.LookupHistoryQueryDTO{IndicatorType: "domain", IndicatorValue: "C.Example."})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ids[2]}, requestIDs(page.Items))
+
+	page, err = svc.History(ctx, &dto.LookupHistoryQueryDTO{Verdict: "malicious", Provider: "judge"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{ids[1]}, requestIDs(page.Items))
+
+	page, err = svc.History(ctx, &dto.LookupHistoryQueryDTO{Provider: "other"})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Items)
+}
+
+func requestIDs(items []vo.LookupSummaryVO) []string {
+	out := make([]string, 0, len(items))
+	for _, it := range items {
+		out = append(out, it.RequestID)
+	}
+	return out
+}
+
+func TestLookupService_HistoryScopedToClient(t *testing.T) {
+	var calls int32
+	svc := NewLookupService(&config.Config{CacheTTLSeconds: 3600}, registry.New(countingAdapter(&calls)), setupTestDB(t))
+	alice := auth.WithPrincipal(context.Background(), &auth.Principal{ClientName: "alice", Scopes: []string{auth.ScopeLookupRead}})
+	bob := auth.WithPrincipal(context.Background(), &auth.Principal{ClientName: "bob", Scopes: []string{auth.ScopeLookupRead}})
+	admin := auth.WithPrincipal(context.Background(), &auth.Principal{ClientName: "ops", Scopes: []string{auth.ScopeAdmin}})
+
+	res, err := svc.Lookup(alice, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "example.com"})
+	assert.NoError(t, err)
+	_, err = svc.Lookup(bob, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "example.org"})
+	assert.NoError(t, err)
+
+	page, err := svc.History(alice, &dto.LookupHistoryQueryDTO{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	page, err = svc.History(admin, &dto.LookupHistoryQueryDTO{})
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+
+	_, err = svc.Get(bob, uuid.MustParse(res.RequestID))
+	assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	_, err = svc.Get(alice, uuid.MustParse(res.RequestID))
+	assert.NoError(t, err)
+}
+
+// asyncAdapter is a MockAdapter that also implements providerapi.AsyncAdapter.
+type asyncAdapter struct {
+	*providerapi.MockAdapter
+	done atomic.Bool
+}
+
+func (a *asyncAdapter) Submit(ctx context.Context, indicatorType string, value string) (*providerapi.Job, providerapi.Result, error) {
+	return &providerapi.Job{ID: "scan-1", Data: map[string]interface{}{"phase": "queued"}}, providerapi.Result{}, nil
+}
+
+func (a *asyncAdapter) Poll(ctx context.Context, job providerapi.Job) (providerapi.JobStatus, error) {
+	if !a.done.Load() {
+		return providerapi.JobStatus{Data: map[string]interface{}{"phase": "running"}, RetryAfter: time.Millisecond}, nil
+	}
+	return providerapi.JobStatus{Done: true}, nil
+}
+
+func (a *asyncAdapter) Collect(ctx context.Context, job providerapi.Job) (providerapi.Result, error) {
+	return providerapi.Result{ProviderCode: "scanner", Success: true, Data: map[string]interface{}{"uuid": job.ID, "done": true}}, nil
+}
+
+func TestLookupService_AsyncJobFinishes(t *testing.T) {
+	scanner := &asyncAdapter{MockAdapter: &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "scanner" },
+		SupportedTypesFunc: func() []string { return []string{"url"} },
+		AssessFunc: func(data map[string]interface{}) providerapi.Assessment {
+			return providerapi.Assess(providerapi.VerdictMalicious, 80)
+		},
+	}}
+	db := setupTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&model.Client{}, &model.ClientUsage{}))
+	assert.NoError(t, db.Create(&model.Client{Name: "soc"}).Error)
+	cfg := &config.Config{CacheTTLSeconds: 3600}
+	svc := NewLookupService(cfg, registry.New(providerapi.WithPolicy(scanner, providerapi.Policy{})), db)
+	usageSvc := NewUsageService(cfg, db)
+	ctx := auth.WithPrincipal(context.Background(), &auth.Principal{ClientName: "soc"})
+
+	res, err := svc.Lookup(ctx, &dto.LookupRequestDTO{IndicatorType: "url", IndicatorValue: "https://example.com/"})
+	assert.NoError(t, err)
+	assert.True(t, res.Partial)
+	assert.Equal(t, vo.StatusPending, res.Results["scanner"].Status)
+
+	id := uuid.MustParse(res.RequestID)
+	events, unsubscribe, err := svc.Subscribe(ctx, id)
+	assert.NoError(t, err)
+	defer unsubscribe()
+
+	// Workers are not started: drive the job by hand, as a worker would after a restart.
+	poll := func() {
+		due, err := svc.jobs.Due(time.Now().Add(time.Hour), 10)
+		assert.NoError(t, err)
+		assert.Len(t, due, 1)
+		svc.runJob(context.Background(), &due[0], usageSvc)
+	}
+	poll()
+	got, err := svc.Get(ctx, id)
+	assert.NoError(t, err)
+	assert.True(t, got.Partial)
+	assert.Equal(t, vo.StatusPending, got.Results["scanner"].Status)
+	assert.Equal(t, "running", got.Results["scanner"].Data.(map[string]interface{})["phase"])
+
+	scanner.done.Store(true)
+	poll()
+	r := <-events
+	assert.Equal(t, vo.StatusOK, r.Status)
+	got, err = svc.Get(ctx, id)
+	assert.NoError(t, err)
+	assert.False(t, got.Partial)
+	assert.Equal(t, vo.StatusOK, got.Results["scanner"].Status)
+	assert.Equal(t, "malicious", got.Verdict.Verdict)
+	var job model.ProviderJob
+	assert.NoError(t, db.First(&job).Error)
+	assert.Equal(t, model.ProviderJobDone, job.Status)
+	assert.Equal(t, 2, job.Polls)
+
+	// Two polls and the collect are charged to the client that submitted the lookup.
+	u, err := usageSvc.Usage(auth.FromContext(ctx), "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), u.Day.ProviderCalls)
+}
+
+func TestJobBackoff(t *testing.T) {
+	assert.Equal(t, jobPollInterval, jobBackoff(1))
+	assert.Equal(t, 4*jobPollInterval, jobBackoff(3))
+	assert.Equal(t, jobBackoffMax, jobBackoff(30))
+}
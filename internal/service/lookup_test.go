@@ -2,16 +2,28 @@ package service
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"hermes/internal/config"
+	"hermes/internal/cve"
+	"hermes/internal/dto"
+	"hermes/internal/indicator"
+	"hermes/internal/model"
 	"hermes/internal/providerapi"
+	"hermes/internal/registry"
+	"hermes/internal/usage"
+	"hermes/internal/vo"
 
 	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
 )
 
 func TestLookupService_MockAdapter(t *testing.T) {
 	mock := &providerapi.MockAdapter{
-		CodeFunc: func() string { return "mock" },
+		CodeFunc:           func() string { return "mock" },
 		SupportedTypesFunc: func() []string { return []string{"ip"} },
 		LookupFunc: func(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
 			return providerapi.Result{
@@ -28,3 +40,223 @@ func TestLookupService_MockAdapter(t *testing.T) {
 	assert.True(t, res.Success)
 	assert.Equal(t, "mock", res.ProviderCode)
 }
+
+func setupTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.AutoMigrate(&model.LookupRequest{}, &model.LookupResult{}, &model.AuditLog{}, &model.ProviderJob{}, &model.LookupObject{}))
+	return db
+}
+
+func countingAdapter(calls *int32) *providerapi.MockAdapter {
+	return &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "mock" },
+		SupportedTypesFunc: func() []string { return []string{"domain"} },
+		LookupFunc: func(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+			atomic.AddInt32(calls, 1)
+			return providerapi.Result{ProviderCode: "mock", Success: true, Data: map[string]interface{}{"value": value}}, nil
+		},
+	}
+}
+
+func TestLookupService_Cache(t *testing.T) {
+	var calls int32
+	svc := NewLookupService(&config.Config{CacheTTLSeconds: 3600}, registry.New(countingAdapter(&calls)), setupTestDB(t))
+	ctx := context.Background()
+
+	first, err := svc.Lookup(ctx, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "Example.COM"})
+	assert.NoError(t, err)
+	assert.False(t, first.Results["mock"].Cached)
+
+	// Same indicator after normalization is served from lookup_results.
+	second, err := svc.Lookup(ctx, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "example.com."})
+	assert.NoError(t, err)
+	assert.True(t, second.Results["mock"].Cached)
+	assert.True(t, second.Results["mock"].Success)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	_, err = svc.Lookup(ctx, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "example.com", Cache: dto.CacheBypass})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	miss, err := svc.Lookup(ctx, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "other.example", Cache: dto.CacheOnly})
+	assert.NoError(t, err)
+	assert.False(t, miss.Results["mock"].Success)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+// unconfiguredAdapter is a mock lacking the credentials it needs.
+type unconfiguredAdapter struct {
+	providerapi.MockAdapter
+}
+
+func (*unconfiguredAdapter) Configured() bool { return false }
+
+func TestLookupService_MetersContactedProviders(t *testing.T) {
+	var calls int32
+	keyless := &unconfiguredAdapter{providerapi.MockAdapter{
+		CodeFunc:           func() string { return "keyless" },
+		SupportedTypesFunc: func() []string { return []string{"domain"} },
+		LookupFunc: func(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+			return providerapi.Result{ProviderCode: "keyless", Error: "not configured"}, nil
+		},
+	}}
+	svc := NewLookupService(&config.Config{CacheTTLSeconds: 3600}, registry.New(countingAdapter(&calls), keyless), setupTestDB(t))
+	meter := &usage.Meter{}
+	resp, err := svc.Lookup(usage.WithMeter(context.Background(), meter), &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "example.com"})
+	assert.NoError(t, err)
+	assert.Len(t, resp.Results, 2)
+	assert.Equal(t, int64(1), meter.ProviderCalls())
+}
+
+func TestLookupService_CacheExpired(t *testing.T) {
+	var calls int32
+	db := setupTestDB(t)
+	svc := NewLookupService(&config.Config{CacheTTLSeconds: 60}, registry.New(countingAdapter(&calls)), db)
+	ctx := context.Background()
+
+	_, err := svc.Lookup(ctx, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "example.com"})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Model(&model.LookupResult{}).Where("1 = 1").Update("cached_at", time.Now().Add(-time.Hour)).Error)
+
+	res, err := svc.Lookup(ctx, &dto.LookupRequestDTO{IndicatorType: "domain", IndicatorValue: "example.com"})
+	assert.NoError(t, err)
+	assert.False(t, res.Results["mock"].Cached)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestLookupService_DeadlineReturnsPartial(t *testing.T) {
+	fast := &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "fast" },
+		SupportedTypesFunc: func() []string { return []string{"ip"} },
+	}
+	slow := &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "slow" },
+		SupportedTypesFunc: func() []string { return []string{"ip"} },
+		LookupFunc: func(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+			<-ctx.Done()
+			return providerapi.Result{ProviderCode: "slow", Error: ctx.Err().Error()}, ctx.Err()
+		},
+	}
+	cfg := &config.Config{CacheTTLSeconds: 3600, LookupDeadlineSeconds: 1}
+	svc := NewLookupService(cfg, registry.New(fast, slow), setupTestDB(t))
+
+	res, err := svc.Lookup(context.Background(), &dto.LookupRequestDTO{IndicatorType: "ip", IndicatorValue: "8.8.8.8"})
+	assert.NoError(t, err)
+	assert.True(t, res.Partial)
+	assert.Equal(t, vo.StatusOK, res.Results["fast"].Status)
+	assert.Equal(t, vo.StatusTimedOut, res.Results["slow"].Status)
+}
+
+func TestLookupService_AggregatedVerdict(t *testing.T) {
+	bad := &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "bad" },
+		SupportedTypesFunc: func() []string { return []string{"ip"} },
+		AssessFunc: func(data map[string]interface{}) providerapi.Assessment {
+			return providerapi.Assess(providerapi.VerdictMalicious, 90)
+		},
+	}
+	neutral := &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "neutral" },
+		SupportedTypesFunc: func() []string { return []string{"ip"} },
+	}
+	db := setupTestDB(t)
+	svc := NewLookupService(&config.Config{CacheTTLSeconds: 3600}, registry.New(bad, neutral), db)
+
+	res, err := svc.Lookup(context.Background(), &dto.LookupRequestDTO{IndicatorType: "ip", IndicatorValue: "1.1.1.1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "malicious", res.Results["bad"].Assessment.Verdict)
+	assert.Equal(t, "unknown", res.Results["neutral"].Assessment.Verdict)
+	assert.Equal(t, "malicious", res.Verdict.Verdict)
+	assert.Equal(t, 90, res.Verdict.Score)
+	assert.Len(t, res.Verdict.Contributors, 1)
+
+	var stored model.LookupResult
+	assert.NoError(t, db.Where("provider_code = ?", "bad").First(&stored).Error)
+	assert.Equal(t, "malicious", stored.Verdict)
+	var req model.LookupRequest
+	assert.NoError(t, db.First(&req).Error)
+	assert.Equal(t, "malicious", req.Verdict)
+}
+
+// cveAdapter is a MockAdapter that also implements cve.Parser.
+type cveAdapter struct {
+	*providerapi.MockAdapter
+	parse func(data map[string]interface{}) (cve.Record, bool)
+}
+
+func (a *cveAdapter) ParseCVE(data map[string]interface{}) (cve.Record, bool) { return a.parse(data) }
+
+func newCVEAdapter(code, vector string) *cveAdapter {
+	return &cveAdapter{
+		MockAdapter: &providerapi.MockAdapter{
+			CodeFunc:           func() string { return code },
+			SupportedTypesFunc: func() []string { return []string{"cve"} },
+			LookupFunc: func(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+				return providerapi.Result{ProviderCode: code, Success: true, Data: map[string]interface{}{"id": value}}, nil
+			},
+		},
+		parse: func(data map[string]interface{}) (cve.Record, bool) {
+			return cve.Record{
+				ID:      data["id"].(string),
+				CVSS:    []cve.CVSS{{Version: cve.VersionFromVector(vector), Vector: vector, Source: code}},
+				CWEs:    []string{"CWE-506"},
+				Sources: []string{code},
+			}, true
+		},
+	}
+}
+
+func TestLookupService_CVE(t *testing.T) {
+	reg := registry.New(
+		providerapi.WithPolicy(newCVEAdapter("nvd", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"), providerapi.Policy{}),
+		newCVEAdapter("circl_cve", "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"),
+	)
+	svc := NewLookupService(&config.Config{CacheTTLSeconds: 3600}, reg, setupTestDB(t))
+
+	res, err := svc.Lookup(context.Background(), &dto.LookupRequestDTO{IndicatorType: "cve", IndicatorValue: " cve-2024-3094 "})
+	assert.NoError(t, err)
+	assert.Equal(t, "CVE-2024-3094", res.IndicatorValue)
+	assert.NotNil(t, res.CVE)
+	assert.Equal(t, "CVE-2024-3094", res.CVE.ID)
+	assert.Equal(t, []string{"circl_cve", "nvd"}, res.CVE.Sources)
+	assert.Len(t, res.CVE.CVSS, 2)
+	assert.Equal(t, "4.0", res.CVE.CVSS[0].Version)
+
+	_, err = svc.Lookup(context.Background(), &dto.LookupRequestDTO{IndicatorType: "cve", IndicatorValue: "e3b0c44298fc1c149afbf4c8996fb924"})
+	assert.ErrorIs(t, err, indicator.ErrInvalid)
+}
+
+func TestLookupService_LookupStream(t *testing.T) {
+	release := make(chan struct{})
+	fast := &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "fast" },
+		SupportedTypesFunc: func() []string { return []string{"ip"} },
+	}
+	slow := &providerapi.MockAdapter{
+		CodeFunc:           func() string { return "slow" },
+		SupportedTypesFunc: func() []string { return []string{"ip"} },
+		LookupFunc: func(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+			<-release
+			return providerapi.Result{ProviderCode: "slow", Success: true, Data: map[string]interface{}{}}, nil
+		},
+	}
+	svc := NewLookupService(&config.Config{CacheTTLSeconds: 3600}, registry.New(fast, slow), setupTestDB(t))
+
+	var start *vo.LookupStartVO
+	var order []string
+	res, err := svc.LookupStream(context.Background(), &dto.LookupRequestDTO{IndicatorType: "ip", IndicatorValue: "8.8.8.8"}, LookupHooks{
+		Started: func(v *vo.LookupStartVO) { start = v },
+		Result: func(r vo.ProviderResultVO) {
+			order = append(order, r.ProviderCode)
+			// slow answers only once fast has been streamed.
+			if r.ProviderCode == "fast" {
+				close(release)
+			}
+		},
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"fast", "slow"}, start.Providers)
+	assert.Equal(t, res.RequestID, start.RequestID)
+	assert.Equal(t, []string{"fast", "slow"}, order)
+}
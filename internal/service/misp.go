@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"hermes/internal/misp"
+	"hermes/internal/providerapi"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+)
+
+// ErrMISPPush is returned when MISP could not be reached or rejected a pushed event.
+var ErrMISPPush = errors.New("push to MISP failed")
+
+// MISP returns a past lookup as a MISP event. It returns gorm.ErrRecordNotFound like Get.
+func (s *LookupService) MISP(ctx context.Context, requestID uuid.UUID) (*misp.Envelope, error) {
+	l, err := s.Get(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	info := fmt.Sprintf("Hermes lookup: %s %s", l.IndicatorType, l.IndicatorValue)
+	return misp.NewEvent("lookup:"+l.RequestID, info, []misp.Lookup{s.mispLookup(l)}, s.mispOptions()), nil
+}
+
+// PushMISP pushes a past lookup to the configured MISP instance; pushing it again updates the
+// event. It returns misp.ErrNotConfigured without a MISP URL and key.
+func (s *LookupService) PushMISP(ctx context.Context, requestID uuid.UUID) (*vo.MISPPushVO, error) {
+	if s.misp == nil {
+		return nil, misp.ErrNotConfigured
+	}
+	e, err := s.MISP(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	return s.pushMISP(ctx, e)
+}
+
+func (s *LookupService) pushMISP(ctx context.Context, e *misp.Envelope) (*vo.MISPPushVO, error) {
+	id, created, err := s.misp.Push(ctx, e)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMISPPush, err)
+	}
+	return &vo.MISPPushVO{EventID: id, EventUUID: e.Event.UUID, Created: created, Attributes: len(e.Event.Attribute)}, nil
+}
+
+// mispLookup pairs a lookup with the galaxy clusters of the malware families named by successful
+// results of adapters implementing misp.FamilyParser.
+func (s *LookupService) mispLookup(l *vo.LookupResponseVO) misp.Lookup {
+	out := misp.Lookup{Response: l}
+	for code, r := range l.Results {
+		a := s.registry.AdapterByCode(code)
+		if a == nil || !r.Success {
+			continue
+		}
+		if p, ok := providerapi.Base(a).(misp.FamilyParser); ok {
+			data, _ := r.Data.(map[string]interface{})
+			out.Galaxies = append(out.Galaxies, misp.Families(p.MalwareFamilies(data))...)
+		}
+	}
+	return out
+}
+
+func (s *LookupService) mispOptions() misp.Options {
+	return misp.Options{Distribution: s.cfg.MISPDistribution, Published: s.cfg.MISPPublish}
+}
+
+// MISP returns the lookups of a bulk job as one MISP event; see Lookups.
+func (s *BulkService) MISP(ctx context.Context, jobID uuid.UUID) (*misp.Envelope, error) {
+	lookups, err := s.Lookups(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]misp.Lookup, 0, len(lookups))
+	for _, l := range lookups {
+		list = append(list, s.lookup.mispLookup(l))
+	}
+	info := fmt.Sprintf("Hermes bulk lookup %s (%d indicators)", jobID, len(lookups))
+	return misp.NewEvent("bulk:"+jobID.String(), info, list, s.lookup.mispOptions()), nil
+}
+
+// PushMISP pushes a bulk job's lookups to MISP as one event; see LookupService.PushMISP.
+func (s *BulkService) PushMISP(ctx context.Context, jobID uuid.UUID) (*vo.MISPPushVO, error) {
+	if s.lookup.misp == nil {
+		return nil, misp.ErrNotConfigured
+	}
+	e, err := s.MISP(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	return s.lookup.pushMISP(ctx, e)
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"hermes/internal/config"
+	"hermes/internal/indicator"
+	"hermes/internal/metrics"
+	"hermes/internal/providerapi"
+	"hermes/internal/registry"
+	"hermes/internal/usage"
+	"hermes/internal/vo"
+
+	"gorm.io/gorm"
+)
+
+// canaries are the indicators Health looks up, per type: well-known public values, and for
+// hashes the EICAR test file (sha256, sha1, md5) so providers limited to some algorithms match.
+var canaries = map[string][]string{
+	indicator.TypeIP:     {"8.8.8.8"},
+	indicator.TypeDomain: {"example.com"},
+	indicator.TypeURL:    {"https://example.com/"},
+	indicator.TypeEmail:  {"test@example.com"},
+	indicator.TypeCVE:    {"CVE-2021-44228"},
+	indicator.TypeHash: {
+		"275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f",
+		"3395856ce81f2b7382dee72602f798b642f14140",
+		"44d88612fea8a8f36de82e1278abb02f",
+	},
+}
+
+// ProviderService reports the registered providers' capabilities and state, and checks them
+// with canary lookups.
+type ProviderService struct {
+	cfg      *config.Config
+	registry *registry.Registry
+}
+
+// NewProviderService creates a provider service.
+func NewProviderService(cfg *config.Config, reg *registry.Registry) *ProviderService {
+	return &ProviderService{cfg: cfg, registry: reg}
+}
+
+// List describes every registered provider, sorted by code.
+func (s *ProviderService) List() []vo.ProviderStatusVO {
+	codes := s.registry.AllCodes()
+	sort.Strings(codes)
+	out := make([]vo.ProviderStatusVO, 0, len(codes))
+	for _, code := range codes {
+		a := s.registry.AdapterByCode(code)
+		st := vo.ProviderStatusVO{
+			Code:            code,
+			Name:            s.registry.Name(code),
+			SupportedTypes:  a.SupportedTypes(),
+			Configured:      providerapi.IsConfigured(a),
+			Enabled:         s.registry.Enabled(code),
+			Async:           providerapi.IsAsync(a),
+			RateLimitPerMin: s.registry.RateLimit(code),
+		}
+		if h, ok := providerapi.Base(a).(providerapi.HashSubtyper); ok {
+			st.HashTypes = h.HashSubtypes()
+		}
+		recent := metrics.Recent(code)
+		st.Recent = vo.ProviderRecentVO{
+			Calls:        recent.Calls,
+			AvgLatencyMs: recent.AvgLatency.Milliseconds(),
+			P95LatencyMs: recent.P95Latency.Milliseconds(),
+		}
+		if recent.Calls > 0 {
+			rate := float64(recent.Succeeded) / float64(recent.Calls)
+			st.Recent.SuccessRate = &rate
+			st.Recent.LastCallAt = &recent.LastCallAt
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// Health looks up a canary indicator of a type the provider supports, through its rate limit,
+// and reports whether it answered. Disabled providers are checked too. Async providers are only
+// checked with lookups that start no provider-side job (see providerapi.PassiveTypes), and are
+// reported as skipped without one. It returns gorm.ErrRecordNotFound for unknown providers.
+func (s *ProviderService) Health(ctx context.Context, code string) (*vo.ProviderHealthVO, error) {
+	a := s.registry.AdapterByCode(code)
+	if a == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	out := &vo.ProviderHealthVO{ProviderCode: code, CheckedAt: time.Now().UTC()}
+	t, value, ok := canary(a)
+	if !ok && providerapi.IsAsync(a) {
+		out.Status = vo.HealthSkipped
+		out.Error = "every lookup would start a provider job"
+		return out, nil
+	}
+	if !ok {
+		out.Status = vo.StatusError
+		out.Error = "no canary for the provider's indicator types"
+		return out, nil
+	}
+	out.IndicatorType, out.IndicatorValue = t, value
+	if !providerapi.IsConfigured(a) {
+		out.Status = vo.StatusError
+		out.Error = "not configured"
+		return out, nil
+	}
+
+	if s.cfg.LookupDeadlineSeconds > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(s.cfg.LookupDeadlineSeconds)*time.Second)
+		defer cancel()
+	}
+	start := time.Now()
+	res, err := a.Lookup(ctx, t, value)
+	out.LatencyMs = time.Since(start).Milliseconds()
+	if providerapi.Contacted(a, t, err) {
+		usage.CountProviderCall(ctx)
+	}
+	r := resultVO(code, res, err)
+	out.Status = r.Status
+	out.Healthy = r.Status == vo.StatusOK || r.Status == vo.StatusPending
+	out.Error = r.Error
+	if out.Error == "" && err != nil {
+		out.Error = err.Error()
+	}
+	return out, nil
+}
+
+// canary picks the first canary the adapter accepts, in the order of the types it looks up
+// without starting a job.
+func canary(a providerapi.Adapter) (string, string, bool) {
+	for _, t := range providerapi.PassiveTypes(a) {
+		for _, v := range canaries[t] {
+			ind, err := indicator.Canonicalize(t, v, indicator.Options{})
+			if err != nil || ind.Subtype != "" && !providerapi.AcceptsHash(a, ind.Subtype) {
+				continue
+			}
+			return t, ind.Value, true
+		}
+	}
+	return "", "", false
+}
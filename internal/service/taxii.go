@@ -0,0 +1,431 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"hermes/internal/dto"
+	"hermes/internal/logging"
+	"hermes/internal/model"
+	"hermes/internal/repository"
+	"hermes/internal/stix"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultTAXIILimit = 100
+	maxTAXIILimit     = 1000
+	// maxTAXIIScan caps the entries one request converts; a page is cut short, with more set,
+	// once that many were read without filling it.
+	maxTAXIIScan = 5000
+	// indexBatch is how many lookups IndexObjects converts at a time.
+	indexBatch = 500
+)
+
+// ErrInvalidCollection is returned for curated collections that cannot be created or changed
+// as asked.
+var ErrInvalidCollection = errors.New("invalid collection")
+
+var taxiiLog = logging.For("taxii")
+
+// builtinCollection is a TAXII collection derived from stored lookups: those whose verdict was
+// stored within the window. Their date_added is when the verdict was last stored, so a lookup a
+// provider job turns malicious later is added then, not when it was made.
+type builtinCollection struct {
+	id          uuid.UUID
+	alias       string
+	title       string
+	description string
+	verdict     string
+	window      time.Duration
+}
+
+var builtinCollections = []builtinCollection{{
+	id:          uuid.MustParse("9c119955-a20b-59a3-9e0e-fdb93756a999"),
+	alias:       "malicious-last-7d",
+	title:       "Malicious indicators, last 7 days",
+	description: "Indicators whose lookups got an aggregate verdict of malicious in the last 7 days.",
+	verdict:     "malicious",
+	window:      7 * 24 * time.Hour,
+}}
+
+// TAXIIObject is a STIX object of a collection and when it was added.
+type TAXIIObject struct {
+	Object    stix.Object
+	DateAdded time.Time
+}
+
+// TAXIIPage is a page of a collection's objects; Next resumes after it when More is set.
+type TAXIIPage struct {
+	Objects []TAXIIObject
+	More    bool
+	Next    string
+}
+
+// taxiiCursor is the opaque next parameter: the entry to resume at and how many of its objects
+// were already served.
+type taxiiCursor struct {
+	DateAdded time.Time `json:"d"`
+	Seq       int64     `json:"s"`
+	Skip      int       `json:"k"`
+}
+
+// TAXIIService serves lookups as TAXII collections of STIX objects: built-in collections
+// derived from lookup verdicts and curated collections of hand-picked lookups.
+type TAXIIService struct {
+	lookup *LookupService
+	repo   *repository.TAXIIRepository
+}
+
+// NewTAXIIService creates a TAXII service converting lookups with lookup.
+func NewTAXIIService(lookup *LookupService, db *gorm.DB) *TAXIIService {
+	return &TAXIIService{lookup: lookup, repo: repository.NewTAXIIRepository(db)}
+}
+
+// Collections lists the built-in collections, then the curated ones.
+func (s *TAXIIService) Collections() ([]vo.TAXIICollectionVO, error) {
+	out := make([]vo.TAXIICollectionVO, 0, len(builtinCollections))
+	for _, b := range builtinCollections {
+		out = append(out, collectionVO(b.id, b.alias, b.title, b.description))
+	}
+	list, err := s.repo.ListCollections()
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		out = append(out, curatedVO(&list[i]))
+	}
+	return out, nil
+}
+
+// Collection returns a collection by ID or alias, or gorm.ErrRecordNotFound.
+func (s *TAXIIService) Collection(idOrAlias string) (*vo.TAXIICollectionVO, error) {
+	if b := builtin(idOrAlias); b != nil {
+		v := collectionVO(b.id, b.alias, b.title, b.description)
+		return &v, nil
+	}
+	c, err := s.repo.GetCollection(idOrAlias)
+	if err != nil {
+		return nil, err
+	}
+	v := curatedVO(c)
+	return &v, nil
+}
+
+// Objects returns a page of a collection's objects in date_added order, filtered by q. Objects
+// shared by several lookups (identities, observables) are served once per page. Type and ID
+// filters select the lookups in SQL; at most maxTAXIIScan lookups are converted per request. It
+// returns gorm.ErrRecordNotFound for unknown collections and ErrInvalidCursor for a bad next.
+func (s *TAXIIService) Objects(idOrAlias string, q *dto.TAXIIQueryDTO) (*TAXIIPage, error) {
+	f, err := s.filter(idOrAlias)
+	if err != nil {
+		return nil, err
+	}
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultTAXIILimit
+	}
+	if limit > maxTAXIILimit {
+		limit = maxTAXIILimit
+	}
+	f.AddedAfter = q.AddedAfter
+	f.Limit = limit + 1
+	skip := 0
+	if q.Next != "" {
+		c, err := decodeTAXIICursor(q.Next)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		f.From = &repository.TAXIIEntry{DateAdded: c.DateAdded, Seq: c.Seq}
+		skip = c.Skip
+	}
+	types, ids := splitList(q.Types), splitList(q.IDs)
+	out := &TAXIIPage{}
+	if len(types) > 0 {
+		kinds, all := stix.IndicatorTypes(types...)
+		if !all && len(kinds) == 0 {
+			return out, nil
+		}
+		f.IndicatorTypes = kinds
+	}
+	f.ObjectIDs = ids
+
+	seen := make(map[string]bool)
+	scanned := 0
+	for {
+		entries, err := s.repo.Entries(f)
+		if err != nil {
+			return nil, err
+		}
+		objects, err := s.entryObjects(entries)
+		if err != nil {
+			return nil, err
+		}
+		for k, e := range entries {
+			if scanned == maxTAXIIScan {
+				out.More = true
+				out.Next = encodeTAXIICursor(taxiiCursor{DateAdded: e.DateAdded, Seq: e.Seq, Skip: skip})
+				return out, nil
+			}
+			scanned++
+			for i := skip; i < len(objects[k]); i++ {
+				o := objects[k][i]
+				if seen[o.ID()] || !matches(o, types, ids) {
+					continue
+				}
+				if len(out.Objects) == limit {
+					out.More = true
+					out.Next = encodeTAXIICursor(taxiiCursor{DateAdded: e.DateAdded, Seq: e.Seq, Skip: i})
+					return out, nil
+				}
+				seen[o.ID()] = true
+				out.Objects = append(out.Objects, TAXIIObject{Object: o, DateAdded: e.DateAdded})
+			}
+			skip = 0
+		}
+		if len(entries) < f.Limit {
+			return out, nil
+		}
+		last := entries[len(entries)-1]
+		f.From = &repository.TAXIIEntry{DateAdded: last.DateAdded, Seq: last.Seq + 1}
+	}
+}
+
+// filter selects the entries of a collection.
+func (s *TAXIIService) filter(idOrAlias string) (repository.TAXIIFilter, error) {
+	if b := builtin(idOrAlias); b != nil {
+		since := time.Now().Add(-b.window)
+		return repository.TAXIIFilter{Verdict: b.verdict, Since: &since}, nil
+	}
+	c, err := s.repo.GetCollection(idOrAlias)
+	if err != nil {
+		return repository.TAXIIFilter{}, err
+	}
+	return repository.TAXIIFilter{CollectionID: c.ID}, nil
+}
+
+// entryObjects converts the entries' lookups, reading them all at once; a lookup deleted
+// meanwhile has no objects.
+func (s *TAXIIService) entryObjects(entries []repository.TAXIIEntry) ([][]stix.Object, error) {
+	ids := make([]uuid.UUID, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.RequestID)
+	}
+	reqs, err := s.lookup.reqRepo.GetByRequestIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	list, err := s.lookup.responses(reqs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*vo.LookupResponseVO, len(list))
+	for _, l := range list {
+		byID[l.RequestID] = l
+	}
+	out := make([][]stix.Object, len(entries))
+	for i, e := range entries {
+		l := byID[e.RequestID.String()]
+		if l == nil {
+			continue
+		}
+		if out[i], err = s.lookup.stixObjects(l); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// stixObjects converts a lookup to its STIX objects.
+func (s *LookupService) stixObjects(l *vo.LookupResponseVO) ([]stix.Object, error) {
+	b := stix.NewBuilder(s.registry.Name)
+	if err := b.Add(l); err != nil {
+		return nil, err
+	}
+	return b.Objects(), nil
+}
+
+// indexObjects records the IDs of the STIX objects of a stored lookup; see model.LookupObject.
+// Objects only get added as results come in, so recorded IDs are never stale.
+func (s *LookupService) indexObjects(req *model.LookupRequest) error {
+	l, err := s.response(req)
+	if err != nil {
+		return err
+	}
+	objects, err := s.stixObjects(l)
+	if err != nil {
+		return err
+	}
+	ids := make([]string, 0, len(objects))
+	for _, o := range objects {
+		ids = append(ids, o.ID())
+	}
+	return s.reqRepo.AddObjects(req.ID, ids)
+}
+
+// IndexObjects records the STIX object IDs of stored lookups that have none, such as lookups
+// stored before they were recorded, until all are done or ctx is cancelled. Lookups that cannot
+// be converted are logged and skipped.
+func (s *LookupService) IndexObjects(ctx context.Context) {
+	var after int64
+	for ctx.Err() == nil {
+		reqs, err := s.reqRepo.ListUnindexed(after, indexBatch)
+		if err != nil {
+			taxiiLog.ErrorContext(ctx, "list unindexed lookups", "error", err)
+			return
+		}
+		for i := range reqs {
+			if err := s.indexObjects(&reqs[i]); err != nil {
+				taxiiLog.WarnContext(ctx, "index lookup objects", "request_id", reqs[i].RequestID, "error", err)
+			}
+		}
+		if len(reqs) < indexBatch {
+			return
+		}
+		after = reqs[len(reqs)-1].ID
+	}
+}
+
+// CreateCollection creates a curated collection. The alias must be unused and not a UUID.
+func (s *TAXIIService) CreateCollection(d *dto.CreateTAXIICollectionDTO) (*vo.TAXIICollectionVO, error) {
+	c := &model.TAXIICollection{Title: d.Title, Description: d.Description}
+	if d.Alias != "" {
+		if _, err := uuid.Parse(d.Alias); err == nil {
+			return nil, fmt.Errorf("%w: alias must not be a UUID", ErrInvalidCollection)
+		}
+		if builtin(d.Alias) != nil {
+			return nil, fmt.Errorf("%w: alias %q is taken", ErrInvalidCollection, d.Alias)
+		}
+		if _, err := s.repo.GetCollection(d.Alias); err == nil {
+			return nil, fmt.Errorf("%w: alias %q is taken", ErrInvalidCollection, d.Alias)
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		c.Alias = &d.Alias
+	}
+	if err := s.repo.CreateCollection(c); err != nil {
+		return nil, err
+	}
+	v := curatedVO(c)
+	return &v, nil
+}
+
+// DeleteCollection deletes a curated collection; see curated.
+func (s *TAXIIService) DeleteCollection(idOrAlias string) error {
+	c, err := s.curated(idOrAlias)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteCollection(c.ID)
+}
+
+// AddLookup adds a stored lookup to a curated collection; see curated. It returns
+// gorm.ErrRecordNotFound for unknown lookups.
+func (s *TAXIIService) AddLookup(idOrAlias string, requestID uuid.UUID) error {
+	c, err := s.curated(idOrAlias)
+	if err != nil {
+		return err
+	}
+	req, err := s.lookup.reqRepo.GetByRequestID(requestID)
+	if err != nil {
+		return err
+	}
+	return s.repo.AddLookup(c.ID, req.ID)
+}
+
+// RemoveLookup removes a lookup from a curated collection; see AddLookup.
+func (s *TAXIIService) RemoveLookup(idOrAlias string, requestID uuid.UUID) error {
+	c, err := s.curated(idOrAlias)
+	if err != nil {
+		return err
+	}
+	req, err := s.lookup.reqRepo.GetByRequestID(requestID)
+	if err != nil {
+		return err
+	}
+	return s.repo.RemoveLookup(c.ID, req.ID)
+}
+
+// curated loads a curated collection. It returns ErrInvalidCollection for built-in collections
+// and gorm.ErrRecordNotFound for unknown ones.
+func (s *TAXIIService) curated(idOrAlias string) (*model.TAXIICollection, error) {
+	if builtin(idOrAlias) != nil {
+		return nil, fmt.Errorf("%w: built-in collections cannot be changed", ErrInvalidCollection)
+	}
+	return s.repo.GetCollection(idOrAlias)
+}
+
+func builtin(idOrAlias string) *builtinCollection {
+	for i := range builtinCollections {
+		if b := &builtinCollections[i]; b.alias == idOrAlias || b.id.String() == strings.ToLower(idOrAlias) {
+			return b
+		}
+	}
+	return nil
+}
+
+func curatedVO(c *model.TAXIICollection) vo.TAXIICollectionVO {
+	alias := ""
+	if c.Alias != nil {
+		alias = *c.Alias
+	}
+	return collectionVO(c.CollectionID, alias, c.Title, c.Description)
+}
+
+func collectionVO(id uuid.UUID, alias, title, description string) vo.TAXIICollectionVO {
+	return vo.TAXIICollectionVO{
+		ID:          id.String(),
+		Title:       title,
+		Description: description,
+		Alias:       alias,
+		CanRead:     true,
+		MediaTypes:  []string{stix.MediaType},
+	}
+}
+
+// matches reports whether o is of one of types and has one of ids; empty lists match all.
+func matches(o stix.Object, types, ids []string) bool {
+	return (len(types) == 0 || contains(types, o.Type())) && (len(ids) == 0 || contains(ids, o.ID()))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func encodeTAXIICursor(c taxiiCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeTAXIICursor(s string) (taxiiCursor, error) {
+	var c taxiiCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, fmt.Errorf("decode cursor: %w", err)
+	}
+	err = json.Unmarshal(b, &c)
+	return c, err
+}
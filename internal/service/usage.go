@@ -0,0 +1,166 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"hermes/internal/auth"
+	"hermes/internal/config"
+	"hermes/internal/model"
+	"hermes/internal/ratelimit"
+	"hermes/internal/repository"
+	"hermes/internal/usage"
+	"hermes/internal/vo"
+
+	"gorm.io/gorm"
+)
+
+// UsageService enforces per-client request rates and provider-call quotas and reports usage.
+// Request rates are tracked in memory per process; quotas are counted in client_usage.
+type UsageService struct {
+	cfg      *config.Config
+	repo     *repository.UsageRepository
+	clients  *repository.APIKeyRepository
+	mu       sync.Mutex
+	limiters map[string]*ratelimit.Limiter
+}
+
+// NewUsageService creates a usage service.
+func NewUsageService(cfg *config.Config, db *gorm.DB) *UsageService {
+	return &UsageService{
+		cfg:      cfg,
+		repo:     repository.NewUsageRepository(db),
+		clients:  repository.NewAPIKeyRepository(db),
+		limiters: make(map[string]*ratelimit.Limiter),
+	}
+}
+
+// Admit checks p's quotas and takes one request from its rate. Quotas are checked before the
+// request runs, so a lookup admitted just under the limit may overshoot it by its fan-out.
+func (s *UsageService) Admit(p *auth.Principal) (usage.Status, error) {
+	now := time.Now()
+	st, err := s.quotaStatus(p.ClientName, p.Limits, now)
+	if err != nil {
+		return st, err
+	}
+	st.Rate.Limit = int64(p.Limits.RatePerMin)
+	if st.Exceeded != "" {
+		return st, nil
+	}
+	if st.Rate.Limit > 0 {
+		ok, remaining, wait := s.limiter(p.ClientName, p.Limits.RatePerMin).TryAcquire()
+		st.Rate.Used = st.Rate.Limit - int64(remaining)
+		st.Rate.Reset = now.Add(wait)
+		if !ok {
+			st.Exceeded, st.RetryAfter = usage.ExceededRate, wait
+		}
+	}
+	return st, nil
+}
+
+// Record adds requests and provider calls to a client's usage for today.
+func (s *UsageService) Record(clientName string, requests, providerCalls int64) error {
+	return s.repo.Add(clientName, usage.DayStart(time.Now()), requests, providerCalls)
+}
+
+// QuotaExhausted reports whether the named client has used up its daily or monthly provider-call
+// quota, for work running outside a request (bulk jobs). Unknown clients are unlimited.
+func (s *UsageService) QuotaExhausted(clientName string) (bool, error) {
+	c, err := s.clients.GetClientByName(clientName)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	st, err := s.quotaStatus(clientName, clientLimits(s.cfg, c), time.Now())
+	return st.Exceeded != "", err
+}
+
+// Usage reports a client's usage. Admins may name another client; everyone else gets their own.
+// It returns gorm.ErrRecordNotFound for unknown clients.
+func (s *UsageService) Usage(p *auth.Principal, clientName string) (*vo.UsageVO, error) {
+	limits := p.Limits
+	if clientName != "" && clientName != p.ClientName {
+		c, err := s.clients.GetClientByName(clientName)
+		if err != nil {
+			return nil, err
+		}
+		limits = clientLimits(s.cfg, c)
+	} else {
+		clientName = p.ClientName
+	}
+	now := time.Now()
+	day, month := usage.DayStart(now), usage.MonthStart(now)
+	t, err := s.repo.Totals(clientName, day, month)
+	if err != nil {
+		return nil, err
+	}
+	return &vo.UsageVO{
+		Client:          clientName,
+		RateLimitPerMin: limits.RatePerMin,
+		Day:             usageWindowVO(t.DayRequests, t.DayProviderCalls, limits.DailyCalls, day.AddDate(0, 0, 1)),
+		Month:           usageWindowVO(t.MonthRequests, t.MonthProviderCalls, limits.MonthlyCalls, month.AddDate(0, 1, 0)),
+	}, nil
+}
+
+func (s *UsageService) quotaStatus(clientName string, limits auth.Limits, now time.Time) (usage.Status, error) {
+	day, month := usage.DayStart(now), usage.MonthStart(now)
+	st := usage.Status{
+		Daily:   usage.Window{Limit: int64(limits.DailyCalls), Reset: day.AddDate(0, 0, 1)},
+		Monthly: usage.Window{Limit: int64(limits.MonthlyCalls), Reset: month.AddDate(0, 1, 0)},
+	}
+	if st.Daily.Limit == 0 && st.Monthly.Limit == 0 {
+		return st, nil
+	}
+	t, err := s.repo.Totals(clientName, day, month)
+	if err != nil {
+		return st, err
+	}
+	st.Daily.Used, st.Monthly.Used = t.DayProviderCalls, t.MonthProviderCalls
+	switch {
+	case st.Monthly.Exceeded():
+		st.Exceeded, st.RetryAfter = usage.ExceededMonthly, st.Monthly.Reset.Sub(now)
+	case st.Daily.Exceeded():
+		st.Exceeded, st.RetryAfter = usage.ExceededDaily, st.Daily.Reset.Sub(now)
+	}
+	return st, nil
+}
+
+// limiter returns the client's request limiter, applying limit changes.
+func (s *UsageService) limiter(clientName string, perMin int) *ratelimit.Limiter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.limiters[clientName]
+	if !ok {
+		l = ratelimit.NewLimiter(perMin, false)
+		s.limiters[clientName] = l
+	}
+	l.Configure(perMin, false)
+	return l
+}
+
+// clientLimits resolves a client's overrides against the configured defaults.
+func clientLimits(cfg *config.Config, c *model.Client) auth.Limits {
+	pick := func(override *int, def int) int {
+		if override != nil {
+			return *override
+		}
+		return def
+	}
+	return auth.Limits{
+		RatePerMin:   pick(c.RateLimitPerMin, cfg.ClientRateLimitPerMin),
+		DailyCalls:   pick(c.DailyQuota, cfg.ClientDailyQuota),
+		MonthlyCalls: pick(c.MonthlyQuota, cfg.ClientMonthlyQuota),
+	}
+}
+
+func usageWindowVO(requests, calls int64, limit int, resetsAt time.Time) vo.UsageWindowVO {
+	out := vo.UsageWindowVO{Requests: requests, ProviderCalls: calls, Limit: limit, ResetsAt: resetsAt}
+	if limit > 0 {
+		remaining := usage.Window{Limit: int64(limit), Used: calls}.Remaining()
+		out.Remaining = &remaining
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+
+	"hermes/internal/auth"
+	"hermes/internal/config"
+	"hermes/internal/model"
+	"hermes/internal/usage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsageService_AdmitAndRecord(t *testing.T) {
+	db := setupTestDB(t)
+	assert.NoError(t, db.AutoMigrate(&model.Client{}, &model.APIKey{}, &model.ClientUsage{}))
+	svc := NewUsageService(&config.Config{ClientDailyQuota: 10}, db)
+	p := &auth.Principal{ClientName: "soc", Limits: auth.Limits{RatePerMin: 2, DailyCalls: 10}}
+
+	st, err := svc.Admit(p)
+	assert.NoError(t, err)
+	assert.Empty(t, st.Exceeded)
+	assert.Equal(t, int64(1), st.Rate.Remaining())
+	assert.NoError(t, svc.Record("soc", 1, 6))
+	assert.NoError(t, svc.Record("soc", 1, 4))
+
+	// The daily quota is checked before the rate, so no token is spent on a rejected request.
+	st, err = svc.Admit(p)
+	assert.NoError(t, err)
+	assert.Equal(t, usage.ExceededDaily, st.Exceeded)
+	assert.Equal(t, int64(10), st.Daily.Used)
+	assert.Positive(t, st.RetryAfter)
+
+	p.Limits.DailyCalls = 0
+	st, _ = svc.Admit(p)
+	assert.Empty(t, st.Exceeded)
+	st, _ = svc.Admit(p)
+	assert.Equal(t, usage.ExceededRate, st.Exceeded)
+
+	u, err := svc.Usage(p, "")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), u.Day.Requests)
+	assert.Equal(t, int64(10), u.Month.ProviderCalls)
+	assert.Nil(t, u.Day.Remaining)
+
+	// Unknown clients (e.g. the bootstrap admin) are never exhausted; known ones use their overrides.
+	exhausted, err := svc.QuotaExhausted("soc")
+	assert.NoError(t, err)
+	assert.False(t, exhausted)
+	assert.NoError(t, db.Create(&model.Client{Name: "soc"}).Error)
+	exhausted, err = svc.QuotaExhausted("soc")
+	assert.NoError(t, err)
+	assert.True(t, exhausted)
+}
@@ -0,0 +1,380 @@
+// Package stix converts lookup results into STIX 2.1 bundles: the indicator as an observable
+// (SCO) and an indicator pattern, or a vulnerability for CVEs, one identity per provider, and
+// notes and sightings carrying each provider's verdict. IDs are derived from the content and the
+// lookup's request ID, so a re-exported lookup yields the same objects and consumers deduplicate
+// them; observables and identities are shared by every lookup.
+package stix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"hermes/internal/indicator"
+	"hermes/internal/vo"
+
+	"github.com/google/uuid"
+)
+
+// MediaType is the content type of STIX 2.1 bundles.
+const MediaType = "application/stix+json;version=2.1"
+
+const specVersion = "2.1"
+
+var (
+	// scoNamespace is the STIX namespace for deterministic SCO identifiers (STIX 2.1, 2.9).
+	scoNamespace = uuid.MustParse("00abedb4-aa42-466c-9c01-fed23315a9b7")
+	// sdoNamespace derives the identifiers of the SDOs produced here.
+	sdoNamespace = uuid.MustParse("533cd8b5-e7c6-4610-86e8-1836ffa7a1fc")
+	// identityEpoch is created/modified of identities, which never change.
+	identityEpoch = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// hashKeys maps indicator hash subtypes to STIX hash algorithm names.
+var hashKeys = map[string]string{
+	indicator.HashMD5:    "MD5",
+	indicator.HashSHA1:   "SHA-1",
+	indicator.HashSHA256: "SHA-256",
+	indicator.HashSHA512: "SHA-512",
+	indicator.HashSSDEEP: "SSDEEP",
+	indicator.HashTLSH:   "TLSH",
+}
+
+// Object is a STIX object; every object has at least type, spec_version and id.
+type Object map[string]interface{}
+
+// ID returns the object's id.
+func (o Object) ID() string {
+	id, _ := o["id"].(string)
+	return id
+}
+
+// Type returns the object's type.
+func (o Object) Type() string {
+	t, _ := o["type"].(string)
+	return t
+}
+
+// Bundle is a STIX 2.1 bundle.
+type Bundle struct {
+	Type    string   `json:"type"`
+	ID      string   `json:"id"`
+	Objects []Object `json:"objects"`
+}
+
+// Builder collects the objects of one or more lookups, each object once.
+type Builder struct {
+	names   func(code string) string
+	objects []Object
+	seen    map[string]bool
+}
+
+// NewBuilder returns a builder naming provider identities with names (e.g. the registry's
+// display names).
+func NewBuilder(names func(code string) string) *Builder {
+	return &Builder{names: names, seen: make(map[string]bool)}
+}
+
+// Bundle returns the collected objects in a bundle whose ID is derived from key (e.g.
+// "lookup:<request_id>").
+func (b *Builder) Bundle(key string) *Bundle {
+	objects := b.objects
+	if objects == nil {
+		objects = []Object{}
+	}
+	return &Bundle{Type: "bundle", ID: sdoID("bundle", key), Objects: objects}
+}
+
+// Objects returns the collected objects.
+func (b *Builder) Objects() []Object {
+	return b.objects
+}
+
+func (b *Builder) add(o Object) {
+	if !b.seen[o.ID()] {
+		b.seen[o.ID()] = true
+		b.objects = append(b.objects, o)
+	}
+}
+
+// Add converts a lookup. Results without an assessment (failed or pending providers) are
+// skipped; sightings are only produced for malicious and suspicious verdicts of indicators.
+// Objects are modified when the lookup was last updated, so a verdict changed by a provider that
+// finished later is exported as a new version.
+func (b *Builder) Add(l *vo.LookupResponseVO) error {
+	created := Timestamp(l.CreatedAt)
+	modified := created
+	if l.UpdatedAt.After(l.CreatedAt) {
+		modified = Timestamp(l.UpdatedAt)
+	}
+	producer := identity("hermes", "Hermes", "system")
+	b.add(producer)
+
+	var subject Object
+	refs := []string{}
+	if l.IndicatorType == indicator.TypeCVE {
+		subject = vulnerability(l, created, modified, producer.ID())
+	} else {
+		sco, comparison, err := observable(l)
+		if err != nil {
+			return err
+		}
+		b.add(sco)
+		refs = append(refs, sco.ID())
+		subject = indicatorSDO(l, comparison, created, modified, producer.ID())
+	}
+	b.add(subject)
+	refs = append([]string{subject.ID()}, refs...)
+
+	for _, code := range sortedCodes(l.Results) {
+		r := l.Results[code]
+		if !r.Success || r.Assessment == nil {
+			continue
+		}
+		name := code
+		if b.names != nil {
+			name = b.names(code)
+		}
+		provider := identity(code, name, "organization")
+		b.add(provider)
+		a := r.Assessment
+
+		note := Object{
+			"type":             "note",
+			"spec_version":     specVersion,
+			"id":               sdoID("note", l.RequestID+":"+code),
+			"created":          created,
+			"modified":         modified,
+			"created_by_ref":   provider.ID(),
+			"abstract":         fmt.Sprintf("%s: %s (score %d)", name, a.Verdict, a.Score),
+			"content":          noteContent(name, a),
+			"authors":          []string{name},
+			"object_refs":      refs,
+			"x_hermes_verdict": a.Verdict,
+			"x_hermes_score":   a.Score,
+		}
+		if len(a.Tags) > 0 {
+			note["labels"] = a.Tags
+		}
+		b.add(note)
+
+		if subject.Type() != "indicator" || (a.Verdict != "malicious" && a.Verdict != "suspicious") {
+			continue
+		}
+		sighting := Object{
+			"type":               "sighting",
+			"spec_version":       specVersion,
+			"id":                 sdoID("sighting", l.RequestID+":"+code),
+			"created":            created,
+			"modified":           modified,
+			"created_by_ref":     producer.ID(),
+			"sighting_of_ref":    subject.ID(),
+			"where_sighted_refs": []string{provider.ID()},
+			"count":              1,
+			"x_hermes_verdict":   a.Verdict,
+			"x_hermes_score":     a.Score,
+		}
+		if a.FirstSeen != nil {
+			sighting["first_seen"] = Timestamp(*a.FirstSeen)
+		}
+		if a.LastSeen != nil {
+			sighting["last_seen"] = Timestamp(*a.LastSeen)
+		}
+		b.add(sighting)
+	}
+	return nil
+}
+
+// observable returns the lookup's SCO and the pattern comparison matching it.
+func observable(l *vo.LookupResponseVO) (Object, string, error) {
+	var typ, prop string
+	props := map[string]interface{}{}
+	switch l.IndicatorType {
+	case indicator.TypeIP:
+		typ = "ipv4-addr"
+		if ip := net.ParseIP(l.IndicatorValue); ip != nil && ip.To4() == nil {
+			typ = "ipv6-addr"
+		}
+		prop, props["value"] = "value", l.IndicatorValue
+	case indicator.TypeDomain:
+		typ, prop, props["value"] = "domain-name", "value", l.IndicatorValue
+	case indicator.TypeURL:
+		typ, prop, props["value"] = "url", "value", l.IndicatorValue
+	case indicator.TypeEmail:
+		typ, prop, props["value"] = "email-addr", "value", l.IndicatorValue
+	case indicator.TypeHash:
+		key, ok := hashKeys[l.HashType]
+		if !ok {
+			return nil, "", fmt.Errorf("unsupported hash type: %q", l.HashType)
+		}
+		typ, prop = "file", "hashes."+quote(key)
+		props["hashes"] = map[string]string{key: l.IndicatorValue}
+	default:
+		return nil, "", fmt.Errorf("unsupported type: %s", l.IndicatorType)
+	}
+	o := Object{"type": typ, "spec_version": specVersion, "id": scoID(typ, props)}
+	for k, v := range props {
+		o[k] = v
+	}
+	return o, fmt.Sprintf("%s:%s = %s", typ, prop, quote(l.IndicatorValue)), nil
+}
+
+// indicatorSDO is the indicator matching the observable. Its ID follows the lookup rather than
+// the pattern: created must not change between versions of an object, and each lookup is made
+// at its own time.
+func indicatorSDO(l *vo.LookupResponseVO, comparison, created, modified, producer string) Object {
+	pattern := "[" + comparison + "]"
+	o := Object{
+		"type":            "indicator",
+		"spec_version":    specVersion,
+		"id":              sdoID("indicator", l.RequestID),
+		"created":         created,
+		"modified":        modified,
+		"created_by_ref":  producer,
+		"name":            l.IndicatorValue,
+		"pattern":         pattern,
+		"pattern_type":    "stix",
+		"pattern_version": specVersion,
+		"valid_from":      created,
+		"indicator_types": []string{"unknown"},
+	}
+	if v := l.Verdict; v != nil {
+		o["indicator_types"] = []string{indicatorType(v.Verdict)}
+		o["description"] = v.Explanation
+		o["x_hermes_verdict"] = v.Verdict
+		o["x_hermes_score"] = v.Score
+	}
+	return o
+}
+
+// vulnerability describes a CVE lookup, with the merged record when the lookup has one.
+func vulnerability(l *vo.LookupResponseVO, created, modified, producer string) Object {
+	refs := []map[string]string{{"source_name": "cve", "external_id": l.IndicatorValue}}
+	o := Object{
+		"type":           "vulnerability",
+		"spec_version":   specVersion,
+		"id":             sdoID("vulnerability", l.RequestID),
+		"created":        created,
+		"modified":       modified,
+		"created_by_ref": producer,
+		"name":           l.IndicatorValue,
+	}
+	if c := l.CVE; c != nil {
+		if c.Description != "" {
+			o["description"] = c.Description
+		}
+		for _, u := range c.References {
+			refs = append(refs, map[string]string{"source_name": "reference", "url": u})
+		}
+		if len(c.CVSS) > 0 {
+			o["x_hermes_cvss"] = c.CVSS
+		}
+	}
+	o["external_references"] = refs
+	return o
+}
+
+func identity(code, name, class string) Object {
+	at := Timestamp(identityEpoch)
+	return Object{
+		"type":           "identity",
+		"spec_version":   specVersion,
+		"id":             sdoID("identity", code),
+		"created":        at,
+		"modified":       at,
+		"name":           name,
+		"identity_class": class,
+	}
+}
+
+func noteContent(name string, a *vo.AssessmentVO) string {
+	s := fmt.Sprintf("%s rates this %s with score %d.", name, a.Verdict, a.Score)
+	if len(a.Tags) > 0 {
+		s += " Tags: " + strings.Join(a.Tags, ", ") + "."
+	}
+	return s
+}
+
+// indicatorType maps a verdict to the indicator-type vocabulary.
+func indicatorType(verdict string) string {
+	switch verdict {
+	case "malicious":
+		return "malicious-activity"
+	case "suspicious":
+		return "anomalous-activity"
+	case "clean":
+		return "benign"
+	default:
+		return "unknown"
+	}
+}
+
+// scoID is the deterministic SCO identifier: a UUIDv5 of the canonical JSON of the type's ID
+// contributing properties.
+func scoID(typ string, props map[string]interface{}) string {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(props)
+	return typ + "--" + uuid.NewSHA1(scoNamespace, bytes.TrimSuffix(buf.Bytes(), []byte("\n"))).String()
+}
+
+func sdoID(typ, key string) string {
+	return typ + "--" + uuid.NewSHA1(sdoNamespace, []byte(typ+":"+key)).String()
+}
+
+// quote is a STIX pattern string literal.
+func quote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
+
+// sources maps the object types a lookup is exported to, other than identities, notes and
+// sightings, to the indicator types producing them.
+var sources = map[string][]string{
+	"indicator":     {indicator.TypeIP, indicator.TypeDomain, indicator.TypeURL, indicator.TypeEmail, indicator.TypeHash},
+	"vulnerability": {indicator.TypeCVE},
+	"ipv4-addr":     {indicator.TypeIP},
+	"ipv6-addr":     {indicator.TypeIP},
+	"domain-name":   {indicator.TypeDomain},
+	"url":           {indicator.TypeURL},
+	"email-addr":    {indicator.TypeEmail},
+	"file":          {indicator.TypeHash},
+}
+
+// IndicatorTypes returns the indicator types of the lookups whose exports can contain objects of
+// objectTypes. all is set when lookups of any type can (identities, notes and sightings); types
+// is empty when none can.
+func IndicatorTypes(objectTypes ...string) (types []string, all bool) {
+	seen := map[string]bool{}
+	for _, t := range objectTypes {
+		switch t {
+		case "identity", "note", "sighting":
+			return nil, true
+		}
+		for _, it := range sources[t] {
+			if !seen[it] {
+				seen[it] = true
+				types = append(types, it)
+			}
+		}
+	}
+	return types, false
+}
+
+// Timestamp formats t as a STIX timestamp (UTC, millisecond precision).
+func Timestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func sortedCodes(results map[string]vo.ProviderResultVO) []string {
+	codes := make([]string, 0, len(results))
+	for code := range results {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
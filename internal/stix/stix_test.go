@@ -0,0 +1,127 @@
+package stix
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"hermes/internal/vo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func hashLookup() *vo.LookupResponseVO {
+	seen := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	return &vo.LookupResponseVO{
+		RequestID:      "550e8400-e29b-41d4-a716-446655440000",
+		IndicatorType:  "hash",
+		IndicatorValue: "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f",
+		HashType:       "sha256",
+		CreatedAt:      time.Date(2025, 2, 20, 9, 0, 0, 0, time.UTC),
+		UpdatedAt:      time.Date(2025, 2, 20, 9, 5, 0, 0, time.UTC),
+		Verdict:        &vo.AggregateVerdictVO{Verdict: "malicious", Score: 90},
+		Results: map[string]vo.ProviderResultVO{
+			"virustotal":    {ProviderCode: "virustotal", Success: true, Status: vo.StatusOK, Assessment: &vo.AssessmentVO{Verdict: "malicious", Score: 90, Tags: []string{"eicar"}, LastSeen: &seen}},
+			"malwarebazaar": {ProviderCode: "malwarebazaar", Success: true, Status: vo.StatusOK, Assessment: &vo.AssessmentVO{Verdict: "clean"}},
+			"hybrid":        {ProviderCode: "hybrid", Status: vo.StatusError, Error: "HTTP 500"},
+		},
+	}
+}
+
+func byType(objects []Object) map[string][]Object {
+	out := map[string][]Object{}
+	for _, o := range objects {
+		out[o.Type()] = append(out[o.Type()], o)
+	}
+	return out
+}
+
+func TestBuilder_Hash(t *testing.T) {
+	b := NewBuilder(func(code string) string { return "Name of " + code })
+	assert.NoError(t, b.Add(hashLookup()))
+	bundle := b.Bundle("lookup:550e8400-e29b-41d4-a716-446655440000")
+	objs := byType(bundle.Objects)
+
+	assert.Len(t, objs["file"], 1)
+	assert.Equal(t, map[string]string{"SHA-256": "275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f"}, objs["file"][0]["hashes"])
+	if assert.Len(t, objs["indicator"], 1) {
+		ind := objs["indicator"][0]
+		assert.Equal(t, "[file:hashes.'SHA-256' = '275a021bbfb6489e54d471899f7db9d1663fc695ec2fe2a2c4538aabf651fd0f']", ind["pattern"])
+		assert.Equal(t, []string{"malicious-activity"}, ind["indicator_types"])
+		assert.Equal(t, "2025-02-20T09:00:00.000Z", ind["valid_from"])
+		assert.Equal(t, "2025-02-20T09:00:00.000Z", ind["created"])
+		// A verdict refreshed after the lookup is a new version of the indicator.
+		assert.Equal(t, "2025-02-20T09:05:00.000Z", ind["modified"])
+	}
+	// Hermes plus the two providers with a verdict.
+	assert.Len(t, objs["identity"], 3)
+	assert.Len(t, objs["note"], 2)
+	if assert.Len(t, objs["sighting"], 1) {
+		s := objs["sighting"][0]
+		assert.Equal(t, objs["indicator"][0].ID(), s["sighting_of_ref"])
+		assert.Equal(t, "2024-05-01T00:00:00.000Z", s["last_seen"])
+		assert.Equal(t, "malicious", s["x_hermes_verdict"])
+	}
+}
+
+func TestBuilder_Deterministic(t *testing.T) {
+	export := func() []byte {
+		b := NewBuilder(nil)
+		assert.NoError(t, b.Add(hashLookup()))
+		out, err := json.Marshal(b.Bundle("lookup:x"))
+		assert.NoError(t, err)
+		return out
+	}
+	assert.JSONEq(t, string(export()), string(export()))
+
+	// The observable is shared by lookups of the same value; indicators are per lookup.
+	other := hashLookup()
+	other.RequestID = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	b := NewBuilder(nil)
+	assert.NoError(t, b.Add(hashLookup()))
+	assert.NoError(t, b.Add(other))
+	objs := byType(b.Objects())
+	assert.Len(t, objs["file"], 1)
+	assert.Len(t, objs["indicator"], 2)
+	assert.Len(t, objs["identity"], 3)
+}
+
+func TestBuilder_Types(t *testing.T) {
+	cases := map[string]string{"ip:8.8.8.8": "ipv4-addr", "ip:2001:db8::1": "ipv6-addr", "domain:example.com": "domain-name",
+		"url:https://example.com/a?b=1&c='2'": "url", "email:test@example.com": "email-addr"}
+	for in, typ := range cases {
+		kind, value, _ := strings.Cut(in, ":")
+		b := NewBuilder(nil)
+		assert.NoError(t, b.Add(&vo.LookupResponseVO{RequestID: "r", IndicatorType: kind, IndicatorValue: value}))
+		objs := byType(b.Objects())
+		if assert.Len(t, objs[typ], 1, in) {
+			assert.Equal(t, value, objs[typ][0]["value"])
+		}
+	}
+
+	b := NewBuilder(nil)
+	assert.NoError(t, b.Add(&vo.LookupResponseVO{RequestID: "r", IndicatorType: "url", IndicatorValue: `https://example.com/it's`}))
+	assert.Equal(t, `[url:value = 'https://example.com/it\'s']`, byType(b.Objects())["indicator"][0]["pattern"])
+
+	b = NewBuilder(nil)
+	assert.NoError(t, b.Add(&vo.LookupResponseVO{RequestID: "r", IndicatorType: "cve", IndicatorValue: "CVE-2021-44228",
+		CVE: &vo.CVEVO{ID: "CVE-2021-44228", Description: "Log4Shell", References: []string{"https://logging.apache.org/"}}}))
+	objs := byType(b.Objects())
+	assert.Empty(t, objs["indicator"])
+	if assert.Len(t, objs["vulnerability"], 1) {
+		assert.Equal(t, "Log4Shell", objs["vulnerability"][0]["description"])
+		assert.Len(t, objs["vulnerability"][0]["external_references"], 2)
+	}
+}
+
+func TestIndicatorTypes(t *testing.T) {
+	types, all := IndicatorTypes("domain-name", "url", "domain-name")
+	assert.False(t, all)
+	assert.Equal(t, []string{"domain", "url"}, types)
+	_, all = IndicatorTypes("file", "note")
+	assert.True(t, all)
+	types, all = IndicatorTypes("malware")
+	assert.False(t, all)
+	assert.Empty(t, types)
+}
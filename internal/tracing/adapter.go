@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+
+	"hermes/internal/metrics"
+	"hermes/internal/providerapi"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Adapter wraps a providerapi.Adapter so each call runs in a span named after the call
+// (provider.lookup, provider.submit, provider.poll, provider.collect). The vendor's HTTP requests
+// made through providerapi.NewHTTPClient become its children and set its HTTP status.
+type Adapter struct {
+	providerapi.Adapter
+}
+
+// Wrap returns a, traced.
+func Wrap(a providerapi.Adapter) *Adapter {
+	return &Adapter{Adapter: a}
+}
+
+// Lookup implements providerapi.Adapter.
+func (a *Adapter) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	ctx, span := a.start(ctx, "provider.lookup", IndicatorTypeKey.String(indicatorType))
+	defer span.End()
+	res, err := a.Adapter.Lookup(ctx, indicatorType, value)
+	end(span, res, err)
+	return res, err
+}
+
+// Submit implements providerapi.AsyncAdapter for adapters underneath that do.
+func (a *Adapter) Submit(ctx context.Context, indicatorType string, value string) (*providerapi.Job, providerapi.Result, error) {
+	ctx, span := a.start(ctx, "provider.submit", IndicatorTypeKey.String(indicatorType))
+	defer span.End()
+	job, res, err := providerapi.Submit(ctx, a.Adapter, indicatorType, value)
+	span.SetAttributes(attribute.Bool("hermes.job.started", job != nil))
+	end(span, res, err)
+	return job, res, err
+}
+
+// Poll implements providerapi.AsyncAdapter.
+func (a *Adapter) Poll(ctx context.Context, job providerapi.Job) (providerapi.JobStatus, error) {
+	ctx, span := a.start(ctx, "provider.poll")
+	defer span.End()
+	st, err := providerapi.Poll(ctx, a.Adapter, job)
+	span.SetAttributes(attribute.Bool("hermes.job.done", st.Done))
+	end(span, providerapi.Result{Success: st.Done, Pending: !st.Done && st.Failed == "", Error: st.Failed}, err)
+	return st, err
+}
+
+// Collect implements providerapi.AsyncAdapter.
+func (a *Adapter) Collect(ctx context.Context, job providerapi.Job) (providerapi.Result, error) {
+	ctx, span := a.start(ctx, "provider.collect")
+	defer span.End()
+	res, err := providerapi.Collect(ctx, a.Adapter, job)
+	end(span, res, err)
+	return res, err
+}
+
+// Unwrap returns the wrapped adapter.
+func (a *Adapter) Unwrap() providerapi.Adapter { return a.Adapter }
+
+func (a *Adapter) start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, ProviderKey.String(a.Code()))
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// end marks a failed call by its error class only: error messages may quote the request URL,
+// which can contain the indicator.
+func end(span trace.Span, res providerapi.Result, err error) {
+	if class := metrics.ErrorClass(res, err); class != "" {
+		span.SetAttributes(ErrorClassKey.String(class))
+		span.SetStatus(codes.Error, class)
+	}
+}
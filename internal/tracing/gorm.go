@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const gormSpanKey = "tracing:span"
+
+// Plugin is a GORM plugin running each query in a span (gorm.create, gorm.query, ...) with its
+// table, SQL text (placeholders, not values) and affected rows; failed queries record their
+// SQLSTATE, not the error text. Queries join the trace of the context given with db.WithContext.
+type Plugin struct{}
+
+// Name implements gorm.Plugin.
+func (Plugin) Name() string { return "tracing" }
+
+// Initialize implements gorm.Plugin.
+func (Plugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, reg := range []struct {
+		op     string
+		before func(string, func(*gorm.DB)) error
+		after  func(string, func(*gorm.DB)) error
+	}{
+		{"create", cb.Create().Before("gorm:create").Register, cb.Create().After("gorm:create").Register},
+		{"query", cb.Query().Before("gorm:query").Register, cb.Query().After("gorm:query").Register},
+		{"update", cb.Update().Before("gorm:update").Register, cb.Update().After("gorm:update").Register},
+		{"delete", cb.Delete().Before("gorm:delete").Register, cb.Delete().After("gorm:delete").Register},
+		{"row", cb.Row().Before("gorm:row").Register, cb.Row().After("gorm:row").Register},
+		{"raw", cb.Raw().Before("gorm:raw").Register, cb.Raw().After("gorm:raw").Register},
+	} {
+		if err := reg.before("tracing:before_"+reg.op, startQuery("gorm."+reg.op)); err != nil {
+			return err
+		}
+		if err := reg.after("tracing:after_"+reg.op, endQuery); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startQuery(name string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx, span := Tracer().Start(db.Statement.Context, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNameKey.String(db.Dialector.Name())))
+		db.Statement.Context = ctx
+		db.InstanceSet(gormSpanKey, span)
+	}
+}
+
+func endQuery(db *gorm.DB) {
+	v, ok := db.InstanceGet(gormSpanKey)
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	defer span.End()
+	span.SetAttributes(
+		semconv.DBCollectionName(db.Statement.Table),
+		semconv.DBQueryText(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		var pg sqlStater
+		if errors.As(err, &pg) {
+			span.SetAttributes(semconv.DBResponseStatusCode(pg.SQLState()))
+		}
+		span.RecordError(queryError(err))
+		span.SetStatus(codes.Error, "query failed")
+	}
+}
+
+// sqlStater is implemented by Postgres errors (pgconn.PgError).
+type sqlStater interface {
+	SQLState() string
+}
+
+// queryError is what a span records of a failed query. Database errors may quote the values
+// involved, such as a duplicate indicator or client name, so only the SQLSTATE of Postgres
+// errors and the type of other errors are kept; context errors are kept as they are.
+func queryError(err error) error {
+	var pg sqlStater
+	switch {
+	case errors.As(err, &pg):
+		return fmt.Errorf("SQLSTATE %s", pg.SQLState())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return err
+	}
+	return fmt.Errorf("query failed: %T", err)
+}
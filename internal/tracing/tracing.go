@@ -0,0 +1,77 @@
+// Package tracing sets up OpenTelemetry tracing: the tracer provider and OTLP export (Setup),
+// spans around provider adapters (Wrap) and GORM queries (Plugin). Spans never carry indicator
+// values; adapters are described by provider code and indicator type only.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"hermes/internal/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span attributes specific to hermes.
+const (
+	ProviderKey      = attribute.Key("hermes.provider.code")
+	IndicatorTypeKey = attribute.Key("hermes.indicator.type")
+	RequestIDKey     = attribute.Key("hermes.request_id")
+	ErrorClassKey    = attribute.Key("hermes.error.class")
+)
+
+// Tracer returns the tracer for hermes' own spans.
+func Tracer() trace.Tracer {
+	return otel.Tracer("hermes")
+}
+
+// Setup installs the global tracer provider and W3C trace-context propagation. With
+// TRACING_EXPORTER=otlp spans are batched to the OTLP/HTTP endpoint configured by the standard
+// OTEL_EXPORTER_OTLP_* variables; otherwise the no-op provider stays and only trace context
+// received from callers is passed on. The returned function flushes pending spans on shutdown.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	switch cfg.TracingExporter {
+	case "", "none":
+		return func(context.Context) error { return nil }, nil
+	case "otlp":
+	default:
+		return nil, fmt.Errorf("TRACING_EXPORTER: unknown exporter %q", cfg.TracingExporter)
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("otlp exporter: %w", err)
+	}
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("hermes")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("tracing resource: %w", err)
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.TracingSampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return tp.Shutdown, nil
+}
+
+// TraceID returns the trace ID of the span in ctx, or "" when there is none.
+func TraceID(ctx context.Context) string {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.HasTraceID() {
+		return ""
+	}
+	return sc.TraceID().String()
+}
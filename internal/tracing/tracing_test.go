@@ -0,0 +1,87 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"hermes/internal/providerapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// httpAdapter looks indicators up in the URL path, like most vendors.
+type httpAdapter struct {
+	url    string
+	client *http.Client
+}
+
+func (a httpAdapter) Code() string             { return "stub" }
+func (a httpAdapter) SupportedTypes() []string { return []string{"ip"} }
+func (a httpAdapter) Assess(map[string]interface{}) providerapi.Assessment {
+	return providerapi.Assessment{Verdict: providerapi.VerdictUnknown}
+}
+func (a httpAdapter) Lookup(ctx context.Context, indicatorType string, value string) (providerapi.Result, error) {
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, a.url+"/ip/"+value+"?q="+value, nil)
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return providerapi.Result{ProviderCode: a.Code(), Error: err.Error()}, err
+	}
+	defer resp.Body.Close()
+	return providerapi.Result{ProviderCode: a.Code(), Error: fmt.Sprintf("HTTP %d", resp.StatusCode)}, nil
+}
+
+func TestWrap_SpansWithoutIndicatorValue(t *testing.T) {
+	rec := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec)))
+	defer otel.SetTracerProvider(prev)
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	a := Wrap(httpAdapter{url: srv.URL, client: providerapi.NewHTTPClient()})
+
+	_, err := a.Lookup(context.Background(), "ip", "203.0.113.7")
+	require.NoError(t, err)
+
+	spans := rec.Ended()
+	require.Len(t, spans, 2)
+	httpSpan, lookup := spans[0], spans[1]
+	assert.Equal(t, "HTTP GET", httpSpan.Name())
+	assert.Equal(t, "provider.lookup", lookup.Name())
+	assert.Equal(t, lookup.SpanContext().SpanID(), httpSpan.Parent().SpanID())
+
+	attrs := map[string]string{}
+	for _, s := range spans {
+		for _, kv := range s.Attributes() {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+			assert.False(t, strings.Contains(kv.Value.Emit(), "203.0.113.7"), "%s leaks the indicator", kv.Key)
+		}
+	}
+	assert.Equal(t, "stub", attrs["hermes.provider.code"])
+	assert.Equal(t, "ip", attrs["hermes.indicator.type"])
+	assert.Equal(t, "404", attrs["http.response.status_code"])
+	assert.Equal(t, "http_404", attrs["hermes.error.class"])
+}
+
+// pgError mimics pgconn.PgError, whose detail quotes the values involved.
+type pgError struct{ code, detail string }
+
+func (e *pgError) Error() string {
+	return "duplicate key value violates unique constraint: " + e.detail
+}
+func (e *pgError) SQLState() string { return e.code }
+
+func TestQueryError_KeepsValuesOut(t *testing.T) {
+	err := fmt.Errorf("create client: %w", &pgError{code: "23505", detail: "Key (name)=(acme-soc) already exists."})
+	assert.EqualError(t, queryError(err), "SQLSTATE 23505")
+	assert.NotContains(t, queryError(fmt.Errorf("bad value %q", "203.0.113.7")).Error(), "203.0.113.7")
+	assert.ErrorIs(t, queryError(fmt.Errorf("query: %w", context.Canceled)), context.Canceled)
+}
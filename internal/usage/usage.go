@@ -0,0 +1,81 @@
+// Package usage counts the provider calls made on behalf of a request and describes a client's
+// rate and quota windows.
+package usage
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Meter counts provider calls; it is carried in the request context.
+type Meter struct {
+	calls atomic.Int64
+}
+
+// ProviderCalls returns the calls counted so far.
+func (m *Meter) ProviderCalls() int64 { return m.calls.Load() }
+
+type meterKey struct{}
+
+// WithMeter returns a context carrying m.
+func WithMeter(ctx context.Context, m *Meter) context.Context {
+	return context.WithValue(ctx, meterKey{}, m)
+}
+
+// CountProviderCall records one call that reached a provider (see providerapi.Contacted); it is
+// a no-op without a meter.
+func CountProviderCall(ctx context.Context) {
+	if m, ok := ctx.Value(meterKey{}).(*Meter); ok {
+		m.calls.Add(1)
+	}
+}
+
+// Window is a limit, how much of it is used and when it resets.
+type Window struct {
+	// Limit is 0 when unlimited.
+	Limit int64
+	Used  int64
+	Reset time.Time
+}
+
+// Remaining is what is left of the limit (never negative); meaningless when unlimited.
+func (w Window) Remaining() int64 {
+	if w.Used >= w.Limit {
+		return 0
+	}
+	return w.Limit - w.Used
+}
+
+// Exceeded reports whether a limited window is used up.
+func (w Window) Exceeded() bool { return w.Limit > 0 && w.Used >= w.Limit }
+
+// Limits that can reject a request (Status.Exceeded).
+const (
+	ExceededRate    = "rate"
+	ExceededDaily   = "daily"
+	ExceededMonthly = "monthly"
+)
+
+// Status is a client's request rate (per minute) and provider-call quotas at admission time.
+type Status struct {
+	Rate    Window
+	Daily   Window
+	Monthly Window
+	// Exceeded is "" when the request is admitted, otherwise one of the Exceeded* constants.
+	Exceeded string
+	// RetryAfter is how long to wait before retrying a rejected request.
+	RetryAfter time.Duration
+}
+
+// DayStart returns the UTC midnight starting t's day.
+func DayStart(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// MonthStart returns the UTC midnight starting t's month.
+func MonthStart(t time.Time) time.Time {
+	y, m, _ := t.UTC().Date()
+	return time.Date(y, m, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,112 @@
+package verdict
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"hermes/internal/providerapi"
+)
+
+// Vote is one provider's assessment entering the aggregate.
+type Vote struct {
+	ProviderCode string
+	Assessment   providerapi.Assessment
+}
+
+// Contributor is a provider that returned a verdict, with the weight it carried.
+// Drove is set for the providers that determined the aggregated verdict.
+type Contributor struct {
+	ProviderCode string
+	Verdict      providerapi.Verdict
+	Score        int
+	Weight       float64
+	Drove        bool
+}
+
+// Summary is the aggregated verdict across providers.
+type Summary struct {
+	Verdict      providerapi.Verdict
+	Score        int
+	Explanation  string
+	Contributors []Contributor
+}
+
+// Aggregate combines provider assessments. The score is the weight-averaged score of providers with
+// a verdict (unknown verdicts are ignored; providers missing from weights weigh 1). The verdict is
+// the score's band, except that a single malicious vote lifts a clean average to suspicious.
+func Aggregate(votes []Vote, weights map[string]float64) Summary {
+	var contributors []Contributor
+	var sum, total float64
+	anyMalicious := false
+	for _, v := range votes {
+		if v.Assessment.Verdict == providerapi.VerdictUnknown || v.Assessment.Verdict == "" {
+			continue
+		}
+		w, ok := weights[v.ProviderCode]
+		if !ok {
+			w = 1
+		}
+		if w <= 0 {
+			continue
+		}
+		contributors = append(contributors, Contributor{
+			ProviderCode: v.ProviderCode,
+			Verdict:      v.Assessment.Verdict,
+			Score:        v.Assessment.Score,
+			Weight:       w,
+		})
+		sum += w * float64(v.Assessment.Score)
+		total += w
+		anyMalicious = anyMalicious || v.Assessment.Verdict == providerapi.VerdictMalicious
+	}
+	if len(contributors) == 0 {
+		return Summary{
+			Verdict:     providerapi.VerdictUnknown,
+			Explanation: "no provider returned a reputation verdict",
+		}
+	}
+
+	score := int(math.Round(sum / total))
+	v := providerapi.VerdictForScore(score)
+	if v == providerapi.VerdictClean && anyMalicious {
+		v = providerapi.VerdictSuspicious
+	}
+	out := Summary{Verdict: v, Score: providerapi.Assess(v, score).Score}
+
+	// Drivers: the providers voting in the aggregate's direction, strongest first.
+	for i := range contributors {
+		c := &contributors[i]
+		if v == providerapi.VerdictClean {
+			c.Drove = c.Verdict == providerapi.VerdictClean
+		} else {
+			c.Drove = c.Verdict == providerapi.VerdictMalicious || c.Verdict == providerapi.VerdictSuspicious
+		}
+	}
+	sort.SliceStable(contributors, func(i, j int) bool {
+		if contributors[i].Drove != contributors[j].Drove {
+			return contributors[i].Drove
+		}
+		wi := contributors[i].Weight * float64(contributors[i].Score)
+		wj := contributors[j].Weight * float64(contributors[j].Score)
+		if v == providerapi.VerdictClean {
+			wi, wj = contributors[i].Weight, contributors[j].Weight
+		}
+		if wi != wj {
+			return wi > wj
+		}
+		return contributors[i].ProviderCode < contributors[j].ProviderCode
+	})
+	out.Contributors = contributors
+
+	var drivers []string
+	for _, c := range contributors {
+		if c.Drove {
+			drivers = append(drivers, fmt.Sprintf("%s (%s %d, weight %g)", c.ProviderCode, c.Verdict, c.Score, c.Weight))
+		}
+	}
+	out.Explanation = fmt.Sprintf("%s (score %d) from %d provider(s) with a verdict; driven by %s",
+		v, out.Score, len(contributors), strings.Join(drivers, ", "))
+	return out
+}
@@ -0,0 +1,51 @@
+package verdict
+
+import (
+	"testing"
+
+	"hermes/internal/providerapi"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func vote(code string, v providerapi.Verdict, score int) Vote {
+	return Vote{ProviderCode: code, Assessment: providerapi.Assess(v, score)}
+}
+
+func TestAggregate_NoVerdicts(t *testing.T) {
+	s := Aggregate([]Vote{{ProviderCode: "threatminer", Assessment: providerapi.Unknown()}}, nil)
+	assert.Equal(t, providerapi.VerdictUnknown, s.Verdict)
+	assert.Empty(t, s.Contributors)
+}
+
+func TestAggregate_WeightedMalicious(t *testing.T) {
+	s := Aggregate([]Vote{
+		vote("virustotal", providerapi.VerdictMalicious, 90),
+		vote("abuseipdb", providerapi.VerdictMalicious, 100),
+		vote("pulsedive", providerapi.VerdictClean, 0),
+		{ProviderCode: "threatminer", Assessment: providerapi.Unknown()},
+	}, map[string]float64{"virustotal": 2, "pulsedive": 0.5})
+
+	// (2*90 + 1*100 + 0.5*0) / 3.5 = 80
+	assert.Equal(t, providerapi.VerdictMalicious, s.Verdict)
+	assert.Equal(t, 80, s.Score)
+	assert.Len(t, s.Contributors, 3)
+	assert.Equal(t, "virustotal", s.Contributors[0].ProviderCode)
+	assert.True(t, s.Contributors[0].Drove)
+	assert.True(t, s.Contributors[1].Drove)
+	assert.False(t, s.Contributors[2].Drove)
+	assert.Contains(t, s.Explanation, "virustotal")
+}
+
+func TestAggregate_SingleMaliciousVoteLiftsClean(t *testing.T) {
+	s := Aggregate([]Vote{
+		vote("malwarebazaar", providerapi.VerdictMalicious, 100),
+		vote("a", providerapi.VerdictClean, 0),
+		vote("b", providerapi.VerdictClean, 0),
+		vote("c", providerapi.VerdictClean, 0),
+		vote("d", providerapi.VerdictClean, 0),
+	}, nil)
+	assert.Equal(t, providerapi.VerdictSuspicious, s.Verdict)
+	assert.Equal(t, 25, s.Score)
+	assert.Equal(t, "malwarebazaar", s.Contributors[0].ProviderCode)
+}
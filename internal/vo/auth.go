@@ -0,0 +1,41 @@
+package vo
+
+import "time"
+
+// ClientVO is an API client and its keys.
+// @description API client
+type ClientVO struct {
+	ID        int64          `json:"id" example:"1"`
+	Name      string         `json:"name" example:"soc-automation"`
+	CreatedAt time.Time      `json:"created_at"`
+	Limits    ClientLimitsVO `json:"limits"`
+	Keys      []APIKeyVO     `json:"keys"`
+}
+
+// ClientLimitsVO are a client's effective limits (0 = unlimited); quotas count provider calls.
+// @description Effective client limits
+type ClientLimitsVO struct {
+	RateLimitPerMin int `json:"rate_limit_per_min" example:"120"`
+	DailyQuota      int `json:"daily_quota" example:"20000"`
+	MonthlyQuota    int `json:"monthly_quota" example:"400000"`
+}
+
+// APIKeyVO describes an API key without its secret.
+// @description API key (without secret)
+type APIKeyVO struct {
+	KeyID      string     `json:"key_id" example:"3f9a1c2e"`
+	ClientID   int64      `json:"client_id" example:"1"`
+	Scopes     []string   `json:"scopes" example:"lookup:read,provider:abuseipdb"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// CreatedAPIKeyVO is a newly issued key; Key is shown only once.
+// @description Newly issued API key
+type CreatedAPIKeyVO struct {
+	APIKeyVO
+	Key string `json:"key" example:"hk_3f9a1c2e_5b0e..."`
+}
@@ -0,0 +1,30 @@
+package vo
+
+import "time"
+
+// BlocklistVO is a blocklist definition.
+type BlocklistVO struct {
+	Name          string   `json:"name" example:"malicious-ips"`
+	Description   string   `json:"description,omitempty"`
+	IndicatorType string   `json:"indicator_type" example:"ip"`
+	Format        string   `json:"format" example:"plain"`
+	Verdicts      []string `json:"verdicts" example:"malicious"`
+	MinScore      int      `json:"min_score" example:"70"`
+	MaxAgeHours   int      `json:"max_age_hours" example:"720"`
+	MinProviders  int      `json:"min_providers" example:"2"`
+	// Path serves the rendered list.
+	Path string `json:"path" example:"/api/v1/blocklists/malicious-ips"`
+	// ModifiedAt is when the list's entries last changed, as of the last refresh.
+	ModifiedAt *time.Time `json:"modified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// AllowlistEntryVO is an indicator kept off every blocklist.
+type AllowlistEntryVO struct {
+	ID            int64     `json:"id" example:"1"`
+	IndicatorType string    `json:"indicator_type" example:"ip"`
+	Value         string    `json:"value" example:"192.0.2.0/24"`
+	Comment       string    `json:"comment,omitempty" example:"Office egress"`
+	CreatedAt     time.Time `json:"created_at"`
+}
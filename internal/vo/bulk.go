@@ -0,0 +1,35 @@
+package vo
+
+import "time"
+
+// BulkJobVO is a bulk lookup job's progress and a page of its items.
+// @description Bulk lookup job progress and results
+type BulkJobVO struct {
+	JobID  string `json:"job_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Status string `json:"status" example:"running" enums:"queued,running,completed,failed"`
+	// Error says why a failed job stopped; its remaining items stay pending.
+	Error string `json:"error,omitempty"`
+	// Total is the number of unique indicators; Duplicates counts repeated input lines dropped.
+	Total      int             `json:"total" example:"500"`
+	Completed  int             `json:"completed" example:"120"`
+	Failed     int             `json:"failed" example:"3"`
+	Duplicates int             `json:"duplicates" example:"12"`
+	CreatedAt  time.Time       `json:"created_at"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+	Page       int             `json:"page" example:"1"`
+	PageSize   int             `json:"page_size" example:"100"`
+	Items      []BulkJobItemVO `json:"items"`
+}
+
+// BulkJobItemVO is one unique indicator of a bulk job. RequestID identifies the lookup holding
+// the per-provider results.
+type BulkJobItemVO struct {
+	Position       int    `json:"position" example:"0"`
+	IndicatorType  string `json:"indicator_type" example:"ip"`
+	IndicatorValue string `json:"indicator_value" example:"8.8.8.8"`
+	Status         string `json:"status" example:"done" enums:"pending,done,invalid,error"`
+	RequestID      string `json:"request_id,omitempty" example:"550e8400-e29b-41d4-a716-446655440000"`
+	Verdict        string `json:"verdict,omitempty" example:"clean"`
+	Score          int    `json:"score,omitempty" example:"0"`
+	Error          string `json:"error,omitempty"`
+}
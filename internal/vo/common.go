@@ -5,4 +5,22 @@ package vo
 type ErrorVO struct {
 	Code    string `json:"code" example:"BAD_REQUEST"`
 	Message string `json:"message" example:"invalid request"`
+	// Details lists per-field validation problems, when there are any.
+	Details []FieldErrorVO `json:"details,omitempty"`
+}
+
+// FieldErrorVO is one validation problem of a request field.
+type FieldErrorVO struct {
+	Field   string `json:"field" example:"indicator_value"`
+	Reason  string `json:"reason" example:"private_ip"`
+	Message string `json:"message" example:"private or reserved address"`
+}
+
+// ReadinessVO is the response of GET /health/ready.
+type ReadinessVO struct {
+	Status string `json:"status" example:"ready" enums:"ready,not_ready"`
+	// Database is "ok" or what failed (unreachable, migrations pending or dirty).
+	Database         string `json:"database" example:"ok"`
+	MigrationVersion uint   `json:"migration_version" example:"20250222090000"`
+	LatestMigration  uint   `json:"latest_migration" example:"20250222090000"`
 }
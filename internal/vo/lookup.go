@@ -1,19 +1,128 @@
 package vo
 
+import "time"
+
+// Provider result statuses reported in ProviderResultVO.Status.
+const (
+	StatusOK       = "ok"
+	StatusError    = "error"
+	StatusTimedOut = "timed_out"
+	// StatusRateLimited means the call was refused locally to stay within the provider's rate limit.
+	StatusRateLimited = "rate_limited"
+	// StatusPending means the provider is still working (e.g. a urlscan.io scan); its job is polled
+	// in the background and the result shows up in GET /lookups/{request_id} and .../events.
+	StatusPending = "pending"
+)
+
 // LookupResponseVO is the response for unified lookup.
 // @description Response for unified lookup across providers
 type LookupResponseVO struct {
-	RequestID      string                   `json:"request_id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	IndicatorType  string                   `json:"indicator_type" example:"ip"`
-	IndicatorValue string                   `json:"indicator_value,omitempty" example:""`
-	Results        map[string]ProviderResultVO `json:"results"`
+	RequestID      string `json:"request_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	IndicatorType  string `json:"indicator_type" example:"ip"`
+	IndicatorValue string `json:"indicator_value,omitempty" example:""`
+	// HashType is the detected algorithm of a hash indicator (md5, sha1, sha256, sha512, ssdeep, tlsh).
+	HashType string                      `json:"hash_type,omitempty" example:"sha256"`
+	Results  map[string]ProviderResultVO `json:"results"`
+	// Partial is true when the lookup deadline passed before every provider answered, or some
+	// results are still pending.
+	Partial bool `json:"partial,omitempty"`
+	// Verdict aggregates the providers' normalized verdicts.
+	Verdict *AggregateVerdictVO `json:"verdict,omitempty"`
+	// CVE merges the vulnerability providers' records (cve lookups only).
+	CVE       *CVEVO    `json:"cve,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the verdict was last stored, e.g. after a pending provider finished.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LookupStartVO opens a streamed lookup (event "start"), naming the providers whose results follow.
+// @description Start of a streamed unified lookup
+type LookupStartVO struct {
+	RequestID      string   `json:"request_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	IndicatorType  string   `json:"indicator_type" example:"ip"`
+	IndicatorValue string   `json:"indicator_value" example:"8.8.8.8"`
+	HashType       string   `json:"hash_type,omitempty" example:"sha256"`
+	Providers      []string `json:"providers"`
+}
+
+// CVEVO is a CVE merged from the vulnerability providers (NVD, CIRCL, Vulners).
+// @description CVE record merged across providers
+type CVEVO struct {
+	ID          string     `json:"id" example:"CVE-2024-3094"`
+	Description string     `json:"description,omitempty"`
+	Published   *time.Time `json:"published,omitempty"`
+	Modified    *time.Time `json:"modified,omitempty"`
+	CVSS        []CVSSVO   `json:"cvss"`
+	CWEs        []string   `json:"cwes" example:"CWE-506"`
+	References  []string   `json:"references"`
+	CPEs        []string   `json:"cpes" example:"cpe:2.3:a:tukaani:xz:5.6.0:*:*:*:*:*:*:*"`
+	Sources     []string   `json:"sources" example:"nvd,circl_cve"`
+}
+
+// CVSSVO is one CVSS score of a CVE and the provider that reported it.
+type CVSSVO struct {
+	Version   string  `json:"version" example:"3.1"`
+	Vector    string  `json:"vector" example:"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"`
+	BaseScore float64 `json:"base_score" example:"10"`
+	Severity  string  `json:"severity,omitempty" example:"CRITICAL"`
+	Source    string  `json:"source" example:"nvd"`
+}
+
+// AggregateVerdictVO is the verdict across providers and which providers drove it.
+// @description Aggregated verdict across providers
+type AggregateVerdictVO struct {
+	Verdict      string                 `json:"verdict" example:"malicious" enums:"malicious,suspicious,clean,unknown"`
+	Score        int                    `json:"score" example:"82"`
+	Explanation  string                 `json:"explanation" example:"malicious (score 82) from 3 provider(s) with a verdict; driven by virustotal (malicious 90, weight 1.5)"`
+	Contributors []VerdictContributorVO `json:"contributors"`
+}
+
+// VerdictContributorVO is one provider's share in the aggregated verdict.
+type VerdictContributorVO struct {
+	ProviderCode string  `json:"provider_code" example:"virustotal"`
+	Verdict      string  `json:"verdict" example:"malicious"`
+	Score        int     `json:"score" example:"90"`
+	Weight       float64 `json:"weight" example:"1.5"`
+	Drove        bool    `json:"drove"`
+}
+
+// AssessmentVO is a provider's normalized verdict; the raw response stays in ProviderResultVO.Data.
+// @description Normalized provider verdict
+type AssessmentVO struct {
+	Verdict   string     `json:"verdict" example:"malicious" enums:"malicious,suspicious,clean,unknown"`
+	Score     int        `json:"score" example:"90"`
+	Tags      []string   `json:"tags,omitempty"`
+	FirstSeen *time.Time `json:"first_seen,omitempty"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
 }
 
 // ProviderResultVO is a single provider's result.
 // @description Single provider lookup result
 type ProviderResultVO struct {
-	ProviderCode string      `json:"provider_code" example:"abuseipdb"`
-	Success      bool        `json:"success"`
-	Data         interface{} `json:"data,omitempty"`
-	Error        string      `json:"error,omitempty"`
+	ProviderCode    string        `json:"provider_code" example:"abuseipdb"`
+	Success         bool          `json:"success"`
+	Status          string        `json:"status" example:"ok" enums:"ok,error,timed_out,rate_limited,pending"`
+	Assessment      *AssessmentVO `json:"assessment,omitempty"`
+	Data            interface{}   `json:"data,omitempty"`
+	Error           string        `json:"error,omitempty"`
+	Cached          bool          `json:"cached"`
+	CacheAgeSeconds int64         `json:"cache_age_seconds,omitempty" example:"120"`
+}
+
+// LookupHistoryVO is a page of past lookups; pass NextCursor as cursor to get the next page.
+// @description Page of past lookups
+type LookupHistoryVO struct {
+	Items      []LookupSummaryVO `json:"items"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// LookupSummaryVO is one past lookup; GET /lookups/{request_id} returns its full results.
+type LookupSummaryVO struct {
+	RequestID      string    `json:"request_id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	IndicatorType  string    `json:"indicator_type" example:"ip"`
+	IndicatorValue string    `json:"indicator_value" example:"8.8.8.8"`
+	Verdict        string    `json:"verdict,omitempty" example:"clean"`
+	Score          int       `json:"score" example:"0"`
+	Providers      []string  `json:"providers" example:"abuseipdb,virustotal"`
+	CreatedAt      time.Time `json:"created_at"`
 }
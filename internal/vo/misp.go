@@ -0,0 +1,11 @@
+package vo
+
+// MISPPushVO is the result of pushing a lookup or bulk job to MISP.
+type MISPPushVO struct {
+	// EventID is MISP's ID of the event, EventUUID the UUID Hermes derives for it.
+	EventID   string `json:"event_id" example:"1234"`
+	EventUUID string `json:"event_uuid" example:"5d1c3b6a-2f4e-5a8b-9c0d-1e2f3a4b5c6d"`
+	// Created is false when an earlier push of the same lookup or job was updated.
+	Created    bool `json:"created"`
+	Attributes int  `json:"attributes" example:"12"`
+}
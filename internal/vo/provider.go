@@ -1,9 +1,61 @@
 package vo
 
+import "time"
+
 // ProviderLookupResponseVO is the response for a single-provider lookup (e.g. GET /providers/abuseipdb/ip/:ip).
 type ProviderLookupResponseVO struct {
-	ProviderCode string      `json:"provider_code"`
-	Success      bool        `json:"success"`
-	Data         interface{} `json:"data,omitempty"`
-	Error        string      `json:"error,omitempty"`
+	ProviderCode string        `json:"provider_code"`
+	Success      bool          `json:"success"`
+	Assessment   *AssessmentVO `json:"assessment,omitempty"`
+	Data         interface{}   `json:"data,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	// Pending is true when the provider is still working; Data identifies the pending work.
+	Pending bool `json:"pending,omitempty"`
+}
+
+// ProviderStatusVO describes a registered provider: what it accepts, whether it can be used, and
+// how its recent calls went.
+// @description Provider capabilities and status
+type ProviderStatusVO struct {
+	Code           string   `json:"code" example:"abuseipdb"`
+	Name           string   `json:"name" example:"AbuseIPDB"`
+	SupportedTypes []string `json:"supported_types" example:"ip"`
+	// HashTypes lists the accepted hash algorithms when the provider takes only some.
+	HashTypes []string `json:"hash_types,omitempty" example:"md5,sha256"`
+	// Configured is false when the provider's API key is missing; its lookups then fail.
+	Configured bool `json:"configured"`
+	Enabled    bool `json:"enabled"`
+	// Async providers answer "pending" and finish in the background.
+	Async           bool             `json:"async"`
+	RateLimitPerMin int              `json:"rate_limit_per_min" example:"60"`
+	Recent          ProviderRecentVO `json:"recent"`
+}
+
+// ProviderRecentVO summarizes a provider's latest calls (at most 100) since the server started.
+type ProviderRecentVO struct {
+	Calls int `json:"calls" example:"100"`
+	// SuccessRate is the share of calls that answered (0..1); absent without calls.
+	SuccessRate  *float64   `json:"success_rate,omitempty" example:"0.97"`
+	AvgLatencyMs int64      `json:"avg_latency_ms" example:"420"`
+	P95LatencyMs int64      `json:"p95_latency_ms" example:"1300"`
+	LastCallAt   *time.Time `json:"last_call_at,omitempty"`
+}
+
+// HealthSkipped is the health status of a provider that could only be checked by starting a
+// provider-side job (a scan or assessment), which a health check must not do.
+const HealthSkipped = "skipped"
+
+// ProviderHealthVO is the outcome of a provider's canary lookup.
+// @description Provider canary lookup result
+type ProviderHealthVO struct {
+	ProviderCode string `json:"provider_code" example:"abuseipdb"`
+	Healthy      bool   `json:"healthy"`
+	// Status is the canary result's status (ok, error, timed_out, rate_limited, pending), or
+	// skipped when every lookup the provider supports would start a provider-side job.
+	Status         string    `json:"status" example:"ok" enums:"ok,error,timed_out,rate_limited,pending,skipped"`
+	IndicatorType  string    `json:"indicator_type" example:"ip"`
+	IndicatorValue string    `json:"indicator_value" example:"8.8.8.8"`
+	LatencyMs      int64     `json:"latency_ms" example:"380"`
+	Error          string    `json:"error,omitempty"`
+	CheckedAt      time.Time `json:"checked_at"`
 }
@@ -0,0 +1,70 @@
+package vo
+
+// TAXIIDiscoveryVO is the TAXII 2.1 discovery resource (GET /taxii2/).
+type TAXIIDiscoveryVO struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Default     string   `json:"default,omitempty"`
+	APIRoots    []string `json:"api_roots"`
+}
+
+// TAXIIAPIRootVO is the TAXII 2.1 API root resource.
+type TAXIIAPIRootVO struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Versions    []string `json:"versions"`
+	// MaxContentLength is 0: collections are read-only.
+	MaxContentLength int `json:"max_content_length"`
+}
+
+// TAXIICollectionsVO lists TAXII collections.
+type TAXIICollectionsVO struct {
+	Collections []TAXIICollectionVO `json:"collections"`
+}
+
+// TAXIICollectionVO is a TAXII 2.1 collection resource.
+// @description TAXII collection
+type TAXIICollectionVO struct {
+	ID          string   `json:"id" example:"9c119955-a20b-59a3-9e0e-fdb93756a999"`
+	Title       string   `json:"title" example:"Malicious indicators, last 7 days"`
+	Description string   `json:"description,omitempty"`
+	Alias       string   `json:"alias,omitempty" example:"malicious-last-7d"`
+	CanRead     bool     `json:"can_read"`
+	CanWrite    bool     `json:"can_write"`
+	MediaTypes  []string `json:"media_types"`
+}
+
+// TAXIIEnvelopeVO is a page of STIX objects.
+type TAXIIEnvelopeVO struct {
+	More    bool                     `json:"more"`
+	Next    string                   `json:"next,omitempty"`
+	Objects []map[string]interface{} `json:"objects,omitempty"`
+}
+
+// TAXIIManifestVO is a page of manifest records.
+type TAXIIManifestVO struct {
+	More    bool                    `json:"more"`
+	Next    string                  `json:"next,omitempty"`
+	Objects []TAXIIManifestRecordVO `json:"objects,omitempty"`
+}
+
+// TAXIIManifestRecordVO describes one object of a collection.
+type TAXIIManifestRecordVO struct {
+	ID        string `json:"id"`
+	DateAdded string `json:"date_added"`
+	Version   string `json:"version"`
+	MediaType string `json:"media_type"`
+}
+
+// TAXIIVersionsVO lists the versions of an object.
+type TAXIIVersionsVO struct {
+	More     bool     `json:"more"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+// TAXIIErrorVO is the TAXII 2.1 error message.
+type TAXIIErrorVO struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	HTTPStatus  string `json:"http_status"`
+}
@@ -0,0 +1,25 @@
+package vo
+
+import "time"
+
+// UsageVO is a client's current usage and limits.
+// @description Client usage and limits
+type UsageVO struct {
+	Client string `json:"client" example:"soc-automation"`
+	// RateLimitPerMin bounds HTTP requests per minute (0 = unlimited).
+	RateLimitPerMin int           `json:"rate_limit_per_min" example:"120"`
+	Day             UsageWindowVO `json:"day"`
+	Month           UsageWindowVO `json:"month"`
+}
+
+// UsageWindowVO is usage within one UTC day or month; the quota counts provider calls.
+// @description Usage within a quota window
+type UsageWindowVO struct {
+	Requests      int64 `json:"requests" example:"42"`
+	ProviderCalls int64 `json:"provider_calls" example:"517"`
+	// Limit is the provider-call quota (0 = unlimited).
+	Limit int `json:"limit" example:"20000"`
+	// Remaining is omitted when unlimited.
+	Remaining *int64    `json:"remaining,omitempty" example:"19483"`
+	ResetsAt  time.Time `json:"resets_at"`
+}